@@ -1,5 +1,195 @@
 package main
 
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"myquote/domain"
+	importerdomain "myquote/domain/importer"
+	quotedomain "myquote/domain/quote"
+	"myquote/feature/auth"
+	"myquote/feature/book"
+	"myquote/feature/digest"
+	"myquote/feature/exporter"
+	"myquote/feature/friend"
+	"myquote/feature/importer"
+	"myquote/feature/middleware"
+	"myquote/feature/quote"
+	"myquote/feature/stats"
+	"myquote/feature/tag"
+	"myquote/feature/user"
+	"myquote/service/config"
+	"myquote/service/csvfile"
+	"myquote/service/database"
+	"myquote/service/hash"
+	"myquote/service/kindle"
+	"myquote/service/logger"
+	"myquote/service/mailer"
+	"myquote/service/markdown"
+	"myquote/service/random"
+	"myquote/service/token"
+	"myquote/service/validator"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+const SCHEDULER_INTERVAL = time.Minute
+const IMPORT_INTERVAL = time.Minute
+
 func main() {
+	path := flag.String("config", "", "path to the yaml config file")
+	flag.Parse()
+
+	cfg, err := config.Load(*path)
+	if err != nil {
+		log.Fatalf("load config error: %s", err.Error())
+	}
+
+	l := logger.NewLogger(cfg.LogPath)
+	defer l.CloseFile()
+
+	db, err := database.Open(cfg.Database.Driver, cfg.Database.DSN)
+	if err != nil {
+		l.Fatalf("open database error: %s", err.Error())
+	}
+
+	g := gin.Default()
+	g.Use(middleware.NewErrorMiddleware(l))
+
+	authRepo := auth.NewRepository(l, db)
+	err = authRepo.Migrate()
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	authUc := auth.NewUsecase(l, authRepo, validator.NewPasswordValidator(), validator.NewEmailValidator(), hash.NewBcryptValidator(), token.NewGenerator(cfg.Token.Size))
+	auth.NewAuthHTTPHandler(g, l, authUc)
+	authMiddleware := auth.NewAuthMiddleware(l, authUc)
+
+	quoteRepo := quote.NewRepository(l, db)
+	err = quoteRepo.Migrate()
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	searchRepo, err := newSearchRepository(l, db, cfg.Database.Driver)
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	quoteUc := quote.NewUsecase(l, quoteRepo, searchRepo, random.NewRandom(), cfg.Review.Window)
+	quote.NewQuoteHTTPHandler(g, l, quoteUc, authMiddleware)
+
+	bookRepo := book.NewRepository(l, db)
+	err = bookRepo.Migrate()
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	bookUc := book.NewUsecase(l, bookRepo, validator.NewISBNValidator(), validator.NewURLValidator())
+	book.NewBookHTTPHandler(g, l, bookUc, authMiddleware)
+
+	tagRepo := tag.NewRepository(l, db)
+	err = tagRepo.Migrate()
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	tagUc := tag.NewUsecase(l, tagRepo)
+	tag.NewTagHTTPHandler(g, l, tagUc, authMiddleware)
+
+	importerRepo := importer.NewRepository(l, db)
+	err = importerRepo.Migrate()
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	importerUc := importer.NewUsecase(l, importerRepo, importerdomain.Parsers{
+		Markdown: markdown.NewParser(),
+		Kindle:   kindle.NewParser(),
+		Readwise: csvfile.NewReadwiseParser(),
+		CSV:      func(m importerdomain.Mapping) importerdomain.Parser { return csvfile.NewParser(m) },
+	})
+	importer.NewImporterHTTPHandler(g, l, importerUc, authMiddleware)
+
+	exporterRepo := exporter.NewRepository(l, db)
+	exporterUc := exporter.NewUsecase(l, exporterRepo)
+	exporter.NewExporterHTTPHandler(g, l, exporterUc, authMiddleware)
+
+	userRepo := user.NewRepository(l, db)
+	userUc := user.NewUsecase(l, userRepo, validator.NewEmailValidator(), hash.NewBcryptValidator(), validator.NewRangeValidator(1, 3), validator.NewRangeValidator(3, 7), validator.NewTimeZoneValidator())
+	user.NewUserHTTPHandler(g, l, userUc, authMiddleware)
+
+	friendRepo := friend.NewRepository(l, db)
+	err = friendRepo.Migrate()
+	if err != nil {
+		l.Fatalf("migrate database error: %s", err.Error())
+	}
+	friendUc := friend.NewUsecase(l, friendRepo)
+	friend.NewFriendHTTPHandler(g, l, friendUc, authMiddleware)
+
+	statsRepo := stats.NewRepository(l, db)
+	statsUc := stats.NewUsecase(l, statsRepo)
+	stats.NewStatsHTTPHandler(g, l, statsUc, authMiddleware)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	poolDone := make(chan struct{})
+	go func() {
+		importer.NewPool(l, importerUc, cfg.Import.Workers, IMPORT_INTERVAL).Run(ctx)
+		close(poolDone)
+	}()
+	if cfg.SMTP.Host != "" {
+		digestRepo := digest.NewRepository(l, db)
+		err = digestRepo.Migrate()
+		if err != nil {
+			l.Fatalf("migrate database error: %s", err.Error())
+		}
+		m := mailer.NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From)
+		digestUc := digest.NewUsecase(l, digestRepo, quoteUc, m)
+		go digest.NewScheduler(l, digestUc, SCHEDULER_INTERVAL).Run(ctx)
+	}
+
+	srv := &http.Server{Addr: cfg.Addr, Handler: g}
+	go func() {
+		l.Infof("listen on %s", cfg.Addr)
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			l.Errorf("server error: %s", err.Error())
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	l.Info("shutting down server")
+	stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+	defer cancel()
+	err = srv.Shutdown(ctx)
+	if err != nil {
+		l.Errorf("server shutdown error: %s", err.Error())
+	}
+	// the import workers finish their batch before the database closes
+	<-poolDone
+
+	sqlDB, err := db.DB()
+	if err == nil {
+		sqlDB.Close()
+	}
+}
 
+// newSearchRepository uses the sqlite full-text index and plain LIKE queries on other databases.
+func newSearchRepository(l domain.Logger, db *gorm.DB, driver string) (quotedomain.SearchRepository, error) {
+	if driver != "sqlite" {
+		return quote.NewLikeRepository(l, db), nil
+	}
+	repo := quote.NewFTSRepository(l, db)
+	err := repo.Migrate()
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
 }
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+)
+
+// USER_KEY is the gin.Context key holding the authenticated models.User.
+const USER_KEY = "auth_user"
+
+// CurrentUser returns the authenticated user put into the context by the auth middleware.
+func CurrentUser(c *gin.Context) (models.User, bool) {
+	v, ok := c.Get(USER_KEY)
+	if !ok {
+		return models.User{}, false
+	}
+	user, ok := v.(models.User)
+	return user, ok
+}
+
+// RequireUser is CurrentUser for the handlers of routes behind the auth middleware. Without an
+// authenticated user it reports exceptions.Unauthorized and aborts the request.
+func RequireUser(c *gin.Context) (models.User, bool) {
+	user, ok := CurrentUser(c)
+	if !ok {
+		c.Error(exceptions.Unauthorized)
+		c.Abort()
+	}
+	return user, ok
+}
@@ -4,7 +4,8 @@ import "myquote/domain/models"
 
 type Repository interface {
 	FindUser(email string) (bool, models.UserModel, error)
+	FindUserByToken(token string) (bool, models.UserModel, error)
 	Register(name string, email string, password string) error
-	Signout() error
+	Signout(userID int64) error
 	UpdateToken(user models.UserModel, token string) error
 }
@@ -5,5 +5,6 @@ import "myquote/domain/models"
 type Usecase interface {
 	Register(user NewUser) error
 	Login(a Anonymous) (models.User, error)
-	Signout() error
+	Signout(user models.User) error
+	Authenticate(token string) (models.User, error)
 }
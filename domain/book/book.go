@@ -0,0 +1,40 @@
+package book
+
+import "myquote/domain/models"
+
+// BookUpdate changes only the fields that are set.
+type BookUpdate struct {
+	Title    *string `json:"title"`
+	Author   *string `json:"author"`
+	ISBN     *string `json:"isbn"`
+	CoverURL *string `json:"cover_url"`
+}
+
+// Merge moves every quote of a book into the book Into.
+type Merge struct {
+	Into int64 `json:"into"`
+}
+
+type BookCount struct {
+	models.BookModel `gorm:"embedded"`
+	QuoteCount       int64
+}
+
+// ToBook is the book as the API shows it, with the number of its quotes.
+func (b BookCount) ToBook() models.Book {
+	return models.Book{
+		ID:         b.ID,
+		Title:      b.Title,
+		Author:     b.Author,
+		ISBN:       b.ISBN,
+		CoverURL:   b.CoverURL,
+		QuoteCount: b.QuoteCount,
+		CreatedAt:  b.CreatedAt,
+		UpdatedAt:  b.UpdatedAt,
+	}
+}
+
+type ChapterCount struct {
+	models.ChapterModel `gorm:"embedded"`
+	QuoteCount          int64
+}
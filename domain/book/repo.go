@@ -0,0 +1,14 @@
+package book
+
+import "myquote/domain/models"
+
+type Repository interface {
+	FindAll(userID int64) ([]BookCount, error)
+	Find(id int64) (bool, BookCount, error)
+	FindByTitle(userID int64, title string) (bool, models.BookModel, error)
+	Chapters(bookID int64) ([]ChapterCount, error)
+	// Update saves the book and renames it on its quotes.
+	Update(book models.BookModel) error
+	// Merge moves the chapters and quotes of source into target and deletes source.
+	Merge(source models.BookModel, target models.BookModel) error
+}
@@ -0,0 +1,10 @@
+package book
+
+import "myquote/domain/models"
+
+type Usecase interface {
+	FindAll(user models.User) ([]models.Book, error)
+	Chapters(user models.User, id int64) ([]models.Chapter, error)
+	Update(user models.User, id int64, u BookUpdate) (models.Book, error)
+	Merge(user models.User, id int64, m Merge) (models.Book, error)
+}
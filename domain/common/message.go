@@ -3,3 +3,10 @@ package common
 type Message struct {
 	Message string `json:"message"`
 }
+
+// Error is the body of an error response, Code names the error for clients and stays the
+// same when the message is reworded.
+type Error struct {
+	Message
+	Code string `json:"code"`
+}
@@ -0,0 +1,8 @@
+package common
+
+// Page is the envelope of a list response. NextCursor asks for the page after this one,
+// it is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
@@ -0,0 +1,6 @@
+package common
+
+type Random interface {
+	// Float64 returns a number in [0.0, 1.0).
+	Float64() float64
+}
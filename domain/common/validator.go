@@ -4,6 +4,10 @@ type Validator interface {
 	Validate(s string) bool
 }
 
+type IntValidator interface {
+	Validate(n int) bool
+}
+
 type HashValidator interface {
 	Hash(s string) (string, error)
 	Compare(s string, h string) bool
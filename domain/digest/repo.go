@@ -0,0 +1,15 @@
+package digest
+
+import (
+	"myquote/domain/models"
+	"time"
+)
+
+type Repository interface {
+	// FindDueUsers returns the users whose next review mail is due at now, or was never scheduled.
+	FindDueUsers(now time.Time) ([]models.UserModel, error)
+	// Claim moves the next send time of the user from prev to next. It returns false when another
+	// instance moved it first, so only one instance sends the mail.
+	Claim(userID int64, prev *time.Time, next time.Time) (bool, error)
+	CreateDigest(digest models.DigestModel) error
+}
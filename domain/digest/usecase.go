@@ -0,0 +1,18 @@
+package digest
+
+import (
+	"myquote/domain/models"
+	"myquote/domain/quote"
+)
+
+// QuotePicker selects the quotes of a digest, quote.Usecase satisfies it.
+type QuotePicker interface {
+	Draw(user models.User, n int, options quote.RandomOptions) ([]models.Quote, error)
+	Due(user models.User, n int) ([]models.Quote, error)
+	Deliver(user models.User, quotes []models.Quote, channel quote.Channel) error
+}
+
+type Usecase interface {
+	Send(user models.User, n int) error
+	SendDue() error
+}
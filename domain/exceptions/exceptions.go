@@ -1,13 +1,83 @@
 package exceptions
 
-import "errors"
+import "net/http"
+
+// Error is an error the API shows to the user. Code is a stable name a client can switch
+// on, Status the HTTP status of the response and Message the text the user reads. Cause is
+// the error behind it, it is logged and never shown.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Cause   error
+}
+
+func New(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches errors of the same code, so errors.Is finds a wrapped copy of a sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by cause.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Cause = cause
+	return &wrapped
+}
 
 var (
-	InvalidInput          = errors.New("invalid input")
-	InvalidEmailAddr      = errors.New("invalid email address")
-	InvalidPasswordLength = errors.New("password length should be 6-15 characters")
-	AuthError             = errors.New("email or password incorrect")
-	UserNotExists         = errors.New("user not exists")
-	UserExists            = errors.New("user exists")
-	ServerError           = errors.New("server error")
+	InvalidInput          = New("invalid_input", http.StatusBadRequest, "invalid input")
+	InvalidEmailAddr      = New("invalid_email_address", http.StatusBadRequest, "invalid email address")
+	InvalidPasswordLength = New("invalid_password_length", http.StatusBadRequest, "password length should be 6-15 characters")
+	AuthError             = New("wrong_credentials", http.StatusBadRequest, "email or password incorrect")
+	UserNotExists         = New("user_not_exists", http.StatusNotFound, "user not exists")
+	UserExists            = New("user_exists", http.StatusBadRequest, "user exists")
+	ServerError           = New("server_error", http.StatusInternalServerError, "server error")
+	Unauthorized          = New("unauthorized", http.StatusUnauthorized, "unauthorized")
+	Forbidden             = New("forbidden", http.StatusForbidden, "permission denied")
+	InvalidQuote          = New("invalid_quote", http.StatusBadRequest, "quote text should not be empty")
+	InvalidTag            = New("invalid_tag", http.StatusBadRequest, "tag should not contain comma")
+	QuoteNotExists        = New("quote_not_exists", http.StatusNotFound, "quote not exists")
+	InvalidImportFile     = New("invalid_import_file", http.StatusBadRequest, "invalid import file")
+	ImportFileTooLarge    = New("import_file_too_large", http.StatusBadRequest, "import file is too large")
+	NothingToImport       = New("nothing_to_import", http.StatusBadRequest, "no quote found in import file")
+	InvalidCSVMapping     = New("invalid_csv_mapping", http.StatusBadRequest, "csv mapping should name the text column")
+	UnknownCSVColumn      = New("unknown_csv_column", http.StatusBadRequest, "a mapped column is not in the csv header")
+	ImportJobNotExists    = New("import_job_not_exists", http.StatusNotFound, "import job does not exist")
+	ImportJobFinished     = New("import_job_finished", http.StatusConflict, "import job already finished")
+	MailError             = New("mail_error", http.StatusInternalServerError, "send mail error")
+	InvalidMailsPerWeek   = New("invalid_mails_per_week", http.StatusBadRequest, "mails per week should be 1-3")
+	InvalidQuotesPerMail  = New("invalid_quotes_per_mail", http.StatusBadRequest, "quotes per mail should be 3-7")
+	InvalidTimeZone       = New("invalid_time_zone", http.StatusBadRequest, "invalid time zone")
+	InvalidName           = New("invalid_name", http.StatusBadRequest, "name should be 1-50 characters")
+	InvalidVisibility     = New("invalid_visibility", http.StatusBadRequest, "visibility should be private, followers or public")
+	BookNotExists         = New("book_not_exists", http.StatusNotFound, "book does not exist")
+	BookExists            = New("book_exists", http.StatusBadRequest, "book with this title already exists, merge the books instead")
+	InvalidBookTitle      = New("invalid_book_title", http.StatusBadRequest, "book title should be 1-255 characters")
+	InvalidISBN           = New("invalid_isbn", http.StatusBadRequest, "invalid isbn")
+	InvalidCoverURL       = New("invalid_cover_url", http.StatusBadRequest, "cover url should be an http or https url")
+	CannotMergeSameBook   = New("cannot_merge_same_book", http.StatusBadRequest, "cannot merge a book into itself")
+	TagNotExists          = New("tag_not_exists", http.StatusNotFound, "tag does not exist")
+	InvalidTagName        = New("invalid_tag_name", http.StatusBadRequest, "tag should be 1-100 characters")
+	FollowNotExists       = New("follow_not_exists", http.StatusNotFound, "follow request not exists")
+	FollowExists          = New("follow_exists", http.StatusBadRequest, "follow request exists")
+	CannotFollowSelf      = New("cannot_follow_self", http.StatusBadRequest, "cannot follow yourself")
+	InvalidFollowStatus   = New("invalid_follow_status", http.StatusBadRequest, "follow request status cannot be changed")
+	InvalidCursor         = New("invalid_cursor", http.StatusBadRequest, "invalid cursor")
+	InvalidSort           = New("invalid_sort", http.StatusBadRequest, "sort should be created_at, updated_at or book and order asc or desc")
+	InvalidFeedback       = New("invalid_feedback", http.StatusBadRequest, "feedback should be loved, meh or skip")
+	InvalidReviewMode     = New("invalid_review_mode", http.StatusBadRequest, "review mode should be random or spaced")
+	InvalidExportFormat   = New("invalid_export_format", http.StatusBadRequest, "export format should be md, json or csv")
 )
@@ -0,0 +1,17 @@
+package exporter
+
+type Format string
+
+// The formats do not keep the same parts of a quote. JSON keeps all of it. CSV has a column for
+// all of it, the CSV import reads text, book, chapter, page, tags and created_at back through a
+// mapping, id, visibility and updated_at are not imported. Markdown only keeps the books,
+// chapters and texts, which the markdown import reads back.
+const (
+	MARKDOWN Format = "md"
+	JSON     Format = "json"
+	CSV      Format = "csv"
+)
+
+func (f Format) Valid() bool {
+	return f == MARKDOWN || f == JSON || f == CSV
+}
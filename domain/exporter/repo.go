@@ -0,0 +1,9 @@
+package exporter
+
+import "myquote/domain/models"
+
+type Repository interface {
+	// Each calls fn with every quote of userID grouped by book and chapter, quotes without book
+	// or chapter first. It stops at the first error of fn and returns it.
+	Each(userID int64, fn func(q models.QuoteModel) error) error
+}
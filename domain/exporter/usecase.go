@@ -0,0 +1,11 @@
+package exporter
+
+import (
+	"io"
+	"myquote/domain/models"
+)
+
+type Usecase interface {
+	// Export streams the quotes of user to w in format. Nothing is written when the format is invalid.
+	Export(user models.User, format Format, w io.Writer) error
+}
@@ -0,0 +1,5 @@
+package friend
+
+type NewFollow struct {
+	UserID int64 `json:"user_id"`
+}
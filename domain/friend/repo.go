@@ -0,0 +1,15 @@
+package friend
+
+import "myquote/domain/models"
+
+type Repository interface {
+	SearchUsers(prefix string, excludeID int64, limit int) ([]models.UserModel, error)
+	UserExists(id int64) (bool, error)
+	Find(id int64) (bool, models.FollowModel, error)
+	FindBetween(followerID int64, followeeID int64) (bool, models.FollowModel, error)
+	Create(follow models.FollowModel) (models.FollowModel, error)
+	// UpdateStatus changes the status only if it is still from, it returns false when it was changed meanwhile.
+	UpdateStatus(id int64, from Status, to Status) (bool, error)
+	Followers(userID int64, status Status) ([]models.FollowModel, error)
+	Following(userID int64, status Status) ([]models.FollowModel, error)
+}
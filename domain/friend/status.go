@@ -0,0 +1,28 @@
+package friend
+
+type Status string
+
+const (
+	PENDING   Status = "pending"
+	ACCEPTED  Status = "accepted"
+	REJECTED  Status = "rejected"
+	CANCELLED Status = "cancelled"
+)
+
+// transitions lists the statuses a follow request can move to.
+// A rejected or cancelled request can be applied again.
+var transitions = map[Status][]Status{
+	PENDING:   {ACCEPTED, REJECTED, CANCELLED},
+	ACCEPTED:  {CANCELLED},
+	REJECTED:  {PENDING},
+	CANCELLED: {PENDING},
+}
+
+func (s Status) CanTransition(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package friend
+
+import "myquote/domain/models"
+
+type Usecase interface {
+	Search(user models.User, q string) ([]models.Profile, error)
+	Request(user models.User, f NewFollow) (models.Follow, error)
+	Cancel(user models.User, id int64) (models.Follow, error)
+	Accept(user models.User, id int64) (models.Follow, error)
+	Reject(user models.User, id int64) (models.Follow, error)
+	Requests(user models.User) ([]models.Follow, error)
+	Followers(user models.User) ([]models.Follow, error)
+	Following(user models.User) ([]models.Follow, error)
+}
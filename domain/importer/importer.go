@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"myquote/domain/models"
+	"time"
+	"unicode/utf8"
+)
+
+// NOTE_TAG marks the quotes imported from notes, a note is the reader's own words.
+const NOTE_TAG = "note"
+
+// ParsedQuote is a quote read from an import file. Author, Page, Tags and Date are only
+// filled by formats that carry them.
+type ParsedQuote struct {
+	Line    int        `json:"line"`
+	Book    string     `json:"book"`
+	Author  string     `json:"author,omitempty"`
+	Chapter string     `json:"chapter"`
+	Page    int        `json:"page,omitempty"`
+	Text    string     `json:"text"`
+	Tags    []string   `json:"tags,omitempty"`
+	Date    *time.Time `json:"date,omitempty"`
+}
+
+type Warning struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// Check tells why the quote does not fit the book, chapter and tag columns, nil when it does.
+func (q ParsedQuote) Check() *Warning {
+	if utf8.RuneCountInString(q.Book) > models.MAX_TITLE_LENGTH {
+		return &Warning{Line: q.Line, Message: fmt.Sprintf("book title is longer than %d characters", models.MAX_TITLE_LENGTH)}
+	}
+	if utf8.RuneCountInString(q.Chapter) > models.MAX_TITLE_LENGTH {
+		return &Warning{Line: q.Line, Message: fmt.Sprintf("chapter title is longer than %d characters", models.MAX_TITLE_LENGTH)}
+	}
+	for _, t := range q.Tags {
+		if utf8.RuneCountInString(t) > models.MAX_TAG_LENGTH {
+			return &Warning{Line: q.Line, Message: fmt.Sprintf("tag is longer than %d characters", models.MAX_TAG_LENGTH)}
+		}
+	}
+	return nil
+}
+
+// Valid drops the quotes Check rejects and returns a warning for each of them.
+func Valid(quotes []ParsedQuote) ([]ParsedQuote, []Warning) {
+	var valid []ParsedQuote
+	var warnings []Warning
+	for _, q := range quotes {
+		if w := q.Check(); w != nil {
+			warnings = append(warnings, *w)
+			continue
+		}
+		valid = append(valid, q)
+	}
+	return valid, warnings
+}
+
+// Result is what an import would do, the preview of a dry run. Skipped counts the quotes the
+// user already has.
+type Result struct {
+	Quotes   []ParsedQuote `json:"quotes"`
+	Warnings []Warning     `json:"warnings"`
+	Inserted int           `json:"inserted"`
+	Updated  int           `json:"updated"`
+	Skipped  int           `json:"skipped"`
+}
+
+type Format string
+
+const (
+	MARKDOWN Format = "markdown"
+	KINDLE   Format = "kindle"
+	READWISE Format = "readwise"
+	CSV      Format = "csv"
+)
+
+// Source is the format of an import file, Mapping is only read for CSV.
+type Source struct {
+	Format  Format
+	Mapping Mapping
+}
+
+type JobStatus string
+
+const (
+	JOB_QUEUED   JobStatus = "queued"
+	JOB_RUNNING  JobStatus = "running"
+	JOB_DONE     JobStatus = "done"
+	JOB_FAILED   JobStatus = "failed"
+	JOB_CANCELED JobStatus = "canceled"
+)
+
+// Finished tells whether the job will not change anymore.
+func (s JobStatus) Finished() bool {
+	return s == JOB_DONE || s == JOB_FAILED || s == JOB_CANCELED
+}
+
+// Job is an import run in the background. Total is the number of quotes to save, known once
+// the job runs, Processed counts the saved ones. The quotes are saved in batches, a canceled
+// or failed job keeps the quotes it saved before it stopped, Inserted and Updated count them.
+type Job struct {
+	ID         int64      `json:"id"`
+	Format     Format     `json:"format"`
+	Status     JobStatus  `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Inserted   int        `json:"inserted"`
+	Updated    int        `json:"updated"`
+	Skipped    int        `json:"skipped"`
+	Warnings   []Warning  `json:"warnings"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+type Parser interface {
+	Parse(r io.Reader) ([]ParsedQuote, []Warning, error)
+}
+
+// Mapping names the CSV column read into each field of a quote, an empty name skips the field.
+type Mapping struct {
+	Text    string `json:"text" form:"text"`
+	Book    string `json:"book" form:"book"`
+	Author  string `json:"author" form:"author"`
+	Chapter string `json:"chapter" form:"chapter"`
+	Page    string `json:"page" form:"page"`
+	Tags    string `json:"tags" form:"tags"`
+	Date    string `json:"date" form:"date"`
+	Note    string `json:"note" form:"note"`
+	// PageType names a column telling what Page holds, only rows where it is "page" get a page
+	PageType string `json:"-" form:"-"`
+}
+
+// Parsers are the parsers of the supported file formats. CSV builds a parser for a mapping.
+type Parsers struct {
+	Markdown Parser
+	Kindle   Parser
+	Readwise Parser
+	CSV      func(m Mapping) Parser
+}
@@ -0,0 +1,31 @@
+package importer
+
+import (
+	"myquote/domain/models"
+	"time"
+)
+
+type Repository interface {
+	// Hashes tells which of the content hashes the quotes of the user already have.
+	Hashes(userID int64, hashes []string) (map[string]bool, error)
+	// FindChapter returns the quotes of a book chapter in the order they were created.
+	FindChapter(userID int64, book string, chapter string) ([]models.QuoteModel, error)
+	// Save creates and updates the quotes of an import, authors maps a book title to the
+	// author set on the books of the quotes that have none.
+	Save(created []models.QuoteModel, updated []models.QuoteModel, authors map[string]string) error
+
+	CreateJob(job models.ImportJobModel) (models.ImportJobModel, error)
+	FindJob(userID int64, id int64) (bool, models.ImportJobModel, error)
+	// ClaimJob marks the oldest queued job running, false when no job is queued.
+	ClaimJob() (bool, models.ImportJobModel, error)
+	// UpdateJob saves the status and the progress of a job, a cancel request is kept. It is
+	// false when the worker lost the job, because it was queued again or claimed by another.
+	UpdateJob(job models.ImportJobModel) (bool, error)
+	// TouchJob tells the job still makes progress, false like UpdateJob.
+	TouchJob(job models.ImportJobModel, now time.Time) (bool, error)
+	// CancelJob cancels a queued job and asks the worker of a running one to stop.
+	CancelJob(userID int64, id int64, now time.Time) error
+	IsCanceled(id int64) (bool, error)
+	// RequeueJobs queues the running jobs not updated since before again.
+	RequeueJobs(before time.Time) (int64, error)
+}
@@ -0,0 +1,27 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"myquote/domain/models"
+)
+
+type Usecase interface {
+	// Preview tells what importing the file would do without saving anything.
+	Preview(user models.User, source Source, r io.Reader, update bool) (Result, error)
+	// Start checks the file and queues a job importing it. With update a changed quote
+	// replaces the quote at the same position of its book chapter.
+	Start(user models.User, source Source, file []byte, update bool) (Job, error)
+	Job(user models.User, id int64) (Job, error)
+	Cancel(user models.User, id int64) (Job, error)
+}
+
+// Worker runs the queued import jobs.
+type Worker interface {
+	// RunNext runs the oldest queued job, false when no job is queued.
+	RunNext(ctx context.Context) (bool, error)
+	// Recover queues the running jobs again whose worker stopped.
+	Recover() error
+	// Queued receives when a job is queued.
+	Queued() <-chan struct{}
+}
@@ -0,0 +1,12 @@
+package mail
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(m Message) error
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// MAX_TITLE_LENGTH is the longest book or chapter title in runes, the size of BookModel.Title.
+const MAX_TITLE_LENGTH = 255
+
+type BookModel struct {
+	ID        int64
+	UserID    int64  `gorm:"uniqueIndex:idx_user_book_title"`
+	Title     string `gorm:"size:255;uniqueIndex:idx_user_book_title"`
+	Author    string `gorm:"size:255"`
+	ISBN      string `gorm:"size:13"`
+	CoverURL  string `gorm:"size:2048"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (BookModel) TableName() string {
+	return "books"
+}
+
+type ChapterModel struct {
+	ID        int64
+	BookID    int64  `gorm:"uniqueIndex:idx_book_chapter_title"`
+	Title     string `gorm:"size:255;uniqueIndex:idx_book_chapter_title"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ChapterModel) TableName() string {
+	return "chapters"
+}
+
+type Book struct {
+	ID         int64     `json:"id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	ISBN       string    `json:"isbn"`
+	CoverURL   string    `json:"cover_url"`
+	QuoteCount int64     `json:"quote_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type Chapter struct {
+	ID         int64  `json:"id"`
+	BookID     int64  `json:"book_id"`
+	Title      string `json:"title"`
+	QuoteCount int64  `json:"quote_count"`
+}
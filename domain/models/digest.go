@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// DigestModel records a review mail sent to a user.
+type DigestModel struct {
+	ID     int64
+	UserID int64 `gorm:"index"`
+	SentAt time.Time
+	Quotes []DigestQuoteModel `gorm:"foreignKey:DigestID"`
+}
+
+func (DigestModel) TableName() string {
+	return "digests"
+}
+
+type DigestQuoteModel struct {
+	ID       int64
+	DigestID int64 `gorm:"index"`
+	QuoteID  int64
+}
+
+func (DigestQuoteModel) TableName() string {
+	return "digest_quotes"
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// QuoteDrawModel records a quote shown to a user and the channel it was delivered through.
+type QuoteDrawModel struct {
+	ID      int64
+	UserID  int64  `gorm:"index"`
+	QuoteID int64  `gorm:"index"`
+	Channel string `gorm:"size:20;default:api"`
+	DrawnAt time.Time
+}
+
+func (QuoteDrawModel) TableName() string {
+	return "quote_draws"
+}
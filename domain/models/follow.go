@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type FollowModel struct {
+	ID         int64
+	FollowerID int64     `gorm:"uniqueIndex:idx_follower_followee"`
+	FolloweeID int64     `gorm:"uniqueIndex:idx_follower_followee;index"`
+	Status     string    `gorm:"size:20"`
+	Follower   UserModel `gorm:"foreignKey:FollowerID"`
+	Followee   UserModel `gorm:"foreignKey:FolloweeID"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (FollowModel) TableName() string {
+	return "follows"
+}
+
+type Follow struct {
+	ID        int64     `json:"id"`
+	Follower  Profile   `json:"follower"`
+	Followee  Profile   `json:"followee"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Profile is what other users can see of a user.
+type Profile struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
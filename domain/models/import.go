@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ImportJobModel is an import waiting for a worker or run by one. File keeps the upload until
+// the job finishes, so a job stopped by a restart can run again.
+type ImportJobModel struct {
+	ID     int64
+	UserID int64  `gorm:"index"`
+	Format string `gorm:"size:20"`
+	// Mapping is the JSON encoded CSV mapping, empty for other formats
+	Mapping       string
+	UpdateChanged bool
+	// Visibility is the default visibility of the user when the job was queued
+	Visibility string `gorm:"size:20"`
+	File       []byte
+	Status     string `gorm:"size:20;index"`
+	// Attempt counts the claims of the job, a worker owns the job while it is the one it claimed
+	Attempt  int
+	Canceled bool
+	Total    int
+	Inserted int
+	Updated  int
+	Skipped  int
+	// Warnings is the JSON encoded warnings of the file
+	Warnings   string
+	Error      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func (ImportJobModel) TableName() string {
+	return "import_jobs"
+}
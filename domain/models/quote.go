@@ -0,0 +1,73 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+type QuoteModel struct {
+	ID         int64
+	UserID     int64 `gorm:"index"`
+	Text       string
+	Book       string
+	Chapter    string
+	BookID     *int64 `gorm:"index"`
+	ChapterID  *int64 `gorm:"index"`
+	Page       int
+	Tags       string
+	Visibility string `gorm:"size:20;default:followers;index"`
+	// ContentHash is the ContentHash of Text, imports use it to find the quotes a user already has
+	ContentHash string `gorm:"size:64;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (QuoteModel) TableName() string {
+	return "quotes"
+}
+
+// ContentHash identifies the text of a quote, case and spacing do not change it.
+func ContentHash(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// BeforeSave sets the content hash of the text.
+func (q *QuoteModel) BeforeSave(tx *gorm.DB) error {
+	q.ContentHash = ContentHash(q.Text)
+	return nil
+}
+
+// ToQuote is the quote as the API shows it, without its owner.
+func (q QuoteModel) ToQuote() Quote {
+	return Quote{
+		ID:         q.ID,
+		UserID:     q.UserID,
+		Text:       q.Text,
+		Book:       q.Book,
+		Chapter:    q.Chapter,
+		Page:       q.Page,
+		Tags:       SplitTags(q.Tags),
+		Visibility: q.Visibility,
+		CreatedAt:  q.CreatedAt,
+		UpdatedAt:  q.UpdatedAt,
+	}
+}
+
+type Quote struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Text       string    `json:"text"`
+	Book       string    `json:"book"`
+	Chapter    string    `json:"chapter"`
+	Page       int       `json:"page"`
+	Tags       []string  `json:"tags"`
+	Visibility string    `json:"visibility"`
+	Owner      *Profile  `json:"owner,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ReviewModel is the spaced repetition state of a quote for a user.
+type ReviewModel struct {
+	ID             int64
+	UserID         int64 `gorm:"uniqueIndex:idx_user_quote_review"`
+	QuoteID        int64 `gorm:"uniqueIndex:idx_user_quote_review;index"`
+	Ease           float64
+	Interval       int
+	Repetitions    int
+	DueAt          time.Time `gorm:"index"`
+	LastReviewedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (ReviewModel) TableName() string {
+	return "reviews"
+}
+
+type Review struct {
+	QuoteID        int64      `json:"quote_id"`
+	Ease           float64    `json:"ease"`
+	Interval       int        `json:"interval"`
+	Repetitions    int        `json:"repetitions"`
+	DueAt          time.Time  `json:"due_at"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type SearchResult struct {
+	Quote     Quote     `json:"quote"`
+	Highlight Highlight `json:"highlight"`
+}
+
+// Highlight repeats the searched fields of a quote as escaped HTML with the matched fragments
+// in <mark> tags.
+type Highlight struct {
+	Text string   `json:"text"`
+	Book string   `json:"book"`
+	Tags []string `json:"tags"`
+}
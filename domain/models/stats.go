@@ -0,0 +1,27 @@
+package models
+
+type Stats struct {
+	AddedPerWeek  []WeekCount   `json:"added_per_week"`
+	MostReviewed  []ReviewCount `json:"most_reviewed"`
+	LeastReviewed []ReviewCount `json:"least_reviewed"`
+	TopBooks      []Book        `json:"top_books"`
+	Streak        Streak        `json:"streak"`
+}
+
+// WeekCount is how many quotes were added in the week starting on the Monday Week, like 2022-05-02.
+type WeekCount struct {
+	Week   string `json:"week"`
+	Quotes int64  `json:"quotes"`
+}
+
+// ReviewCount is how many times a quote was delivered, through the API or a digest mail.
+type ReviewCount struct {
+	Quote   Quote `json:"quote"`
+	Reviews int64 `json:"reviews"`
+}
+
+// Streak counts the days in a row with a review. The current streak lasts until a whole day passes without one.
+type Streak struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
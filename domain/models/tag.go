@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// MAX_TAG_LENGTH is the longest tag name in runes, the size of TagModel.Name.
+const MAX_TAG_LENGTH = 100
+
+type TagModel struct {
+	ID        int64
+	UserID    int64  `gorm:"uniqueIndex:idx_user_tag_name"`
+	Name      string `gorm:"size:100;uniqueIndex:idx_user_tag_name"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (TagModel) TableName() string {
+	return "tags"
+}
+
+// QuoteTagModel links a quote to one of its tags.
+type QuoteTagModel struct {
+	QuoteID int64 `gorm:"primaryKey;autoIncrement:false"`
+	TagID   int64 `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+func (QuoteTagModel) TableName() string {
+	return "quote_tags"
+}
+
+type Tag struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	QuoteCount int64  `json:"quote_count"`
+}
+
+// JoinTags trims the tags and drops empty and repeated ones, the result is what QuoteModel.Tags keeps.
+func JoinTags(tags []string) string {
+	var cleaned []string
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		cleaned = append(cleaned, t)
+	}
+	return strings.Join(cleaned, ",")
+}
+
+func SplitTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
+}
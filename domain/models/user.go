@@ -5,20 +5,49 @@ import (
 )
 
 type UserModel struct {
-	ID        int64
-	Name      string
-	Email  string
-	Hashed string
-	Token  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                int64
+	Name              string
+	Email             string `gorm:"uniqueIndex;size:255"`
+	Hashed            string
+	Token             string     `gorm:"index;size:255"`
+	MailsPerWeek      int        `gorm:"default:1"`
+	QuotesPerMail     int        `gorm:"default:5"`
+	TimeZone          string     `gorm:"default:UTC"`
+	NextDigestAt      *time.Time `gorm:"index"`
+	DefaultVisibility string     `gorm:"size:20;default:followers"`
+	ReviewMode        string     `gorm:"size:20;default:random"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (UserModel) TableName() string {
+	return "users"
+}
+
+// ToUser is the user as the API shows it, with the preferences as they are saved. The code
+// reading DefaultVisibility or ReviewMode resolves an unknown value to the default.
+func (u UserModel) ToUser() User {
+	return User{
+		ID:                u.ID,
+		Name:              u.Name,
+		Email:             u.Email,
+		Token:             u.Token,
+		DefaultVisibility: u.DefaultVisibility,
+		ReviewMode:        u.ReviewMode,
+		TimeZone:          u.TimeZone,
+		CreatedAt:         u.CreatedAt,
+		UpdatedAt:         u.UpdatedAt,
+	}
 }
 
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Token     string    `json:"token"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	Token             string    `json:"token"`
+	DefaultVisibility string    `json:"default_visibility"`
+	ReviewMode        string    `json:"review_mode"`
+	TimeZone          string    `json:"time_zone"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
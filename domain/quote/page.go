@@ -0,0 +1,60 @@
+package quote
+
+import "time"
+
+type SortKey string
+
+const (
+	SORT_CREATED_AT SortKey = "created_at"
+	SORT_UPDATED_AT SortKey = "updated_at"
+	SORT_BOOK       SortKey = "book"
+)
+
+func (s SortKey) Valid() bool {
+	return s == SORT_CREATED_AT || s == SORT_UPDATED_AT || s == SORT_BOOK
+}
+
+// DefaultOrder lists the newest quotes first and books from A to Z.
+func (s SortKey) DefaultOrder() Order {
+	if s == SORT_BOOK {
+		return ASC
+	}
+	return DESC
+}
+
+type Order string
+
+const (
+	ASC  Order = "asc"
+	DESC Order = "desc"
+)
+
+func (o Order) Valid() bool {
+	return o == ASC || o == DESC
+}
+
+// PageRequest is the page a client asks for. Cursor is the next cursor of the previous page,
+// empty Sort and Order use the ones of the cursor or the defaults.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+	Sort   SortKey
+	Order  Order
+}
+
+// Cursor points at the last quote of a page, by the value of its sort key and its id.
+type Cursor struct {
+	Sort  SortKey   `json:"s"`
+	Order Order     `json:"o"`
+	Time  time.Time `json:"t"`
+	Book  string    `json:"b,omitempty"`
+	ID    int64     `json:"id"`
+}
+
+// Page is the page the repository reads, After is nil on the first page.
+type Page struct {
+	Sort  SortKey
+	Order Order
+	After *Cursor
+	Limit int
+}
@@ -0,0 +1,46 @@
+package quote
+
+type NewQuote struct {
+	Text    string   `json:"text"`
+	Book    string   `json:"book"`
+	Chapter string   `json:"chapter"`
+	Page    int      `json:"page"`
+	Tags    []string `json:"tags"`
+	// Visibility is empty to use the default of the user on create, or to keep the current one on update.
+	Visibility string `json:"visibility"`
+}
+
+// Channel is how a quote is delivered to the user.
+type Channel string
+
+const (
+	CHANNEL_API    Channel = "api"
+	CHANNEL_DIGEST Channel = "digest"
+)
+
+// RandomOptions chooses the quotes Random draws from.
+type RandomOptions struct {
+	// Following also draws from the quotes of the users the user follows.
+	Following bool
+	Tags      TagFilter
+	// Channel the drawn quotes are delivered through, empty is CHANNEL_API.
+	Channel Channel
+}
+
+type Match string
+
+const (
+	MATCH_ALL Match = "all"
+	MATCH_ANY Match = "any"
+)
+
+// TagFilter keeps the quotes tagged with all or any of Tags, no tags keeps every quote.
+type TagFilter struct {
+	Tags  []string
+	Match Match
+}
+
+// TagsUpdate names the tags to add to or remove from a quote.
+type TagsUpdate struct {
+	Tags []string `json:"tags"`
+}
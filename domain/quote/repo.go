@@ -0,0 +1,44 @@
+package quote
+
+import (
+	"myquote/domain/models"
+	"time"
+)
+
+type Repository interface {
+	Create(quote models.QuoteModel) (models.QuoteModel, error)
+	FindAll(userID int64, filter TagFilter) ([]models.QuoteModel, error)
+	// List returns a page of the quotes of userID.
+	List(userID int64, filter TagFilter, page Page) ([]models.QuoteModel, error)
+	Find(id int64) (bool, models.QuoteModel, error)
+	// FindVisible finds the quote when viewerID is allowed to read it.
+	FindVisible(viewerID int64, id int64) (bool, models.QuoteModel, error)
+	// FindByUser returns a page of the quotes of ownerID that viewerID is allowed to read.
+	FindByUser(ownerID int64, viewerID int64, filter TagFilter, page Page) ([]models.QuoteModel, error)
+	Update(quote models.QuoteModel) error
+	Delete(id int64) error
+	RecentDraws(userID int64, limit int) ([]models.QuoteDrawModel, error)
+	LastDrawn(userID int64) (map[int64]time.Time, error)
+	CreateDraws(draws []models.QuoteDrawModel) error
+	// FindFollowing returns the quotes of the users userID follows.
+	FindFollowing(userID int64, filter TagFilter) ([]models.QuoteModel, error)
+	// Feed returns a page of the quotes of the users userID follows.
+	Feed(userID int64, page Page) ([]models.QuoteModel, error)
+	Owners(userIDs []int64) (map[int64]models.UserModel, error)
+	FindReview(userID int64, quoteID int64) (bool, models.ReviewModel, error)
+	SaveReview(review models.ReviewModel) (models.ReviewModel, error)
+	// Due returns the quotes userID should review at now: the reviewed ones that are due, the most
+	// overdue first, then the own quotes never reviewed. Quotes of others count while userID may read them.
+	Due(userID int64, now time.Time, limit int) ([]models.QuoteModel, error)
+	// Postpone moves the quotes of quoteIDs that are due for userID at now to dueAt, the own
+	// quotes never reviewed get a first review due at dueAt.
+	Postpone(userID int64, quoteIDs []int64, now time.Time, dueAt time.Time) error
+}
+
+// SearchRepository finds quotes by their text, book title and tags. Every backend ranks the
+// quotes its own way, the best match comes first.
+type SearchRepository interface {
+	// Search returns the quotes viewerID may read that match all terms. A term matches the
+	// start of a word, "habit" matches "habits".
+	Search(viewerID int64, terms []string, limit int) ([]models.QuoteModel, error)
+}
@@ -0,0 +1,38 @@
+package quote
+
+type Feedback string
+
+const (
+	LOVED Feedback = "loved"
+	MEH   Feedback = "meh"
+	SKIP  Feedback = "skip"
+)
+
+func (f Feedback) Valid() bool {
+	return f == LOVED || f == MEH || f == SKIP
+}
+
+// ReviewFeedback is how the user felt about a quote they just reviewed.
+type ReviewFeedback struct {
+	Feedback Feedback `json:"feedback"`
+}
+
+// ReviewMode is how the digest picks the quotes of a user.
+type ReviewMode string
+
+const (
+	REVIEW_RANDOM ReviewMode = "random"
+	REVIEW_SPACED ReviewMode = "spaced"
+)
+
+func (m ReviewMode) Valid() bool {
+	return m == REVIEW_RANDOM || m == REVIEW_SPACED
+}
+
+// ResolveReviewMode is the review mode of a user, random unless spaced repetition was chosen.
+func ResolveReviewMode(mode string) ReviewMode {
+	if ReviewMode(mode) == REVIEW_SPACED {
+		return REVIEW_SPACED
+	}
+	return REVIEW_RANDOM
+}
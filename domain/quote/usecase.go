@@ -0,0 +1,29 @@
+package quote
+
+import (
+	"myquote/domain/common"
+	"myquote/domain/models"
+)
+
+type Usecase interface {
+	Create(user models.User, q NewQuote) (models.Quote, error)
+	FindAll(user models.User, filter TagFilter, page PageRequest) (common.Page[models.Quote], error)
+	Find(user models.User, id int64) (models.Quote, error)
+	FindByUser(user models.User, ownerID int64, filter TagFilter, page PageRequest) (common.Page[models.Quote], error)
+	Update(user models.User, id int64, q NewQuote) (models.Quote, error)
+	Delete(user models.User, id int64) error
+	AddTags(user models.User, id int64, u TagsUpdate) (models.Quote, error)
+	RemoveTags(user models.User, id int64, u TagsUpdate) (models.Quote, error)
+	Random(user models.User, n int, options RandomOptions) ([]models.Quote, error)
+	// Draw picks quotes like Random without recording them, the caller delivers the ones it uses.
+	Draw(user models.User, n int, options RandomOptions) ([]models.Quote, error)
+	Feed(user models.User, page PageRequest) (common.Page[models.Quote], error)
+	// Review records the feedback of user on a quote and schedules its next review.
+	Review(user models.User, id int64, f ReviewFeedback) (models.Review, error)
+	// Due returns up to n quotes user should review now.
+	Due(user models.User, n int) ([]models.Quote, error)
+	// Deliver records that the quotes reached user through channel. Random records its quotes itself.
+	Deliver(user models.User, quotes []models.Quote, channel Channel) error
+	// Search finds the quotes user may read by the words of query, best match first.
+	Search(user models.User, query string, limit int) ([]models.SearchResult, error)
+}
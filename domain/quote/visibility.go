@@ -0,0 +1,32 @@
+package quote
+
+// Visibility decides who besides the owner can read a quote.
+type Visibility string
+
+const (
+	PRIVATE   Visibility = "private"
+	FOLLOWERS Visibility = "followers"
+	PUBLIC    Visibility = "public"
+)
+
+// DEFAULT_VISIBILITY is used when neither the quote nor its owner chose one.
+const DEFAULT_VISIBILITY = FOLLOWERS
+
+func (v Visibility) Valid() bool {
+	switch v {
+	case PRIVATE, FOLLOWERS, PUBLIC:
+		return true
+	}
+	return false
+}
+
+// ResolveVisibility returns requested, or the default of the user when nothing is requested.
+func ResolveVisibility(requested string, userDefault string) Visibility {
+	if requested != "" {
+		return Visibility(requested)
+	}
+	if Visibility(userDefault).Valid() {
+		return Visibility(userDefault)
+	}
+	return DEFAULT_VISIBILITY
+}
@@ -0,0 +1,26 @@
+package stats
+
+import (
+	"myquote/domain/book"
+	"myquote/domain/models"
+	"time"
+)
+
+// QuoteCount is a quote together with how many times it was delivered to its owner.
+type QuoteCount struct {
+	models.QuoteModel `gorm:"embedded"`
+	Deliveries        int64
+}
+
+type Repository interface {
+	// Added returns when the quotes of userID added since were created.
+	Added(userID int64, since time.Time) ([]time.Time, error)
+	// MostDelivered returns the quotes of userID delivered to them the most, never delivered quotes are left out.
+	MostDelivered(userID int64, limit int) ([]QuoteCount, error)
+	// LeastDelivered returns the quotes of userID delivered to them the least, never delivered quotes first.
+	LeastDelivered(userID int64, limit int) ([]QuoteCount, error)
+	// TopBooks returns the books of userID with the most quotes.
+	TopBooks(userID int64, limit int) ([]book.BookCount, error)
+	// Deliveries returns when quotes were delivered to userID, oldest first.
+	Deliveries(userID int64) ([]time.Time, error)
+}
@@ -0,0 +1,8 @@
+package stats
+
+import "myquote/domain/models"
+
+type Usecase interface {
+	// Stats summarizes the quotes of user over the last weeks, the quote and book lists hold up to limit entries.
+	Stats(user models.User, weeks int, limit int) (models.Stats, error)
+}
@@ -0,0 +1,12 @@
+package tag
+
+import "myquote/domain/models"
+
+type Repository interface {
+	// FindAll returns every tag of the user with the number of quotes it is on, 0 for unused tags.
+	FindAll(userID int64) ([]TagCount, error)
+	Find(id int64) (bool, TagCount, error)
+	// Rename renames the tag on every quote. When the user has a tag with the new name already,
+	// the two tags become one and its id is returned.
+	Rename(tag models.TagModel, name string) (int64, error)
+}
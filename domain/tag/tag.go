@@ -0,0 +1,12 @@
+package tag
+
+import "myquote/domain/models"
+
+type Rename struct {
+	Name string `json:"name"`
+}
+
+type TagCount struct {
+	models.TagModel `gorm:"embedded"`
+	QuoteCount      int64
+}
@@ -0,0 +1,8 @@
+package tag
+
+import "myquote/domain/models"
+
+type Usecase interface {
+	FindAll(user models.User) ([]models.Tag, error)
+	Rename(user models.User, id int64, r Rename) (models.Tag, error)
+}
@@ -0,0 +1,18 @@
+package user
+
+type Preferences struct {
+	MailsPerWeek      int    `json:"mails_per_week"`
+	QuotesPerMail     int    `json:"quotes_per_mail"`
+	TimeZone          string `json:"time_zone"`
+	DefaultVisibility string `json:"default_visibility"`
+	ReviewMode        string `json:"review_mode"`
+}
+
+// PreferencesUpdate changes only the fields that are set.
+type PreferencesUpdate struct {
+	MailsPerWeek      *int    `json:"mails_per_week"`
+	QuotesPerMail     *int    `json:"quotes_per_mail"`
+	TimeZone          *string `json:"time_zone"`
+	DefaultVisibility *string `json:"default_visibility"`
+	ReviewMode        *string `json:"review_mode"`
+}
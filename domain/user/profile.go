@@ -0,0 +1,11 @@
+package user
+
+type NameUpdate struct {
+	Name string `json:"name"`
+}
+
+// EmailUpdate needs the current password, a stolen token alone cannot take over the account.
+type EmailUpdate struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
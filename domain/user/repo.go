@@ -0,0 +1,15 @@
+package user
+
+import (
+	"myquote/domain/models"
+	"time"
+)
+
+type Repository interface {
+	Find(id int64) (bool, models.UserModel, error)
+	FindByEmail(email string) (bool, models.UserModel, error)
+	UpdateName(id int64, name string) error
+	// UpdateEmail is false when another user has the email, also when they took it meanwhile.
+	UpdateEmail(id int64, email string) (bool, error)
+	UpdatePreferences(id int64, p Preferences, nextDigestAt time.Time) error
+}
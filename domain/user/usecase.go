@@ -0,0 +1,10 @@
+package user
+
+import "myquote/domain/models"
+
+type Usecase interface {
+	UpdateName(user models.User, u NameUpdate) (models.User, error)
+	UpdateEmail(user models.User, u EmailUpdate) (models.User, error)
+	Preferences(user models.User) (Preferences, error)
+	UpdatePreferences(user models.User, p PreferencesUpdate) (Preferences, error)
+}
@@ -1,7 +1,6 @@
 package auth
 
 import (
-	"errors"
 	"github.com/gin-gonic/gin"
 	"myquote/domain"
 	"myquote/domain/auth"
@@ -23,7 +22,7 @@ func NewAuthHTTPHandler(c *gin.Engine, l domain.Logger, uc auth.Usecase) {
 	handler := &handler{logger: l, registerUc: uc}
 	c.POST(REGISTER_ENDPOINT, handler.register)
 	c.POST(LOGIN_ENDPOINT, handler.login)
-	c.POST(SIGNOUT_ENDPOINT, handler.signout)
+	c.POST(SIGNOUT_ENDPOINT, NewAuthMiddleware(l, uc), handler.signout)
 }
 
 func (h *handler) register(c *gin.Context) {
@@ -31,13 +30,13 @@ func (h *handler) register(c *gin.Context) {
 	err := c.Bind(&user)
 	if err != nil {
 		h.logger.Debugf("Convert new user json error: %s", err.Error())
-		c.JSON(http.StatusBadRequest, common.Message{Message: exceptions.InvalidInput.Error()})
+		c.Error(exceptions.InvalidInput)
 		return
 	}
 	err = h.registerUc.Register(user)
 	if err != nil {
 		h.logger.Warnf("auth user error: %s", err.Error())
-		c.JSON(http.StatusBadRequest, common.Message{Message: err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -49,17 +48,12 @@ func (h *handler) login(c *gin.Context) {
 	err := c.Bind(&info)
 	if err != nil {
 		h.logger.Debugf("Convert login info json error: %s", err.Error())
-		c.JSON(http.StatusBadRequest, common.Message{Message: exceptions.InvalidInput.Error()})
+		c.Error(exceptions.InvalidInput)
 		return
 	}
 	user, err := h.registerUc.Login(info)
-	if err != nil && errors.Is(err, exceptions.ServerError) {
-		c.JSON(http.StatusInternalServerError, common.Message{Message: err.Error()})
-		return
-	}
-
 	if err != nil {
-		c.JSON(http.StatusBadRequest, common.Message{Message: err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -67,9 +61,13 @@ func (h *handler) login(c *gin.Context) {
 }
 
 func (h *handler) signout(c *gin.Context) {
-	err := h.registerUc.Signout()
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	err := h.registerUc.Signout(user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, common.Message{Message: err.Error()})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, common.Message{Message: "sign out successful"})
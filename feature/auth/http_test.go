@@ -11,6 +11,7 @@ import (
 	"myquote/domain/common"
 	"myquote/domain/exceptions"
 	"myquote/domain/models"
+	"myquote/feature/middleware"
 	"myquote/service/logger"
 	"net/http"
 	"net/http/httptest"
@@ -22,8 +23,8 @@ type MockedAuthUsecase struct {
 	mock.Mock
 }
 
-func (m *MockedAuthUsecase) Signout() error {
-	args := m.Called()
+func (m *MockedAuthUsecase) Signout(user models.User) error {
+	args := m.Called(user)
 	return args.Error(0)
 }
 
@@ -32,6 +33,11 @@ func (m *MockedAuthUsecase) Register(user auth.NewUser) error {
 	return args.Error(0)
 }
 
+func (m *MockedAuthUsecase) Authenticate(token string) (models.User, error) {
+	args := m.Called(token)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
 func (m *MockedAuthUsecase) Login(i auth.Anonymous) (models.User, error) {
 	args := m.Called(i)
 	return args.Get(0).(models.User), args.Error(1)
@@ -53,6 +59,7 @@ func (s *AuthTestSuite) SetupTest() {
 	s.uc = new(MockedAuthUsecase)
 	s.l = logger.NewLogger("")
 	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
 	s.r = httptest.NewRecorder()
 }
 
@@ -182,17 +189,42 @@ func (s *AuthTestSuite) TestLoginShowServerErrorException() {
 }
 
 func (s *AuthTestSuite) TestSignoutSuccess() {
-	s.uc.On("Signout").Return(nil)
+	user := models.User{ID: 1, Token: "secret token"}
+	s.uc.On("Authenticate", "secret token").Return(user, nil)
+	s.uc.On("Signout", user).Return(nil)
 	NewAuthHTTPHandler(s.g, s.l, s.uc)
 	req, _ := newTestRequest(http.MethodPost, SIGNOUT_ENDPOINT, nil)
+	req.Header.Set(AUTHORIZATION_HEADER, "Bearer secret token")
 	s.g.ServeHTTP(s.r, req)
 	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.uc.AssertCalled(s.T(), "Signout", user)
+}
+
+func (s *AuthTestSuite) TestSignoutWithoutTokenRespondUnauthorized() {
+	NewAuthHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodPost, SIGNOUT_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Signout", mock.Anything)
+}
+
+func (s *AuthTestSuite) TestSignoutWithRevokedTokenRespondUnauthorized() {
+	s.uc.On("Authenticate", "revoked token").Return(models.User{}, exceptions.Unauthorized)
+	NewAuthHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodPost, SIGNOUT_ENDPOINT, nil)
+	req.Header.Set(AUTHORIZATION_HEADER, "Bearer revoked token")
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Signout", mock.Anything)
 }
 
 func (s *AuthTestSuite) TestRespondServerErrorWhenSignoutFailure() {
-	s.uc.On("Signout").Return(exceptions.ServerError)
+	user := models.User{ID: 1, Token: "secret token"}
+	s.uc.On("Authenticate", "secret token").Return(user, nil)
+	s.uc.On("Signout", user).Return(exceptions.ServerError)
 	NewAuthHTTPHandler(s.g, s.l, s.uc)
 	req, _ := newTestRequest(http.MethodPost, SIGNOUT_ENDPOINT, nil)
+	req.Header.Set(AUTHORIZATION_HEADER, "Bearer secret token")
 	s.g.ServeHTTP(s.r, req)
 	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
 }
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"strings"
+)
+
+const AUTHORIZATION_HEADER = "Authorization"
+const BEARER_SCHEME = "Bearer"
+
+// NewAuthMiddleware resolves the user of the bearer token and puts it into the context under auth.USER_KEY.
+// Requests without a known token are rejected with 401.
+func NewAuthMiddleware(l domain.Logger, uc auth.Usecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader(AUTHORIZATION_HEADER))
+		if !ok {
+			l.Debugf("missing bearer token, path: %s", c.FullPath())
+			c.Error(exceptions.Unauthorized)
+			c.Abort()
+			return
+		}
+		user, err := uc.Authenticate(token)
+		if err != nil && errors.Is(err, exceptions.ServerError) {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.Error(exceptions.Unauthorized)
+			c.Abort()
+			return
+		}
+		c.Set(auth.USER_KEY, user)
+		c.Next()
+	}
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, BEARER_SCHEME) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
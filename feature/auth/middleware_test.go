@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const PROTECTED_ENDPOINT = "/protected"
+
+type AuthMiddlewareTestSuite struct {
+	suite.Suite
+	uc *MockedAuthUsecase
+	l  domain.Logger
+	g  *gin.Engine
+	r  *httptest.ResponseRecorder
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	suite.Run(t, new(AuthMiddlewareTestSuite))
+}
+
+func (s *AuthMiddlewareTestSuite) SetupTest() {
+	s.uc = new(MockedAuthUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.g.GET(PROTECTED_ENDPOINT, NewAuthMiddleware(s.l, s.uc), func(c *gin.Context) {
+		user, _ := auth.CurrentUser(c)
+		c.JSON(http.StatusOK, user)
+	})
+}
+
+func (s *AuthMiddlewareTestSuite) serve(authorization string) {
+	req, _ := http.NewRequest(http.MethodGet, PROTECTED_ENDPOINT, nil)
+	if authorization != "" {
+		req.Header.Set(AUTHORIZATION_HEADER, authorization)
+	}
+	s.g.ServeHTTP(s.r, req)
+}
+
+func (s *AuthMiddlewareTestSuite) TestPutUserIntoContext() {
+	user := models.User{ID: 1, Name: "Lester", Email: "123@gmail.com", Token: "token"}
+	s.uc.On("Authenticate", "token").Return(user, nil)
+	s.serve("Bearer token")
+
+	var actual models.User
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(user.ID, actual.ID)
+}
+
+func (s *AuthMiddlewareTestSuite) TestRejectMissingToken() {
+	s.serve("")
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.Assert().Equal(exceptions.Unauthorized.Error(), m.Message)
+	s.uc.AssertNotCalled(s.T(), "Authenticate", mock.Anything)
+}
+
+func (s *AuthMiddlewareTestSuite) TestRejectOtherScheme() {
+	s.serve("Basic dXNlcjpwYXNz")
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Authenticate", mock.Anything)
+}
+
+func (s *AuthMiddlewareTestSuite) TestRejectUnknownToken() {
+	s.uc.On("Authenticate", "unknown").Return(models.User{}, exceptions.Unauthorized)
+	s.serve("Bearer unknown")
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.Assert().Equal(exceptions.Unauthorized.Error(), m.Message)
+}
+
+func (s *AuthMiddlewareTestSuite) TestRespondServerErrorWhenAuthenticateFailure() {
+	s.uc.On("Authenticate", "token").Return(models.User{}, exceptions.ServerError)
+	s.serve("Bearer token")
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+}
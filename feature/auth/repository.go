@@ -12,6 +12,20 @@ type Repository struct {
 	db *gorm.DB
 }
 
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the users table.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.UserModel{})
+	if err != nil {
+		r.l.Errorf("migrate users table error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
 func (r *Repository) FindUser(email string) (bool, models.UserModel, error) {
 	var user models.UserModel
 	result := r.db.Table("users").First(&user, "email = ?", email)
@@ -29,6 +43,22 @@ func (r *Repository) FindUser(email string) (bool, models.UserModel, error) {
 	return true, user, nil
 }
 
+func (r *Repository) FindUserByToken(token string) (bool, models.UserModel, error) {
+	if token == "" {
+		return false, models.UserModel{}, nil
+	}
+	var user models.UserModel
+	result := r.db.Table("users").First(&user, "token = ?", token)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.UserModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find user by token error.\n The error message is: %s", result.Error.Error())
+		return false, models.UserModel{}, result.Error
+	}
+	return true, user, nil
+}
+
 func (r *Repository) Register(name string, email string, password string) error {
 	user := models.UserModel{Name: name, Email: email, Hashed: password}
 	result := r.db.Create(&user)
@@ -38,3 +68,26 @@ func (r *Repository) Register(name string, email string, password string) error
 	}
 	return nil
 }
+
+func (r *Repository) UpdateToken(user models.UserModel, token string) error {
+	result := r.db.Model(&models.UserModel{}).Where("id = ?", user.ID).Update("token", token)
+	if result.Error != nil {
+		r.l.Debugf("update token error, user id: %d\n The error message: %s", user.ID, result.Error.Error())
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		r.l.Debugf("update token error, user id %d not found", user.ID)
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Signout clears the token of the user. Clearing an already cleared token is not an error.
+func (r *Repository) Signout(userID int64) error {
+	result := r.db.Model(&models.UserModel{}).Where("id = ?", userID).Update("token", "")
+	if result.Error != nil {
+		r.l.Debugf("sign out error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
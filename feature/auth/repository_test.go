@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+)
+
+type AuthRepositoryTestSuite struct {
+	suite.Suite
+	repo *Repository
+}
+
+func TestAuthRepository(t *testing.T) {
+	suite.Run(t, new(AuthRepositoryTestSuite))
+}
+
+func (s *AuthRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+}
+
+func (s *AuthRepositoryTestSuite) TestFindUserNotExists() {
+	find, user, err := s.repo.FindUser("123@gmail.com")
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+	s.Assert().Equal(models.UserModel{}, user)
+}
+
+func (s *AuthRepositoryTestSuite) TestRegisterAndFindUser() {
+	err := s.repo.Register("Lester", "123@gmail.com", "this is a hash")
+	s.Require().Nil(err)
+
+	find, user, err := s.repo.FindUser("123@gmail.com")
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().NotZero(user.ID)
+	s.Assert().Equal("Lester", user.Name)
+	s.Assert().Equal("this is a hash", user.Hashed)
+	s.Assert().False(user.CreatedAt.IsZero())
+}
+
+func (s *AuthRepositoryTestSuite) TestRegisterDuplicateEmail() {
+	s.Require().Nil(s.repo.Register("Lester", "123@gmail.com", "this is a hash"))
+	err := s.repo.Register("Other", "123@gmail.com", "another hash")
+	s.Assert().NotNil(err)
+}
+
+func (s *AuthRepositoryTestSuite) TestUpdateToken() {
+	s.Require().Nil(s.repo.Register("Lester", "123@gmail.com", "this is a hash"))
+	_, user, _ := s.repo.FindUser("123@gmail.com")
+
+	err := s.repo.UpdateToken(user, "this is a token")
+	s.Assert().Nil(err)
+	_, updated, _ := s.repo.FindUser("123@gmail.com")
+	s.Assert().Equal("this is a token", updated.Token)
+}
+
+func (s *AuthRepositoryTestSuite) TestUpdateTokenUserNotExists() {
+	err := s.repo.UpdateToken(models.UserModel{ID: 99}, "this is a token")
+	s.Assert().NotNil(err)
+}
+
+func (s *AuthRepositoryTestSuite) TestFindUserByToken() {
+	s.Require().Nil(s.repo.Register("Lester", "123@gmail.com", "this is a hash"))
+	_, user, _ := s.repo.FindUser("123@gmail.com")
+	s.Require().Nil(s.repo.UpdateToken(user, "this is a token"))
+
+	find, actual, err := s.repo.FindUserByToken("this is a token")
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal(user.ID, actual.ID)
+
+	find, _, err = s.repo.FindUserByToken("unknown")
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *AuthRepositoryTestSuite) TestFindUserByEmptyTokenNeverMatches() {
+	s.Require().Nil(s.repo.Register("Lester", "123@gmail.com", "this is a hash"))
+
+	find, _, err := s.repo.FindUserByToken("")
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *AuthRepositoryTestSuite) TestSignoutRevokesToken() {
+	s.Require().Nil(s.repo.Register("Lester", "123@gmail.com", "this is a hash"))
+	_, user, _ := s.repo.FindUser("123@gmail.com")
+	s.Require().Nil(s.repo.UpdateToken(user, "this is a token"))
+
+	s.Assert().Nil(s.repo.Signout(user.ID))
+	find, _, err := s.repo.FindUserByToken("this is a token")
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *AuthRepositoryTestSuite) TestSignoutIsIdempotent() {
+	s.Require().Nil(s.repo.Register("Lester", "123@gmail.com", "this is a hash"))
+	_, user, _ := s.repo.FindUser("123@gmail.com")
+
+	s.Assert().Nil(s.repo.Signout(user.ID))
+	s.Assert().Nil(s.repo.Signout(user.ID))
+}
@@ -62,14 +62,15 @@ func (uc *Usecase) Register(user auth.NewUser) error {
 
 func (uc *Usecase) Login(i auth.Anonymous) (models.User, error) {
 	find, u, err := uc.r.FindUser(i.Email)
-	if !find {
-		uc.l.Warnf("not found u. email: %s", i.Email)
-		return models.User{}, exceptions.UserNotExists
-	}
 	if err != nil {
 		uc.l.Debugf("find u error when u login.\n message: %s", err.Error())
 		return models.User{}, exceptions.ServerError
 	}
+	// an unknown email fails like a wrong password, so the response does not tell which emails are registered
+	if !find {
+		uc.l.Warnf("not found u. email: %s", i.Email)
+		return models.User{}, exceptions.AuthError
+	}
 	//compare password & hash
 	matched := uc.hashv.Compare(i.Password, u.Hashed)
 	if !matched {
@@ -88,20 +89,28 @@ func (uc *Usecase) Login(i auth.Anonymous) (models.User, error) {
 		return models.User{}, exceptions.ServerError
 	}
 
-	return models.User{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Token:     user.Token,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+	return user.ToUser(), nil
 }
 
-func (uc *Usecase) Signout() error {
-	err := uc.r.Signout()
+func (uc *Usecase) Signout(user models.User) error {
+	err := uc.r.Signout(user.ID)
 	if err != nil {
 		return exceptions.ServerError
 	}
 	return nil
 }
+
+func (uc *Usecase) Authenticate(token string) (models.User, error) {
+	if token == "" {
+		return models.User{}, exceptions.Unauthorized
+	}
+	find, u, err := uc.r.FindUserByToken(token)
+	if err != nil {
+		return models.User{}, exceptions.ServerError
+	}
+	if !find {
+		uc.l.Debugf("unknown token")
+		return models.User{}, exceptions.Unauthorized
+	}
+	return u.ToUser(), nil
+}
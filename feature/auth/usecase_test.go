@@ -15,8 +15,8 @@ type MockedAuthRepo struct {
 	mock.Mock
 }
 
-func (m *MockedAuthRepo) Signout() error {
-	args := m.Called()
+func (m *MockedAuthRepo) Signout(userID int64) error {
+	args := m.Called(userID)
 	return args.Error(0)
 }
 
@@ -35,6 +35,11 @@ func (m *MockedAuthRepo) FindUser(email string) (bool, models.UserModel, error)
 	return args.Bool(0), args.Get(1).(models.UserModel), args.Error(2)
 }
 
+func (m *MockedAuthRepo) FindUserByToken(token string) (bool, models.UserModel, error) {
+	args := m.Called(token)
+	return args.Bool(0), args.Get(1).(models.UserModel), args.Error(2)
+}
+
 type MockedEmailValidator struct {
 	mock.Mock
 }
@@ -181,14 +186,14 @@ func (s *AuthUsecaseTestSuite) TestRegisterThrowServerErrorWhenRegisterFailure()
 	s.Assert().Equal(exceptions.ServerError, err)
 }
 
-func (s *AuthUsecaseTestSuite) TestLoginThrowUserNotExistException() {
+func (s *AuthUsecaseTestSuite) TestLoginUnknownEmailThrowAuthErrorException() {
 	info := auth.Anonymous{
 		Email:    "123@gmail.com",
 		Password: "123456",
 	}
-	s.repo.On("FindUser", info.Email).Return(false, models.UserModel{}, exceptions.UserNotExists)
+	s.repo.On("FindUser", info.Email).Return(false, models.UserModel{}, nil)
 	_, err := s.uc.Login(info)
-	s.Assert().Equal(exceptions.UserNotExists, err)
+	s.Assert().Equal(exceptions.AuthError, err)
 }
 
 func (s *AuthUsecaseTestSuite) TestLoginThrowUserServerErrorException() {
@@ -310,13 +315,40 @@ func (s *AuthUsecaseTestSuite) TestLoginThrowServerErrorWhenFindUserFailure() {
 }
 
 func (s *AuthUsecaseTestSuite) TestSignoutSuccess() {
-	s.repo.On("Signout").Return(nil)
-	err := s.uc.Signout()
+	s.repo.On("Signout", int64(1)).Return(nil)
+	err := s.uc.Signout(models.User{ID: 1})
 	s.Assert().Equal(nil, err)
 }
 
 func (s *AuthUsecaseTestSuite) TestSignoutReturnServerErrorWhenFailure() {
-	s.repo.On("Signout").Return(exceptions.ServerError)
-	err := s.uc.Signout()
+	s.repo.On("Signout", int64(1)).Return(exceptions.ServerError)
+	err := s.uc.Signout(models.User{ID: 1})
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *AuthUsecaseTestSuite) TestAuthenticateEmptyToken() {
+	_, err := s.uc.Authenticate("")
+	s.Assert().Equal(exceptions.Unauthorized, err)
+	s.repo.AssertNotCalled(s.T(), "FindUserByToken", mock.Anything)
+}
+
+func (s *AuthUsecaseTestSuite) TestAuthenticateUnknownToken() {
+	s.repo.On("FindUserByToken", "unknown").Return(false, models.UserModel{}, nil)
+	_, err := s.uc.Authenticate("unknown")
+	s.Assert().Equal(exceptions.Unauthorized, err)
+}
+
+func (s *AuthUsecaseTestSuite) TestAuthenticateThrowServerError() {
+	s.repo.On("FindUserByToken", "token").Return(false, models.UserModel{}, exceptions.ServerError)
+	_, err := s.uc.Authenticate("token")
 	s.Assert().Equal(exceptions.ServerError, err)
 }
+
+func (s *AuthUsecaseTestSuite) TestAuthenticateSuccess() {
+	user := models.UserModel{ID: 1, Name: "Lester", Email: "123@gmail.com", Hashed: "this is a hash", Token: "token"}
+	s.repo.On("FindUserByToken", "token").Return(true, user, nil)
+	actual, err := s.uc.Authenticate("token")
+	s.Assert().Nil(err)
+	s.Assert().Equal(user.ID, actual.ID)
+	s.Assert().Equal(user.Email, actual.Email)
+}
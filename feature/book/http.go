@@ -0,0 +1,115 @@
+package book
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/book"
+	"myquote/domain/exceptions"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	logger domain.Logger
+	bookUc book.Usecase
+}
+
+const BOOKS_ENDPOINT = "/api/books"
+
+// NewBookHTTPHandler registers the book routes.
+func NewBookHTTPHandler(c *gin.Engine, l domain.Logger, uc book.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, bookUc: uc}
+	g := c.Group(BOOKS_ENDPOINT, middlewares...)
+	g.GET("", handler.findAll)
+	g.GET("/:id/chapters", handler.chapters)
+	g.PATCH("/:id", handler.update)
+	g.POST("/:id/merge", handler.merge)
+}
+
+func (h *handler) findAll(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	books, err := h.bookUc.FindAll(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, books)
+}
+
+func (h *handler) chapters(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := bookID(c)
+	if !ok {
+		return
+	}
+	chapters, err := h.bookUc.Chapters(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, chapters)
+}
+
+func (h *handler) update(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := bookID(c)
+	if !ok {
+		return
+	}
+	var u book.BookUpdate
+	err := c.Bind(&u)
+	if err != nil {
+		h.logger.Debugf("Convert book json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	updated, err := h.bookUc.Update(user, id, u)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *handler) merge(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := bookID(c)
+	if !ok {
+		return
+	}
+	var m book.Merge
+	err := c.Bind(&m)
+	if err != nil {
+		h.logger.Debugf("Convert merge json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	merged, err := h.bookUc.Merge(user, id, m)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, merged)
+}
+
+func bookID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return 0, false
+	}
+	return id, true
+}
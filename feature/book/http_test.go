@@ -0,0 +1,145 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/book"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedBookUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedBookUsecase) FindAll(user models.User) ([]models.Book, error) {
+	args := m.Called(user)
+	return args.Get(0).([]models.Book), args.Error(1)
+}
+
+func (m *MockedBookUsecase) Chapters(user models.User, id int64) ([]models.Chapter, error) {
+	args := m.Called(user, id)
+	return args.Get(0).([]models.Chapter), args.Error(1)
+}
+
+func (m *MockedBookUsecase) Update(user models.User, id int64, u book.BookUpdate) (models.Book, error) {
+	args := m.Called(user, id, u)
+	return args.Get(0).(models.Book), args.Error(1)
+}
+
+func (m *MockedBookUsecase) Merge(user models.User, id int64, merge book.Merge) (models.Book, error) {
+	args := m.Called(user, id, merge)
+	return args.Get(0).(models.Book), args.Error(1)
+}
+
+type BookTestSuite struct {
+	suite.Suite
+	uc   *MockedBookUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestBookHTTPHandler(t *testing.T) {
+	suite.Run(t, new(BookTestSuite))
+}
+
+func (s *BookTestSuite) SetupTest() {
+	s.uc = new(MockedBookUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *BookTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *BookTestSuite) TestFindAll() {
+	books := []models.Book{{ID: 1, Title: "Book1", QuoteCount: 3}}
+	s.uc.On("FindAll", s.user).Return(books, nil)
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, BOOKS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.Book
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(books, actual)
+}
+
+func (s *BookTestSuite) TestChaptersNotExists() {
+	s.uc.On("Chapters", s.user, int64(2)).Return([]models.Chapter{}, exceptions.BookNotExists)
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, BOOKS_ENDPOINT+"/2/chapters", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+}
+
+func (s *BookTestSuite) TestUpdate() {
+	title := "Atomic Habits"
+	s.uc.On("Update", s.user, int64(1), book.BookUpdate{Title: &title}).Return(models.Book{ID: 1, Title: title}, nil)
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, BOOKS_ENDPOINT+"/1", []byte(`{"title":"Atomic Habits"}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Book
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(title, actual.Title)
+}
+
+func (s *BookTestSuite) TestUpdateExistingTitle() {
+	s.uc.On("Update", s.user, int64(1), mock.Anything).Return(models.Book{}, exceptions.BookExists)
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, BOOKS_ENDPOINT+"/1", []byte(`{"title":"Book2"}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.BookExists.Error(), actual.Message)
+}
+
+func (s *BookTestSuite) TestMerge() {
+	s.uc.On("Merge", s.user, int64(1), book.Merge{Into: 2}).Return(models.Book{ID: 2, QuoteCount: 5}, nil)
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, BOOKS_ENDPOINT+"/1/merge", []byte(`{"into":2}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *BookTestSuite) TestMergeForbidden() {
+	s.uc.On("Merge", s.user, int64(1), book.Merge{Into: 2}).Return(models.Book{}, exceptions.Forbidden)
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, BOOKS_ENDPOINT+"/1/merge", []byte(`{"into":2}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusForbidden, s.r.Code)
+}
+
+func (s *BookTestSuite) TestInvalidID() {
+	NewBookHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, BOOKS_ENDPOINT+"/abc/chapters", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+}
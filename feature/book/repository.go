@@ -0,0 +1,182 @@
+package book
+
+import (
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/book"
+	"myquote/domain/models"
+	"myquote/service/quotelink"
+)
+
+const LINK_BATCH_SIZE = 100
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the books and chapters tables and links the quotes saved before
+// books existed. It needs the quotes table.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.BookModel{}, &models.ChapterModel{})
+	if err != nil {
+		r.l.Errorf("migrate books table error: %s", err.Error())
+		return err
+	}
+
+	var quotes []models.QuoteModel
+	result := r.db.Where("book <> '' AND book_id IS NULL").FindInBatches(&quotes, LINK_BATCH_SIZE, func(tx *gorm.DB, batch int) error {
+		link := quotelink.New(tx)
+		for i := range quotes {
+			err := link.Book(&quotes[i])
+			if err != nil {
+				return err
+			}
+			// UpdateColumns keeps updated_at, linking does not change the quote
+			err = tx.Model(&quotes[i]).UpdateColumns(map[string]interface{}{
+				"book_id":    quotes[i].BookID,
+				"chapter_id": quotes[i].ChapterID,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		r.l.Errorf("link quotes to books error: %s", result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
+
+func (r *Repository) FindAll(userID int64) ([]book.BookCount, error) {
+	var books []book.BookCount
+	result := r.counted().Where("books.user_id = ?", userID).Order("books.title, books.id").Scan(&books)
+	if result.Error != nil {
+		r.l.Debugf("find books error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return books, nil
+}
+
+func (r *Repository) Find(id int64) (bool, book.BookCount, error) {
+	var books []book.BookCount
+	result := r.counted().Where("books.id = ?", id).Scan(&books)
+	if result.Error != nil {
+		r.l.Debugf("find book error, book id: %d\n The error message: %s", id, result.Error.Error())
+		return false, book.BookCount{}, result.Error
+	}
+	if len(books) == 0 {
+		return false, book.BookCount{}, nil
+	}
+	return true, books[0], nil
+}
+
+// counted selects books together with how many quotes they have.
+func (r *Repository) counted() *gorm.DB {
+	return r.db.Model(&models.BookModel{}).
+		Select("books.*, COUNT(quotes.id) AS quote_count").
+		Joins("LEFT JOIN quotes ON quotes.book_id = books.id").
+		Group("books.id")
+}
+
+func (r *Repository) FindByTitle(userID int64, title string) (bool, models.BookModel, error) {
+	var books []models.BookModel
+	result := r.db.Where("user_id = ? AND title = ?", userID, title).Limit(1).Find(&books)
+	if result.Error != nil {
+		r.l.Debugf("find book by title error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return false, models.BookModel{}, result.Error
+	}
+	if len(books) == 0 {
+		return false, models.BookModel{}, nil
+	}
+	return true, books[0], nil
+}
+
+// Chapters lists the chapters of the book in the order they were first used.
+func (r *Repository) Chapters(bookID int64) ([]book.ChapterCount, error) {
+	var chapters []book.ChapterCount
+	result := r.db.Model(&models.ChapterModel{}).
+		Select("chapters.*, COUNT(quotes.id) AS quote_count").
+		Joins("LEFT JOIN quotes ON quotes.chapter_id = chapters.id").
+		Where("chapters.book_id = ?", bookID).
+		Group("chapters.id").
+		Order("chapters.id").
+		Scan(&chapters)
+	if result.Error != nil {
+		r.l.Debugf("find chapters error, book id: %d\n The error message: %s", bookID, result.Error.Error())
+		return nil, result.Error
+	}
+	return chapters, nil
+}
+
+func (r *Repository) Update(b models.BookModel) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(&b).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.QuoteModel{}).Where("book_id = ?", b.ID).UpdateColumn("book", b.Title).Error
+	})
+	if err != nil {
+		r.l.Debugf("update book error, book id: %d\n The error message: %s", b.ID, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Merge(source models.BookModel, target models.BookModel) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var chapters []models.ChapterModel
+		err := tx.Where("book_id = ?", source.ID).Find(&chapters).Error
+		if err != nil {
+			return err
+		}
+		for _, c := range chapters {
+			err = r.mergeChapter(tx, c, target.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.QuoteModel{}).Where("book_id = ?", source.ID).UpdateColumns(map[string]interface{}{
+			"book_id": target.ID,
+			"book":    target.Title,
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Save(&target).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.BookModel{}, source.ID).Error
+	})
+	if err != nil {
+		r.l.Debugf("merge book error, book id: %d into %d\n The error message: %s", source.ID, target.ID, err.Error())
+		return err
+	}
+	return nil
+}
+
+// mergeChapter moves the chapter to the book, a chapter with the same title there takes over its quotes.
+func (r *Repository) mergeChapter(tx *gorm.DB, c models.ChapterModel, bookID int64) error {
+	var same []models.ChapterModel
+	err := tx.Where("book_id = ? AND title = ?", bookID, c.Title).Limit(1).Find(&same).Error
+	if err != nil {
+		return err
+	}
+	if len(same) == 0 {
+		return tx.Model(&c).UpdateColumn("book_id", bookID).Error
+	}
+	err = tx.Model(&models.QuoteModel{}).Where("chapter_id = ?", c.ID).UpdateColumn("chapter_id", same[0].ID).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(&models.ChapterModel{}, c.ID).Error
+}
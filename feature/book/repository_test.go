@@ -0,0 +1,137 @@
+package book
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"myquote/service/quotelink"
+	"testing"
+)
+
+type BookRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+func TestBookRepository(t *testing.T) {
+	suite.Run(t, new(BookRepositoryTestSuite))
+}
+
+func (s *BookRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.QuoteModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+}
+
+func (s *BookRepositoryTestSuite) quote(userID int64, book string, chapter string) models.QuoteModel {
+	q := models.QuoteModel{UserID: userID, Text: "Quote", Book: book, Chapter: chapter}
+	s.Require().NoError(quotelink.New(s.db).Book(&q))
+	s.Require().NoError(s.db.Create(&q).Error)
+	return q
+}
+
+func (s *BookRepositoryTestSuite) TestMigrateLinksOldQuotes() {
+	s.Require().NoError(s.db.Exec("INSERT INTO quotes (user_id, text, book, chapter) VALUES (1, 'Quote', 'Book1', 'Chapter 1')").Error)
+	s.Require().NoError(s.repo.Migrate())
+
+	var q models.QuoteModel
+	s.db.First(&q)
+	s.Assert().NotNil(q.BookID)
+	s.Assert().NotNil(q.ChapterID)
+}
+
+func (s *BookRepositoryTestSuite) TestFindAllWithQuoteCount() {
+	s.quote(1, "Book2", "")
+	s.quote(1, "Book1", "Chapter 1")
+	s.quote(1, "Book1", "Chapter 2")
+	s.quote(2, "Book3", "")
+
+	books, err := s.repo.FindAll(1)
+	s.Assert().Nil(err)
+	s.Assert().Len(books, 2)
+	s.Assert().Equal("Book1", books[0].Title)
+	s.Assert().Equal(int64(2), books[0].QuoteCount)
+	s.Assert().Equal(int64(1), books[1].QuoteCount)
+}
+
+func (s *BookRepositoryTestSuite) TestFindNotExists() {
+	find, _, err := s.repo.Find(99)
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *BookRepositoryTestSuite) TestChapters() {
+	q := s.quote(1, "Book1", "Chapter 1")
+	s.quote(1, "Book1", "Chapter 2")
+	s.quote(1, "Book1", "Chapter 1")
+
+	chapters, err := s.repo.Chapters(*q.BookID)
+	s.Assert().Nil(err)
+	s.Assert().Len(chapters, 2)
+	s.Assert().Equal("Chapter 1", chapters[0].Title)
+	s.Assert().Equal(int64(2), chapters[0].QuoteCount)
+	s.Assert().Equal("Chapter 2", chapters[1].Title)
+}
+
+func (s *BookRepositoryTestSuite) TestUpdateRenamesQuotes() {
+	q := s.quote(1, "Book1", "")
+	_, b, _ := s.repo.Find(*q.BookID)
+	b.Title = "Atomic Habits"
+	b.Author = "James Clear"
+
+	err := s.repo.Update(b.BookModel)
+	s.Assert().Nil(err)
+
+	var actual models.QuoteModel
+	s.db.First(&actual, q.ID)
+	s.Assert().Equal("Atomic Habits", actual.Book)
+	_, b, _ = s.repo.Find(*q.BookID)
+	s.Assert().Equal("James Clear", b.Author)
+}
+
+func (s *BookRepositoryTestSuite) TestFindByTitle() {
+	s.quote(1, "Book1", "")
+	find, b, err := s.repo.FindByTitle(1, "Book1")
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal("Book1", b.Title)
+
+	find, _, err = s.repo.FindByTitle(2, "Book1")
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *BookRepositoryTestSuite) TestMergeMovesQuotesAndChapters() {
+	kept := s.quote(1, "Atomic Habits", "Chapter 1")
+	same := s.quote(1, "Atomic habits", "Chapter 1")
+	moved := s.quote(1, "Atomic habits", "Chapter 2")
+	_, source, _ := s.repo.Find(*same.BookID)
+	_, target, _ := s.repo.Find(*kept.BookID)
+
+	err := s.repo.Merge(source.BookModel, target.BookModel)
+	s.Assert().Nil(err)
+
+	find, _, _ := s.repo.Find(source.ID)
+	s.Assert().False(find)
+	_, target, _ = s.repo.Find(target.ID)
+	s.Assert().Equal(int64(3), target.QuoteCount)
+
+	var actual models.QuoteModel
+	s.db.First(&actual, same.ID)
+	s.Assert().Equal("Atomic Habits", actual.Book)
+	s.Assert().Equal(*kept.ChapterID, *actual.ChapterID)
+	s.db.First(&actual, moved.ID)
+	s.Assert().Equal(target.ID, *actual.BookID)
+
+	chapters, _ := s.repo.Chapters(target.ID)
+	s.Assert().Len(chapters, 2)
+	var count int64
+	s.db.Model(&models.ChapterModel{}).Count(&count)
+	s.Assert().Equal(int64(2), count)
+}
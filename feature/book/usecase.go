@@ -0,0 +1,163 @@
+package book
+
+import (
+	"errors"
+	"myquote/domain"
+	"myquote/domain/book"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"strings"
+	"unicode/utf8"
+)
+
+type Usecase struct {
+	l     domain.Logger
+	r     book.Repository
+	isbnv common.Validator
+	urlv  common.Validator
+}
+
+func NewUsecase(logger domain.Logger, repository book.Repository, isbnValidator common.Validator, urlValidator common.Validator) *Usecase {
+	return &Usecase{l: logger, r: repository, isbnv: isbnValidator, urlv: urlValidator}
+}
+
+func (uc *Usecase) FindAll(user models.User) ([]models.Book, error) {
+	found, err := uc.r.FindAll(user.ID)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	books := make([]models.Book, 0, len(found))
+	for _, b := range found {
+		books = append(books, b.ToBook())
+	}
+	return books, nil
+}
+
+func (uc *Usecase) Chapters(user models.User, id int64) ([]models.Chapter, error) {
+	_, err := uc.owned(user, id)
+	if errors.Is(err, exceptions.Forbidden) {
+		// a book of somebody else is reported as missing, so its existence does not leak
+		return nil, exceptions.BookNotExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	found, err := uc.r.Chapters(id)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	chapters := make([]models.Chapter, 0, len(found))
+	for _, c := range found {
+		chapters = append(chapters, models.Chapter{ID: c.ID, BookID: c.BookID, Title: c.Title, QuoteCount: c.QuoteCount})
+	}
+	return chapters, nil
+}
+
+// Update changes the given fields. Renaming to the title of another book is refused, those books
+// should be merged.
+func (uc *Usecase) Update(user models.User, id int64, u book.BookUpdate) (models.Book, error) {
+	b, err := uc.owned(user, id)
+	if err != nil {
+		return models.Book{}, err
+	}
+	m := b.BookModel
+	if u.Title != nil {
+		m.Title = strings.TrimSpace(*u.Title)
+		if m.Title == "" || utf8.RuneCountInString(m.Title) > models.MAX_TITLE_LENGTH {
+			return models.Book{}, exceptions.InvalidBookTitle
+		}
+	}
+	if u.Author != nil {
+		m.Author = strings.TrimSpace(*u.Author)
+	}
+	if u.ISBN != nil {
+		m.ISBN = normalizeISBN(*u.ISBN)
+		if m.ISBN != "" && !uc.isbnv.Validate(m.ISBN) {
+			return models.Book{}, exceptions.InvalidISBN
+		}
+	}
+	if u.CoverURL != nil {
+		m.CoverURL = strings.TrimSpace(*u.CoverURL)
+		if m.CoverURL != "" && !uc.urlv.Validate(m.CoverURL) {
+			return models.Book{}, exceptions.InvalidCoverURL
+		}
+	}
+
+	if m.Title != b.Title {
+		find, _, err := uc.r.FindByTitle(user.ID, m.Title)
+		if err != nil {
+			return models.Book{}, exceptions.ServerError
+		}
+		if find {
+			return models.Book{}, exceptions.BookExists
+		}
+	}
+	err = uc.r.Update(m)
+	if err != nil {
+		return models.Book{}, exceptions.ServerError
+	}
+	return uc.reload(id)
+}
+
+// Merge moves the quotes and chapters of the book into m.Into and deletes the book. Details the
+// remaining book lacks are taken from the merged one.
+func (uc *Usecase) Merge(user models.User, id int64, m book.Merge) (models.Book, error) {
+	if id == m.Into {
+		return models.Book{}, exceptions.CannotMergeSameBook
+	}
+	source, err := uc.owned(user, id)
+	if err != nil {
+		return models.Book{}, err
+	}
+	target, err := uc.owned(user, m.Into)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	merged := target.BookModel
+	if merged.Author == "" {
+		merged.Author = source.Author
+	}
+	if merged.ISBN == "" {
+		merged.ISBN = source.ISBN
+	}
+	if merged.CoverURL == "" {
+		merged.CoverURL = source.CoverURL
+	}
+	err = uc.r.Merge(source.BookModel, merged)
+	if err != nil {
+		return models.Book{}, exceptions.ServerError
+	}
+	uc.l.Infof("user %d merged book %d into %d", user.ID, id, m.Into)
+	return uc.reload(m.Into)
+}
+
+// owned finds the book and makes sure it belongs to user.
+func (uc *Usecase) owned(user models.User, id int64) (book.BookCount, error) {
+	find, b, err := uc.r.Find(id)
+	if err != nil {
+		return book.BookCount{}, exceptions.ServerError
+	}
+	if !find {
+		return book.BookCount{}, exceptions.BookNotExists
+	}
+	if b.UserID != user.ID {
+		uc.l.Warnf("user %d tried to access book %d of user %d", user.ID, id, b.UserID)
+		return book.BookCount{}, exceptions.Forbidden
+	}
+	return b, nil
+}
+
+func (uc *Usecase) reload(id int64) (models.Book, error) {
+	find, b, err := uc.r.Find(id)
+	if err != nil || !find {
+		return models.Book{}, exceptions.ServerError
+	}
+	return b.ToBook(), nil
+}
+
+// normalizeISBN drops the hyphens and spaces ISBNs are often written with.
+func normalizeISBN(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+}
@@ -0,0 +1,159 @@
+package book
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/book"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/service/logger"
+	"myquote/service/validator"
+	"testing"
+)
+
+type MockedBookRepo struct {
+	mock.Mock
+}
+
+func (m *MockedBookRepo) FindAll(userID int64) ([]book.BookCount, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]book.BookCount), args.Error(1)
+}
+
+func (m *MockedBookRepo) Find(id int64) (bool, book.BookCount, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Get(1).(book.BookCount), args.Error(2)
+}
+
+func (m *MockedBookRepo) FindByTitle(userID int64, title string) (bool, models.BookModel, error) {
+	args := m.Called(userID, title)
+	return args.Bool(0), args.Get(1).(models.BookModel), args.Error(2)
+}
+
+func (m *MockedBookRepo) Chapters(bookID int64) ([]book.ChapterCount, error) {
+	args := m.Called(bookID)
+	return args.Get(0).([]book.ChapterCount), args.Error(1)
+}
+
+func (m *MockedBookRepo) Update(b models.BookModel) error {
+	args := m.Called(b)
+	return args.Error(0)
+}
+
+func (m *MockedBookRepo) Merge(source models.BookModel, target models.BookModel) error {
+	args := m.Called(source, target)
+	return args.Error(0)
+}
+
+type BookUsecaseTestSuite struct {
+	suite.Suite
+	uc   *Usecase
+	repo *MockedBookRepo
+	user models.User
+}
+
+func TestBookUsecase(t *testing.T) {
+	suite.Run(t, new(BookUsecaseTestSuite))
+}
+
+func (s *BookUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedBookRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, validator.NewISBNValidator(), validator.NewURLValidator())
+	s.user = models.User{ID: 1}
+}
+
+func counted(id int64, userID int64, title string, count int64) book.BookCount {
+	return book.BookCount{BookModel: models.BookModel{ID: id, UserID: userID, Title: title}, QuoteCount: count}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func (s *BookUsecaseTestSuite) TestFindAll() {
+	s.repo.On("FindAll", int64(1)).Return([]book.BookCount{counted(1, 1, "Book1", 3)}, nil)
+	books, err := s.uc.FindAll(s.user)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]models.Book{{ID: 1, Title: "Book1", QuoteCount: 3}}, books)
+}
+
+func (s *BookUsecaseTestSuite) TestChaptersOfOthersReportsNotExists() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 9, "Book1", 0), nil)
+	_, err := s.uc.Chapters(s.user, 1)
+	s.Assert().Equal(exceptions.BookNotExists, err)
+}
+
+func (s *BookUsecaseTestSuite) TestChapters() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 1, "Book1", 2), nil)
+	s.repo.On("Chapters", int64(1)).Return([]book.ChapterCount{{ChapterModel: models.ChapterModel{ID: 4, BookID: 1, Title: "Chapter 1"}, QuoteCount: 2}}, nil)
+	chapters, err := s.uc.Chapters(s.user, 1)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]models.Chapter{{ID: 4, BookID: 1, Title: "Chapter 1", QuoteCount: 2}}, chapters)
+}
+
+func (s *BookUsecaseTestSuite) TestUpdate() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 1, "Book1", 2), nil).Once()
+	s.repo.On("FindByTitle", int64(1), "Atomic Habits").Return(false, models.BookModel{}, nil)
+	s.repo.On("Update", models.BookModel{ID: 1, UserID: 1, Title: "Atomic Habits", ISBN: "9780735211292", CoverURL: "https://example.com/cover.jpg"}).Return(nil)
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 1, "Atomic Habits", 2), nil)
+
+	b, err := s.uc.Update(s.user, 1, book.BookUpdate{
+		Title:    strPtr(" Atomic Habits "),
+		ISBN:     strPtr("978-0-7352-1129-2"),
+		CoverURL: strPtr("https://example.com/cover.jpg"),
+	})
+	s.Assert().Nil(err)
+	s.Assert().Equal("Atomic Habits", b.Title)
+}
+
+func (s *BookUsecaseTestSuite) TestUpdateToExistingTitle() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 1, "Book1", 2), nil)
+	s.repo.On("FindByTitle", int64(1), "Book2").Return(true, models.BookModel{ID: 2, UserID: 1, Title: "Book2"}, nil)
+	_, err := s.uc.Update(s.user, 1, book.BookUpdate{Title: strPtr("Book2")})
+	s.Assert().Equal(exceptions.BookExists, err)
+	s.repo.AssertNotCalled(s.T(), "Update", mock.Anything)
+}
+
+func (s *BookUsecaseTestSuite) TestUpdateInvalid() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 1, "Book1", 2), nil)
+	_, err := s.uc.Update(s.user, 1, book.BookUpdate{Title: strPtr(" ")})
+	s.Assert().Equal(exceptions.InvalidBookTitle, err)
+	_, err = s.uc.Update(s.user, 1, book.BookUpdate{ISBN: strPtr("978-0-7352-1129-3")})
+	s.Assert().Equal(exceptions.InvalidISBN, err)
+	_, err = s.uc.Update(s.user, 1, book.BookUpdate{CoverURL: strPtr("javascript:alert(1)")})
+	s.Assert().Equal(exceptions.InvalidCoverURL, err)
+}
+
+func (s *BookUsecaseTestSuite) TestUpdateOthersForbidden() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 9, "Book1", 2), nil)
+	_, err := s.uc.Update(s.user, 1, book.BookUpdate{Title: strPtr("Book2")})
+	s.Assert().Equal(exceptions.Forbidden, err)
+}
+
+func (s *BookUsecaseTestSuite) TestMergeFillsMissingDetails() {
+	source := counted(1, 1, "Atomic habits", 1)
+	source.Author = "James Clear"
+	source.ISBN = "9780735211292"
+	target := counted(2, 1, "Atomic Habits", 2)
+	target.ISBN = "0735211299"
+	s.repo.On("Find", int64(1)).Return(true, source, nil)
+	s.repo.On("Find", int64(2)).Return(true, target, nil)
+	s.repo.On("Merge", source.BookModel, models.BookModel{ID: 2, UserID: 1, Title: "Atomic Habits", Author: "James Clear", ISBN: "0735211299"}).Return(nil)
+
+	_, err := s.uc.Merge(s.user, 1, book.Merge{Into: 2})
+	s.Assert().Nil(err)
+	s.repo.AssertNumberOfCalls(s.T(), "Merge", 1)
+}
+
+func (s *BookUsecaseTestSuite) TestMergeIntoItself() {
+	_, err := s.uc.Merge(s.user, 1, book.Merge{Into: 1})
+	s.Assert().Equal(exceptions.CannotMergeSameBook, err)
+}
+
+func (s *BookUsecaseTestSuite) TestMergeIntoOthersBookForbidden() {
+	s.repo.On("Find", int64(1)).Return(true, counted(1, 1, "Book1", 1), nil)
+	s.repo.On("Find", int64(2)).Return(true, counted(2, 9, "Book1", 1), nil)
+	_, err := s.uc.Merge(s.user, 1, book.Merge{Into: 2})
+	s.Assert().Equal(exceptions.Forbidden, err)
+	s.repo.AssertNotCalled(s.T(), "Merge", mock.Anything, mock.Anything)
+}
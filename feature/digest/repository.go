@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/models"
+	"time"
+)
+
+const UTC_BATCH_SIZE = 100
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the digest tables and moves the send times saved in the time zone
+// of their user to UTC. It needs the users table.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.DigestModel{}, &models.DigestQuoteModel{})
+	if err != nil {
+		r.l.Errorf("migrate digest tables error: %s", err.Error())
+		return err
+	}
+
+	var users []models.UserModel
+	result := r.db.Where("next_digest_at IS NOT NULL").FindInBatches(&users, UTC_BATCH_SIZE, func(tx *gorm.DB, batch int) error {
+		for _, u := range users {
+			if _, offset := u.NextDigestAt.Zone(); offset == 0 {
+				continue
+			}
+			err := tx.Model(&u).UpdateColumn("next_digest_at", u.NextDigestAt.UTC()).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		r.l.Errorf("move digest send times to utc error: %s", result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
+
+// FindDueUsers compares in UTC, the times are saved in UTC and some databases compare them as text.
+func (r *Repository) FindDueUsers(now time.Time) ([]models.UserModel, error) {
+	var users []models.UserModel
+	result := r.db.Where("next_digest_at IS NULL OR next_digest_at <= ?", now.UTC()).Order("id").Find(&users)
+	if result.Error != nil {
+		r.l.Debugf("find due users error.\n The error message: %s", result.Error.Error())
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+func (r *Repository) Claim(userID int64, prev *time.Time, next time.Time) (bool, error) {
+	query := r.db.Model(&models.UserModel{}).Where("id = ?", userID)
+	if prev == nil {
+		query = query.Where("next_digest_at IS NULL")
+	} else {
+		query = query.Where("next_digest_at = ?", prev.UTC())
+	}
+	result := query.UpdateColumn("next_digest_at", next.UTC())
+	if result.Error != nil {
+		r.l.Debugf("claim digest error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CreateDigest saves the digest together with its quotes.
+func (r *Repository) CreateDigest(digest models.DigestModel) error {
+	result := r.db.Create(&digest)
+	if result.Error != nil {
+		r.l.Debugf("create digest error, user id: %d\n The error message: %s", digest.UserID, result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
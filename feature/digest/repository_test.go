@@ -0,0 +1,144 @@
+package digest
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type DigestRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+func TestDigestRepository(t *testing.T) {
+	suite.Run(t, new(DigestRepositoryTestSuite))
+}
+
+func (s *DigestRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.UserModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+}
+
+func (s *DigestRepositoryTestSuite) TestFindDueUsers() {
+	now := time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	s.db.Create(&models.UserModel{Name: "due", Email: "1@gmail.com", NextDigestAt: &past})
+	s.db.Create(&models.UserModel{Name: "later", Email: "2@gmail.com", NextDigestAt: &future})
+	s.db.Create(&models.UserModel{Name: "new", Email: "3@gmail.com"})
+
+	users, err := s.repo.FindDueUsers(now)
+	s.Assert().Nil(err)
+	s.Require().Len(users, 2)
+	s.Assert().Equal("due", users[0].Name)
+	s.Assert().Equal("new", users[1].Name)
+	s.Assert().Equal(1, users[1].MailsPerWeek)
+	s.Assert().Equal(5, users[1].QuotesPerMail)
+}
+
+func (s *DigestRepositoryTestSuite) TestFindDueUsersInOtherTimeZone() {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.Require().NoError(err)
+	due := time.Date(2022, 5, 2, 8, 0, 0, 0, taipei)
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+	claimed, err := s.repo.Claim(u.ID, nil, due)
+	s.Require().NoError(err)
+	s.Require().True(claimed)
+
+	users, err := s.repo.FindDueUsers(time.Date(2022, 5, 1, 23, 59, 0, 0, time.UTC))
+	s.Assert().Nil(err)
+	s.Assert().Empty(users)
+	users, err = s.repo.FindDueUsers(time.Date(2022, 5, 2, 1, 0, 0, 0, time.UTC))
+	s.Assert().Nil(err)
+	s.Assert().Len(users, 1)
+
+	// the scheduler claims again with the time it read and with times in the zone of the user
+	claimed, err = s.repo.Claim(u.ID, &due, due.AddDate(0, 0, 7))
+	s.Assert().Nil(err)
+	s.Assert().True(claimed)
+}
+
+func (s *DigestRepositoryTestSuite) TestMigrateMovesSendTimesToUTC() {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.Require().NoError(err)
+	due := time.Date(2022, 5, 2, 8, 0, 0, 0, taipei)
+	s.db.Create(&models.UserModel{Name: "Lester", Email: "123@gmail.com", NextDigestAt: &due})
+
+	s.Require().NoError(s.repo.Migrate())
+	users, err := s.repo.FindDueUsers(time.Date(2022, 5, 2, 1, 0, 0, 0, time.UTC))
+	s.Assert().Nil(err)
+	s.Require().Len(users, 1)
+	s.Assert().True(due.Equal(*users[0].NextDigestAt))
+}
+
+func (s *DigestRepositoryTestSuite) TestClaimOnlyOnce() {
+	due := time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	next := due.AddDate(0, 0, 7)
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com", NextDigestAt: &due}
+	s.db.Create(&u)
+
+	claimed, err := s.repo.Claim(u.ID, &due, next)
+	s.Assert().Nil(err)
+	s.Assert().True(claimed)
+
+	// a second instance read the same due time
+	claimed, err = s.repo.Claim(u.ID, &due, next)
+	s.Assert().Nil(err)
+	s.Assert().False(claimed)
+
+	var actual models.UserModel
+	s.db.First(&actual, u.ID)
+	s.Assert().True(next.Equal(*actual.NextDigestAt))
+}
+
+func (s *DigestRepositoryTestSuite) TestClaimAgainForRetry() {
+	due := time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	next := time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC)
+	retry := time.Now().Add(15 * time.Minute)
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com", NextDigestAt: &due}
+	s.db.Create(&u)
+
+	claimed, _ := s.repo.Claim(u.ID, &due, next)
+	s.Require().True(claimed)
+	claimed, err := s.repo.Claim(u.ID, &next, retry)
+	s.Assert().Nil(err)
+	s.Assert().True(claimed)
+
+	var actual models.UserModel
+	s.db.First(&actual, u.ID)
+	s.Assert().True(retry.Equal(*actual.NextDigestAt))
+}
+
+func (s *DigestRepositoryTestSuite) TestClaimUnscheduledUser() {
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+	next := time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC)
+
+	claimed, err := s.repo.Claim(u.ID, nil, next)
+	s.Assert().Nil(err)
+	s.Assert().True(claimed)
+	claimed, _ = s.repo.Claim(u.ID, nil, next)
+	s.Assert().False(claimed)
+}
+
+func (s *DigestRepositoryTestSuite) TestCreateDigestWithQuotes() {
+	err := s.repo.CreateDigest(models.DigestModel{UserID: 1, SentAt: time.Now(), Quotes: []models.DigestQuoteModel{
+		{QuoteID: 1}, {QuoteID: 2},
+	}})
+	s.Require().Nil(err)
+
+	var digest models.DigestModel
+	s.db.Preload("Quotes").First(&digest)
+	s.Assert().Equal(int64(1), digest.UserID)
+	s.Assert().Len(digest.Quotes, 2)
+}
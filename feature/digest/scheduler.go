@@ -0,0 +1,41 @@
+package digest
+
+import (
+	"context"
+	"myquote/domain"
+	"myquote/domain/digest"
+	"time"
+)
+
+// Scheduler checks for due review mails every interval. The send times live in the database,
+// so a restart picks up where it stopped.
+type Scheduler struct {
+	l        domain.Logger
+	uc       digest.Usecase
+	interval time.Duration
+}
+
+func NewScheduler(logger domain.Logger, usecase digest.Usecase, interval time.Duration) *Scheduler {
+	return &Scheduler{l: logger, uc: usecase, interval: interval}
+}
+
+// Run blocks until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.tick()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick() {
+	err := s.uc.SendDue()
+	if err != nil {
+		s.l.Errorf("send due digests error: %s", err.Error())
+	}
+}
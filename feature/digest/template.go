@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"myquote/domain/models"
+	"strings"
+	texttemplate "text/template"
+)
+
+const SUBJECT = "My Quote: quotes to review this week"
+
+const textTemplate = `Hi {{.Name}},
+
+Here are {{len .Quotes}} quotes to look back on.
+{{range .Quotes}}
+{{indent .Text}}
+{{- with attribution .}}
+  -- {{.}}{{end}}
+{{end}}
+My Quote
+`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: Georgia, serif; max-width: 600px; margin: 0 auto;">
+<p>Hi {{.Name}},</p>
+<p>Here are {{len .Quotes}} quotes to look back on.</p>
+{{range .Quotes}}<blockquote style="border-left: 3px solid #ccc; margin: 16px 0; padding-left: 12px;">
+<p style="white-space: pre-line;">{{.Text}}</p>
+{{with attribution .}}<footer style="color: #666;">{{.}}</footer>
+{{end}}</blockquote>
+{{end}}<p>My Quote</p>
+</body>
+</html>
+`
+
+var funcs = map[string]interface{}{
+	"attribution": attribution,
+	"indent": func(s string) string {
+		return "  " + strings.ReplaceAll(s, "\n", "\n  ")
+	},
+}
+
+var (
+	text = texttemplate.Must(texttemplate.New("text").Funcs(funcs).Parse(textTemplate))
+	html = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(htmlTemplate))
+)
+
+type content struct {
+	Name   string
+	Quotes []models.Quote
+}
+
+// render returns the plain text and the html body of the digest.
+func render(user models.User, quotes []models.Quote) (string, string, error) {
+	data := content{Name: user.Name, Quotes: quotes}
+	if data.Name == "" {
+		data.Name = user.Email
+	}
+
+	var t, h bytes.Buffer
+	err := text.Execute(&t, data)
+	if err != nil {
+		return "", "", err
+	}
+	err = html.Execute(&h, data)
+	if err != nil {
+		return "", "", err
+	}
+	return t.String(), h.String(), nil
+}
+
+// attribution is "Book, Chapter" leaving out the empty parts.
+func attribution(q models.Quote) string {
+	var parts []string
+	if q.Book != "" {
+		parts = append(parts, q.Book)
+	}
+	if q.Chapter != "" {
+		parts = append(parts, q.Chapter)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,143 @@
+package digest
+
+import (
+	"myquote/domain"
+	"myquote/domain/digest"
+	"myquote/domain/exceptions"
+	"myquote/domain/mail"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/schedule"
+	"time"
+)
+
+// RETRY_AFTER is how long a failed review mail waits before it is sent again.
+const RETRY_AFTER = 15 * time.Minute
+
+type Usecase struct {
+	l      domain.Logger
+	r      digest.Repository
+	picker digest.QuotePicker
+	mailer mail.Mailer
+	now    func() time.Time
+}
+
+func NewUsecase(logger domain.Logger, repository digest.Repository, picker digest.QuotePicker, mailer mail.Mailer) *Usecase {
+	return &Usecase{l: logger, r: repository, picker: picker, mailer: mailer, now: time.Now}
+}
+
+// Send mails n quotes to the user, only once the mail is sent they are recorded as delivered.
+// A user without quotes gets no mail.
+func (uc *Usecase) Send(user models.User, n int) error {
+	quotes, err := uc.pick(user, n)
+	if err != nil {
+		return err
+	}
+	if len(quotes) == 0 {
+		uc.l.Debugf("user %d has no quote to review", user.ID)
+		return nil
+	}
+
+	text, html, err := render(user, quotes)
+	if err != nil {
+		uc.l.Errorf("render digest error, user id: %d\n The error message: %s", user.ID, err.Error())
+		return exceptions.ServerError
+	}
+	err = uc.mailer.Send(mail.Message{To: user.Email, Subject: SUBJECT, Text: text, HTML: html})
+	if err != nil {
+		uc.l.Errorf("send digest error, user id: %d\n The error message: %s", user.ID, err.Error())
+		return exceptions.MailError
+	}
+
+	err = uc.picker.Deliver(user, quotes, quote.CHANNEL_DIGEST)
+	if err != nil {
+		return err
+	}
+	record := models.DigestModel{UserID: user.ID, SentAt: uc.now()}
+	for _, q := range quotes {
+		record.Quotes = append(record.Quotes, models.DigestQuoteModel{QuoteID: q.ID})
+	}
+	err = uc.r.CreateDigest(record)
+	if err != nil {
+		return exceptions.ServerError
+	}
+	return nil
+}
+
+// pick draws n random quotes, or takes them from the due queue when the user reviews with spaced
+// repetition. Random quotes fill up a short queue, so the mail does not shrink when little is due.
+func (uc *Usecase) pick(user models.User, n int) ([]models.Quote, error) {
+	if quote.ReviewMode(user.ReviewMode) != quote.REVIEW_SPACED {
+		return uc.picker.Draw(user, n, quote.RandomOptions{})
+	}
+	quotes, err := uc.picker.Due(user, n)
+	if err != nil || len(quotes) == n {
+		return quotes, err
+	}
+	random, err := uc.picker.Draw(user, n, quote.RandomOptions{})
+	if err != nil {
+		return nil, err
+	}
+	picked := map[int64]bool{}
+	for _, q := range quotes {
+		picked[q.ID] = true
+	}
+	for _, q := range random {
+		if len(quotes) == n {
+			break
+		}
+		if !picked[q.ID] {
+			picked[q.ID] = true
+			quotes = append(quotes, q)
+		}
+	}
+	return quotes, nil
+}
+
+// SendDue mails every user whose review mail is due and schedules the next one.
+// A user is claimed before the mail goes out, so several instances never send the same mail twice.
+// A failed mail is retried after RETRY_AFTER. A failure for one user does not stop the others,
+// the last error is returned.
+func (uc *Usecase) SendDue() error {
+	now := uc.now()
+	users, err := uc.r.FindDueUsers(now)
+	if err != nil {
+		return exceptions.ServerError
+	}
+	var last error
+	for _, u := range users {
+		next := schedule.Next(u.MailsPerWeek, u.TimeZone, now)
+		claimed, err := uc.r.Claim(u.ID, u.NextDigestAt, next)
+		if err != nil {
+			last = exceptions.ServerError
+			continue
+		}
+		if !claimed {
+			uc.l.Debugf("digest of user %d is claimed by another instance", u.ID)
+			continue
+		}
+		if u.NextDigestAt == nil {
+			// first time the user is seen, only schedule
+			continue
+		}
+		err = uc.Send(u.ToUser(), u.QuotesPerMail)
+		if err != nil {
+			last = err
+			uc.retry(u.ID, next, now)
+		}
+	}
+	return last
+}
+
+// retry moves the claimed send time of the user from next to RETRY_AFTER from now, unless
+// the next mail is due before that anyway.
+func (uc *Usecase) retry(userID int64, next time.Time, now time.Time) {
+	retry := now.UTC().Add(RETRY_AFTER)
+	if !retry.Before(next) {
+		return
+	}
+	_, err := uc.r.Claim(userID, &next, retry)
+	if err != nil {
+		uc.l.Errorf("schedule digest retry error, user id: %d\n The error message: %s", userID, err.Error())
+	}
+}
@@ -0,0 +1,261 @@
+package digest
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"mime"
+	"mime/multipart"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/logger"
+	"myquote/service/mailer"
+	"myquote/service/mailer/smtptest"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MockedDigestRepo struct {
+	mock.Mock
+}
+
+func (m *MockedDigestRepo) FindDueUsers(now time.Time) ([]models.UserModel, error) {
+	args := m.Called(now)
+	return args.Get(0).([]models.UserModel), args.Error(1)
+}
+
+func (m *MockedDigestRepo) Claim(userID int64, prev *time.Time, next time.Time) (bool, error) {
+	args := m.Called(userID, prev, next)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockedDigestRepo) CreateDigest(digest models.DigestModel) error {
+	args := m.Called(digest)
+	return args.Error(0)
+}
+
+type MockedQuotePicker struct {
+	mock.Mock
+}
+
+func (m *MockedQuotePicker) Draw(user models.User, n int, options quote.RandomOptions) ([]models.Quote, error) {
+	args := m.Called(user, n, options)
+	return args.Get(0).([]models.Quote), args.Error(1)
+}
+
+func (m *MockedQuotePicker) Due(user models.User, n int) ([]models.Quote, error) {
+	args := m.Called(user, n)
+	return args.Get(0).([]models.Quote), args.Error(1)
+}
+
+func (m *MockedQuotePicker) Deliver(user models.User, quotes []models.Quote, channel quote.Channel) error {
+	args := m.Called(user, quotes, channel)
+	return args.Error(0)
+}
+
+type DigestUsecaseTestSuite struct {
+	suite.Suite
+	uc     *Usecase
+	repo   *MockedDigestRepo
+	picker *MockedQuotePicker
+	server *smtptest.Server
+	user   models.User
+	now    time.Time
+}
+
+func TestNewDigestUsecase(t *testing.T) {
+	suite.Run(t, new(DigestUsecaseTestSuite))
+}
+
+func (s *DigestUsecaseTestSuite) SetupTest() {
+	server, err := smtptest.NewServer()
+	s.Require().NoError(err)
+	s.server = server
+	s.repo = new(MockedDigestRepo)
+	s.picker = new(MockedQuotePicker)
+	m := mailer.NewSMTPMailer(server.Host(), server.Port(), "", "", "myquote@example.com")
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, s.picker, m)
+	s.now = time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	s.uc.now = func() time.Time { return s.now }
+	s.user = models.User{ID: 1, Name: "Lester", Email: "123@gmail.com", TimeZone: "UTC"}
+}
+
+func (s *DigestUsecaseTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+// bodies returns the plain text and the html part of a sent mail.
+func (s *DigestUsecaseTestSuite) bodies(data string) (string, string) {
+	msg, err := netmail.ReadMessage(strings.NewReader(data))
+	s.Require().Nil(err)
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	s.Require().Nil(err)
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		s.Require().Nil(err)
+		b, _ := io.ReadAll(part)
+		parts = append(parts, string(b))
+	}
+	s.Require().Len(parts, 2)
+	return parts[0], parts[1]
+}
+
+func (s *DigestUsecaseTestSuite) TestSendDigest() {
+	quotes := []models.Quote{
+		{ID: 1, Text: "Quote 1", Book: "Book1", Chapter: "Chapter 1"},
+		{ID: 2, Text: "Quote <2>", Book: "Book2"},
+		{ID: 3, Text: "Quote 3"},
+	}
+	s.picker.On("Draw", s.user, 5, quote.RandomOptions{}).Return(quotes, nil)
+	s.picker.On("Deliver", s.user, quotes, quote.CHANNEL_DIGEST).Return(nil)
+	s.repo.On("CreateDigest", models.DigestModel{UserID: 1, SentAt: s.now, Quotes: []models.DigestQuoteModel{
+		{QuoteID: 1}, {QuoteID: 2}, {QuoteID: 3},
+	}}).Return(nil)
+
+	err := s.uc.Send(s.user, 5)
+	s.Require().Nil(err)
+
+	messages := s.server.Messages()
+	s.Require().Len(messages, 1)
+	s.Assert().Equal([]string{"123@gmail.com"}, messages[0].To)
+	text, html := s.bodies(messages[0].Data)
+	s.Assert().Contains(text, "Hi Lester,")
+	s.Assert().Contains(text, "  Quote 1\n  -- Book1, Chapter 1\n")
+	s.Assert().Contains(text, "  Quote <2>\n  -- Book2\n")
+	s.Assert().Contains(text, "  Quote 3\n\n")
+	s.Assert().Contains(html, "Quote &lt;2&gt;")
+	s.Assert().Contains(html, "<footer style=\"color: #666;\">Book1, Chapter 1</footer>")
+}
+
+func (s *DigestUsecaseTestSuite) TestSkipUserWithoutQuotes() {
+	s.picker.On("Draw", s.user, 5, quote.RandomOptions{}).Return([]models.Quote{}, nil)
+
+	err := s.uc.Send(s.user, 5)
+	s.Assert().Nil(err)
+	s.Assert().Empty(s.server.Messages())
+	s.repo.AssertNotCalled(s.T(), "CreateDigest", mock.Anything)
+}
+
+func (s *DigestUsecaseTestSuite) TestThrowMailErrorWhenSendFailure() {
+	s.server.Close()
+	s.picker.On("Draw", s.user, 5, quote.RandomOptions{}).Return([]models.Quote{{ID: 1, Text: "Quote 1"}}, nil)
+
+	err := s.uc.Send(s.user, 5)
+	s.Assert().Equal(exceptions.MailError, err)
+	s.picker.AssertNotCalled(s.T(), "Deliver", mock.Anything, mock.Anything, mock.Anything)
+	s.repo.AssertNotCalled(s.T(), "CreateDigest", mock.Anything)
+}
+
+func (s *DigestUsecaseTestSuite) TestSendDue() {
+	// Monday 08:00, the mail is due and the next one is on Thursday
+	due := s.now
+	next := time.Date(2022, 5, 5, 8, 0, 0, 0, time.UTC)
+	s.repo.On("FindDueUsers", s.now).Return([]models.UserModel{
+		{ID: 1, Name: "Lester", Email: "123@gmail.com", MailsPerWeek: 2, QuotesPerMail: 3, TimeZone: "UTC", NextDigestAt: &due},
+	}, nil)
+	s.repo.On("Claim", int64(1), &due, next).Return(true, nil)
+	s.picker.On("Draw", s.user, 3, quote.RandomOptions{}).Return([]models.Quote{{ID: 1, Text: "Quote 1"}}, nil)
+	s.picker.On("Deliver", s.user, mock.Anything, quote.CHANNEL_DIGEST).Return(nil)
+	s.repo.On("CreateDigest", mock.Anything).Return(nil)
+
+	err := s.uc.SendDue()
+	s.Assert().Nil(err)
+	s.Assert().Len(s.server.Messages(), 1)
+}
+
+func (s *DigestUsecaseTestSuite) TestSendDueSkipsUserClaimedByAnotherInstance() {
+	due := s.now
+	s.repo.On("FindDueUsers", s.now).Return([]models.UserModel{
+		{ID: 1, Email: "123@gmail.com", MailsPerWeek: 1, QuotesPerMail: 3, TimeZone: "UTC", NextDigestAt: &due},
+	}, nil)
+	s.repo.On("Claim", int64(1), &due, mock.Anything).Return(false, nil)
+
+	err := s.uc.SendDue()
+	s.Assert().Nil(err)
+	s.Assert().Empty(s.server.Messages())
+	s.picker.AssertNotCalled(s.T(), "Draw", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *DigestUsecaseTestSuite) TestSendDueOnlySchedulesNewUser() {
+	var unscheduled *time.Time
+	s.repo.On("FindDueUsers", s.now).Return([]models.UserModel{
+		{ID: 1, Email: "123@gmail.com", MailsPerWeek: 1, QuotesPerMail: 3, TimeZone: "UTC"},
+	}, nil)
+	s.repo.On("Claim", int64(1), unscheduled, time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC)).Return(true, nil)
+
+	err := s.uc.SendDue()
+	s.Assert().Nil(err)
+	s.Assert().Empty(s.server.Messages())
+}
+
+func (s *DigestUsecaseTestSuite) TestSendDueContinuesAfterFailure() {
+	due := s.now
+	other := models.User{ID: 2, Name: "Other", Email: "456@gmail.com", TimeZone: "UTC"}
+	s.repo.On("FindDueUsers", s.now).Return([]models.UserModel{
+		{ID: 1, Name: "Lester", Email: "123@gmail.com", MailsPerWeek: 1, QuotesPerMail: 3, TimeZone: "UTC", NextDigestAt: &due},
+		{ID: 2, Name: "Other", Email: "456@gmail.com", MailsPerWeek: 1, QuotesPerMail: 4, TimeZone: "UTC", NextDigestAt: &due},
+	}, nil)
+	s.repo.On("Claim", mock.Anything, &due, mock.Anything).Return(true, nil)
+	s.repo.On("Claim", int64(1), mock.Anything, s.now.Add(RETRY_AFTER)).Return(true, nil)
+	s.picker.On("Draw", s.user, 3, quote.RandomOptions{}).Return([]models.Quote{}, exceptions.ServerError)
+	s.picker.On("Draw", other, 4, quote.RandomOptions{}).Return([]models.Quote{{ID: 5, Text: "Quote 5"}}, nil)
+	s.picker.On("Deliver", other, mock.Anything, quote.CHANNEL_DIGEST).Return(nil)
+	s.repo.On("CreateDigest", mock.Anything).Return(nil)
+
+	err := s.uc.SendDue()
+	s.Assert().Equal(exceptions.ServerError, err)
+	messages := s.server.Messages()
+	s.Require().Len(messages, 1)
+	s.Assert().Equal([]string{"456@gmail.com"}, messages[0].To)
+}
+
+func (s *DigestUsecaseTestSuite) TestSendFromDueQueue() {
+	s.user.ReviewMode = "spaced"
+	s.picker.On("Due", s.user, 3).Return([]models.Quote{{ID: 2, Text: "Quote 2"}}, nil)
+	s.picker.On("Draw", s.user, 3, quote.RandomOptions{}).Return([]models.Quote{{ID: 2, Text: "Quote 2"}, {ID: 1, Text: "Quote 1"}, {ID: 3, Text: "Quote 3"}}, nil)
+	// the duplicate draw of Quote 2 is not delivered twice
+	s.picker.On("Deliver", s.user, []models.Quote{{ID: 2, Text: "Quote 2"}, {ID: 1, Text: "Quote 1"}, {ID: 3, Text: "Quote 3"}}, quote.CHANNEL_DIGEST).Return(nil)
+	s.repo.On("CreateDigest", models.DigestModel{UserID: 1, SentAt: s.now, Quotes: []models.DigestQuoteModel{
+		{QuoteID: 2}, {QuoteID: 1}, {QuoteID: 3},
+	}}).Return(nil)
+
+	err := s.uc.Send(s.user, 3)
+	s.Assert().Nil(err)
+	s.Assert().Len(s.server.Messages(), 1)
+}
+
+func (s *DigestUsecaseTestSuite) TestSendFullDueQueueDrawsNothingRandom() {
+	s.user.ReviewMode = "spaced"
+	s.picker.On("Due", s.user, 2).Return([]models.Quote{{ID: 2, Text: "Quote 2"}, {ID: 1, Text: "Quote 1"}}, nil)
+	s.picker.On("Deliver", s.user, mock.Anything, quote.CHANNEL_DIGEST).Return(nil)
+	s.repo.On("CreateDigest", mock.Anything).Return(nil)
+
+	err := s.uc.Send(s.user, 2)
+	s.Assert().Nil(err)
+	s.picker.AssertNotCalled(s.T(), "Draw", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *DigestUsecaseTestSuite) TestSendDueRetriesFailedMail() {
+	s.server.Close()
+	due := s.now
+	next := time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC)
+	s.repo.On("FindDueUsers", s.now).Return([]models.UserModel{
+		{ID: 1, Name: "Lester", Email: "123@gmail.com", MailsPerWeek: 1, QuotesPerMail: 3, TimeZone: "UTC", NextDigestAt: &due},
+	}, nil)
+	s.repo.On("Claim", int64(1), &due, next).Return(true, nil)
+	s.repo.On("Claim", int64(1), &next, s.now.Add(RETRY_AFTER)).Return(true, nil)
+	s.picker.On("Draw", s.user, 3, quote.RandomOptions{}).Return([]models.Quote{{ID: 1, Text: "Quote 1"}}, nil)
+
+	err := s.uc.SendDue()
+	s.Assert().Equal(exceptions.MailError, err)
+	s.repo.AssertCalled(s.T(), "Claim", int64(1), &next, s.now.Add(RETRY_AFTER))
+	s.picker.AssertNotCalled(s.T(), "Deliver", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/exporter"
+	"net/http"
+)
+
+type handler struct {
+	logger     domain.Logger
+	exporterUc exporter.Usecase
+}
+
+const EXPORT_ENDPOINT = "/api/export"
+
+var contentTypes = map[exporter.Format]string{
+	exporter.MARKDOWN: "text/markdown; charset=utf-8",
+	exporter.JSON:     "application/json; charset=utf-8",
+	exporter.CSV:      "text/csv; charset=utf-8",
+}
+
+// NewExporterHTTPHandler registers the export route.
+func NewExporterHTTPHandler(c *gin.Engine, l domain.Logger, uc exporter.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, exporterUc: uc}
+	c.Group(EXPORT_ENDPOINT, middlewares...).GET("", handler.export)
+}
+
+// export downloads the quotes of the user, like GET /api/export?format=csv. Markdown is the default.
+func (h *handler) export(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	format := exporter.Format(c.DefaultQuery("format", string(exporter.MARKDOWN)))
+	contentType, ok := contentTypes[format]
+	if !ok {
+		c.Error(exceptions.InvalidExportFormat)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="quotes.%s"`, format))
+	c.Status(http.StatusOK)
+	err := h.exporterUc.Export(user, format, c.Writer)
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Header("Content-Type", "")
+		c.Header("Content-Disposition", "")
+		c.Error(err)
+		return
+	}
+	// the download already started, the client sees a truncated file
+	h.logger.Errorf("export stopped, user id: %d\n The error message: %s", user.ID, err.Error())
+}
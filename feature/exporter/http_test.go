@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/exporter"
+	"myquote/domain/models"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedExporterUsecase struct {
+	mock.Mock
+}
+
+// Export writes the mocked output before it returns the mocked error.
+func (m *MockedExporterUsecase) Export(user models.User, format exporter.Format, w io.Writer) error {
+	args := m.Called(user, format, w)
+	if out := args.String(0); out != "" {
+		io.WriteString(w, out)
+	}
+	return args.Error(1)
+}
+
+type ExporterTestSuite struct {
+	suite.Suite
+	uc   *MockedExporterUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestExporterHTTPHandler(t *testing.T) {
+	suite.Run(t, new(ExporterTestSuite))
+}
+
+func (s *ExporterTestSuite) SetupTest() {
+	s.uc = new(MockedExporterUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *ExporterTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *ExporterTestSuite) TestExportMarkdownByDefault() {
+	s.uc.On("Export", s.user, exporter.MARKDOWN, mock.Anything).Return("## Book1\n- Quote 1\n", nil)
+	NewExporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, EXPORT_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal("text/markdown; charset=utf-8", s.r.Header().Get("Content-Type"))
+	s.Assert().Equal(`attachment; filename="quotes.md"`, s.r.Header().Get("Content-Disposition"))
+	s.Assert().Equal("## Book1\n- Quote 1\n", s.r.Body.String())
+}
+
+func (s *ExporterTestSuite) TestExportCSV() {
+	s.uc.On("Export", s.user, exporter.CSV, mock.Anything).Return("id,text\n", nil)
+	NewExporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, EXPORT_ENDPOINT+"?format=csv", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal("text/csv; charset=utf-8", s.r.Header().Get("Content-Type"))
+	s.Assert().Equal(`attachment; filename="quotes.csv"`, s.r.Header().Get("Content-Disposition"))
+}
+
+func (s *ExporterTestSuite) TestExportInvalidFormat() {
+	NewExporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, EXPORT_ENDPOINT+"?format=pdf", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Contains(s.r.Body.String(), exceptions.InvalidExportFormat.Error())
+	s.uc.AssertNotCalled(s.T(), "Export", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ExporterTestSuite) TestExportFailsBeforeOutput() {
+	s.uc.On("Export", s.user, exporter.JSON, mock.Anything).Return("", exceptions.ServerError)
+	NewExporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, EXPORT_ENDPOINT+"?format=json", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+	s.Assert().Equal("application/json; charset=utf-8", s.r.Header().Get("Content-Type"))
+	s.Assert().Empty(s.r.Header().Get("Content-Disposition"))
+}
+
+func (s *ExporterTestSuite) TestExportUnauthorized() {
+	NewExporterHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, EXPORT_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+}
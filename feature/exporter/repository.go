@@ -0,0 +1,45 @@
+package exporter
+
+import (
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/models"
+)
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Each reads the quotes row by row, so a large library is never held in memory. The empty book
+// and chapter sort first on every database.
+func (r *Repository) Each(userID int64, fn func(q models.QuoteModel) error) error {
+	rows, err := r.db.Model(&models.QuoteModel{}).Where("user_id = ?", userID).Order("book, chapter, id").Rows()
+	if err != nil {
+		r.l.Debugf("read quotes error, user id: %d\n The error message: %s", userID, err.Error())
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var q models.QuoteModel
+		err = r.db.ScanRows(rows, &q)
+		if err != nil {
+			r.l.Debugf("scan quote error, user id: %d\n The error message: %s", userID, err.Error())
+			return err
+		}
+		err = fn(q)
+		if err != nil {
+			return err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		r.l.Debugf("read quotes error, user id: %d\n The error message: %s", userID, err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package exporter
+
+import (
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+)
+
+type ExporterRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+func TestExporterRepository(t *testing.T) {
+	suite.Run(t, new(ExporterRepositoryTestSuite))
+}
+
+func (s *ExporterRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.QuoteModel{}, &models.BookModel{}, &models.ChapterModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+}
+
+func (s *ExporterRepositoryTestSuite) create(userID int64, book string, chapter string, text string) {
+	s.Require().NoError(s.db.Create(&models.QuoteModel{UserID: userID, Book: book, Chapter: chapter, Text: text}).Error)
+}
+
+func (s *ExporterRepositoryTestSuite) TestEachGroupsByBookAndChapter() {
+	s.create(1, "B", "2", "B2 first")
+	s.create(1, "A", "", "A")
+	s.create(1, "B", "1", "B1")
+	s.create(2, "A", "", "other user")
+	s.create(1, "", "", "no book")
+	s.create(1, "B", "2", "B2 second")
+
+	var texts []string
+	err := s.repo.Each(1, func(q models.QuoteModel) error {
+		texts = append(texts, q.Text)
+		return nil
+	})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]string{"no book", "A", "B1", "B2 first", "B2 second"}, texts)
+}
+
+func (s *ExporterRepositoryTestSuite) TestEachStopsAtError() {
+	s.create(1, "", "", "Quote 1")
+	s.create(1, "", "", "Quote 2")
+	stop := errors.New("stop")
+
+	calls := 0
+	err := s.repo.Each(1, func(q models.QuoteModel) error {
+		calls++
+		return stop
+	})
+	s.Assert().Equal(stop, err)
+	s.Assert().Equal(1, calls)
+}
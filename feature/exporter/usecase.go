@@ -0,0 +1,156 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"myquote/domain"
+	"myquote/domain/exceptions"
+	"myquote/domain/exporter"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/service/markdown"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader names the columns of a CSV export, a quote without page has an empty page.
+var csvHeader = []string{"id", "text", "book", "chapter", "page", "tags", "visibility", "created_at", "updated_at"}
+
+type Usecase struct {
+	l domain.Logger
+	r exporter.Repository
+}
+
+func NewUsecase(logger domain.Logger, repository exporter.Repository) *Usecase {
+	return &Usecase{l: logger, r: repository}
+}
+
+// Export writes nothing before the first quote is read, so an early failure can still be reported.
+func (uc *Usecase) Export(user models.User, format exporter.Format, w io.Writer) error {
+	var e encoder
+	switch format {
+	case exporter.MARKDOWN:
+		e = &markdownEncoder{w: markdown.NewWriter(w)}
+	case exporter.JSON:
+		e = &jsonEncoder{w: w}
+	case exporter.CSV:
+		e = &csvEncoder{w: csv.NewWriter(w)}
+	default:
+		return exceptions.InvalidExportFormat
+	}
+
+	count := 0
+	err := uc.r.Each(user.ID, func(m models.QuoteModel) error {
+		count++
+		return e.Encode(m.ToQuote())
+	})
+	if err == nil {
+		err = e.Close()
+	}
+	if err != nil {
+		uc.l.Debugf("export quotes error, user id: %d\n The error message: %s", user.ID, err.Error())
+		return exceptions.ServerError
+	}
+	uc.l.Infof("user %d exported %d quotes as %s", user.ID, count, format)
+	return nil
+}
+
+// encoder writes quotes one by one, Close completes the document.
+type encoder interface {
+	Encode(q models.Quote) error
+	Close() error
+}
+
+// markdownEncoder writes the layout the markdown importer reads. The layout has no place for
+// page, tags and visibility, so they are left out and a markdown export cannot restore them.
+type markdownEncoder struct {
+	w *markdown.Writer
+}
+
+func (e *markdownEncoder) Encode(q models.Quote) error {
+	return e.w.Write(importer.ParsedQuote{Book: q.Book, Chapter: q.Chapter, Text: q.Text})
+}
+
+func (e *markdownEncoder) Close() error {
+	return e.w.Flush()
+}
+
+// jsonEncoder writes an array with one quote per line.
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) Encode(q models.Quote) error {
+	b, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n"
+	if e.count == 0 {
+		prefix = "[\n"
+	}
+	e.count++
+	_, err = io.WriteString(e.w, prefix+string(b))
+	return err
+}
+
+func (e *jsonEncoder) Close() error {
+	end := "\n]\n"
+	if e.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (e *csvEncoder) Encode(q models.Quote) error {
+	err := e.start()
+	if err != nil {
+		return err
+	}
+	return e.w.Write([]string{
+		strconv.FormatInt(q.ID, 10),
+		q.Text,
+		q.Book,
+		q.Chapter,
+		page(q.Page),
+		strings.Join(q.Tags, ","),
+		q.Visibility,
+		q.CreatedAt.Format(time.RFC3339),
+		q.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (e *csvEncoder) Close() error {
+	err := e.start()
+	if err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// page leaves a missing page empty, the CSV import rejects page 0.
+func page(p int) string {
+	if p == 0 {
+		return ""
+	}
+	return strconv.Itoa(p)
+}
+
+// start writes the header once, an export without quotes still has it.
+func (e *csvEncoder) start() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write(csvHeader)
+}
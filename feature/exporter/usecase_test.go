@@ -0,0 +1,163 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/exporter"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/service/csvfile"
+	"myquote/service/logger"
+	"myquote/service/markdown"
+	"testing"
+	"time"
+)
+
+type MockedExporterRepo struct {
+	mock.Mock
+}
+
+// Each hands the mocked quotes to fn before it returns the mocked error.
+func (m *MockedExporterRepo) Each(userID int64, fn func(q models.QuoteModel) error) error {
+	args := m.Called(userID)
+	for _, q := range args.Get(0).([]models.QuoteModel) {
+		err := fn(q)
+		if err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+type ExporterUsecaseTestSuite struct {
+	suite.Suite
+	uc     *Usecase
+	repo   *MockedExporterRepo
+	user   models.User
+	quotes []models.QuoteModel
+}
+
+func TestNewExporterUsecase(t *testing.T) {
+	suite.Run(t, new(ExporterUsecaseTestSuite))
+}
+
+func (s *ExporterUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedExporterRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo)
+	s.user = models.User{ID: 1}
+	created := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	s.quotes = []models.QuoteModel{
+		{ID: 3, UserID: 1, Text: "no book", Visibility: "private", CreatedAt: created, UpdatedAt: created},
+		{ID: 1, UserID: 1, Text: "Quote 1\n\n- second paragraph", Book: "Book1", Chapter: "Chapter 1", Page: 12, Tags: "life,habit", Visibility: "public", CreatedAt: created, UpdatedAt: created},
+		{ID: 2, UserID: 1, Text: "Quote \"2\", with comma", Book: "Book1", Chapter: "Chapter 2", Visibility: "followers", CreatedAt: created, UpdatedAt: created},
+	}
+}
+
+// TestMarkdownKeepsBooksChaptersAndTexts reads the export back, pages, tags and visibility are lost.
+func (s *ExporterUsecaseTestSuite) TestMarkdownKeepsBooksChaptersAndTexts() {
+	s.repo.On("Each", int64(1)).Return(s.quotes, nil)
+	var b bytes.Buffer
+	err := s.uc.Export(s.user, exporter.MARKDOWN, &b)
+	s.Require().Nil(err)
+	s.Assert().Equal("- no book\n## Book1\n### Chapter 1\n- Quote 1\n\n  \\- second paragraph\n### Chapter 2\n- Quote \"2\", with comma\n", b.String())
+
+	parsed, warnings, err := markdown.NewParser().Parse(&b)
+	s.Require().Nil(err)
+	s.Assert().Empty(warnings)
+	s.Require().Len(parsed, len(s.quotes))
+	for i, q := range s.quotes {
+		s.Assert().Equal(importer.ParsedQuote{Line: parsed[i].Line, Book: q.Book, Chapter: q.Chapter, Text: q.Text}, parsed[i])
+	}
+}
+
+func (s *ExporterUsecaseTestSuite) TestJSON() {
+	s.repo.On("Each", int64(1)).Return(s.quotes, nil)
+	var b bytes.Buffer
+	err := s.uc.Export(s.user, exporter.JSON, &b)
+	s.Require().Nil(err)
+
+	var actual []models.Quote
+	s.Require().Nil(json.Unmarshal(b.Bytes(), &actual))
+	s.Require().Len(actual, 3)
+	s.Assert().Equal([]string{"life", "habit"}, actual[1].Tags)
+	s.Assert().Equal(12, actual[1].Page)
+	s.Assert().Equal("private", actual[0].Visibility)
+}
+
+func (s *ExporterUsecaseTestSuite) TestCSV() {
+	s.repo.On("Each", int64(1)).Return(s.quotes, nil)
+	var b bytes.Buffer
+	err := s.uc.Export(s.user, exporter.CSV, &b)
+	s.Require().Nil(err)
+
+	records, err := csv.NewReader(&b).ReadAll()
+	s.Require().Nil(err)
+	s.Require().Len(records, 4)
+	s.Assert().Equal(csvHeader, records[0])
+	s.Assert().Equal([]string{"1", "Quote 1\n\n- second paragraph", "Book1", "Chapter 1", "12", "life,habit", "public", "2022-05-01T08:00:00Z", "2022-05-01T08:00:00Z"}, records[2])
+	s.Assert().Equal("Quote \"2\", with comma", records[3][1])
+	s.Assert().Equal("", records[1][4])
+}
+
+func (s *ExporterUsecaseTestSuite) TestCSVImportsBack() {
+	s.repo.On("Each", int64(1)).Return(s.quotes, nil)
+	var b bytes.Buffer
+	err := s.uc.Export(s.user, exporter.CSV, &b)
+	s.Require().Nil(err)
+
+	mapping := importer.Mapping{Text: "text", Book: "book", Chapter: "chapter", Page: "page", Tags: "tags", Date: "created_at"}
+	parsed, warnings, err := csvfile.NewParser(mapping).Parse(&b)
+	s.Require().Nil(err)
+	s.Assert().Empty(warnings)
+	s.Require().Len(parsed, len(s.quotes))
+	for i, q := range s.quotes {
+		created := q.CreatedAt
+		s.Assert().Equal(importer.ParsedQuote{
+			Line:    parsed[i].Line,
+			Book:    q.Book,
+			Chapter: q.Chapter,
+			Page:    q.Page,
+			Text:    q.Text,
+			Tags:    parsed[i].Tags,
+			Date:    &created,
+		}, parsed[i])
+		s.Assert().Equal(q.Tags, models.JoinTags(parsed[i].Tags))
+	}
+}
+
+func (s *ExporterUsecaseTestSuite) TestEmptyLibrary() {
+	s.repo.On("Each", int64(1)).Return([]models.QuoteModel{}, nil)
+	for format, expected := range map[exporter.Format]string{
+		exporter.MARKDOWN: "",
+		exporter.JSON:     "[]\n",
+		exporter.CSV:      "id,text,book,chapter,page,tags,visibility,created_at,updated_at\n",
+	} {
+		var b bytes.Buffer
+		err := s.uc.Export(s.user, format, &b)
+		s.Assert().Nil(err)
+		s.Assert().Equal(expected, b.String(), format)
+	}
+}
+
+func (s *ExporterUsecaseTestSuite) TestInvalidFormat() {
+	var b bytes.Buffer
+	err := s.uc.Export(s.user, "pdf", &b)
+	s.Assert().Equal(exceptions.InvalidExportFormat, err)
+	s.Assert().Empty(b.String())
+	s.repo.AssertNotCalled(s.T(), "Each", mock.Anything)
+}
+
+func (s *ExporterUsecaseTestSuite) TestThrowServerErrorWithoutOutput() {
+	s.repo.On("Each", int64(1)).Return([]models.QuoteModel{}, errors.New("database is gone"))
+	for _, format := range []exporter.Format{exporter.MARKDOWN, exporter.JSON, exporter.CSV} {
+		var b bytes.Buffer
+		err := s.uc.Export(s.user, format, &b)
+		s.Assert().Equal(exceptions.ServerError, err)
+		s.Assert().Empty(b.String(), format)
+	}
+}
@@ -0,0 +1,126 @@
+package friend
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	logger   domain.Logger
+	friendUc friend.Usecase
+}
+
+const SEARCH_ENDPOINT = "/api/users/search"
+const FOLLOWS_ENDPOINT = "/api/follows"
+const FOLLOW_REQUESTS_ENDPOINT = FOLLOWS_ENDPOINT + "/requests"
+const FOLLOWERS_ENDPOINT = "/api/followers"
+const FOLLOWING_ENDPOINT = "/api/following"
+
+// NewFriendHTTPHandler registers the friend routes.
+func NewFriendHTTPHandler(c *gin.Engine, l domain.Logger, uc friend.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, friendUc: uc}
+	g := c.Group("", middlewares...)
+	g.GET(SEARCH_ENDPOINT, handler.search)
+	g.POST(FOLLOWS_ENDPOINT, handler.request)
+	g.GET(FOLLOW_REQUESTS_ENDPOINT, handler.requests)
+	g.POST(FOLLOWS_ENDPOINT+"/:id/accept", handler.accept)
+	g.POST(FOLLOWS_ENDPOINT+"/:id/reject", handler.reject)
+	g.DELETE(FOLLOWS_ENDPOINT+"/:id", handler.cancel)
+	g.GET(FOLLOWERS_ENDPOINT, handler.followers)
+	g.GET(FOLLOWING_ENDPOINT, handler.following)
+}
+
+func (h *handler) search(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	profiles, err := h.friendUc.Search(user, c.Query("q"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+func (h *handler) request(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	var f friend.NewFollow
+	err := c.Bind(&f)
+	if err != nil {
+		h.logger.Debugf("Convert follow json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	follow, err := h.friendUc.Request(user, f)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, follow)
+}
+
+func (h *handler) accept(c *gin.Context) {
+	h.change(c, h.friendUc.Accept)
+}
+
+func (h *handler) reject(c *gin.Context) {
+	h.change(c, h.friendUc.Reject)
+}
+
+func (h *handler) cancel(c *gin.Context) {
+	h.change(c, h.friendUc.Cancel)
+}
+
+// change runs a status change on the follow request of the path.
+func (h *handler) change(c *gin.Context, action func(user models.User, id int64) (models.Follow, error)) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	follow, err := action(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, follow)
+}
+
+func (h *handler) requests(c *gin.Context) {
+	h.list(c, h.friendUc.Requests)
+}
+
+func (h *handler) followers(c *gin.Context) {
+	h.list(c, h.friendUc.Followers)
+}
+
+func (h *handler) following(c *gin.Context) {
+	h.list(c, h.friendUc.Following)
+}
+
+func (h *handler) list(c *gin.Context, list func(user models.User) ([]models.Follow, error)) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	follows, err := list(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, follows)
+}
@@ -0,0 +1,190 @@
+package friend
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedFriendUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedFriendUsecase) Search(user models.User, q string) ([]models.Profile, error) {
+	args := m.Called(user, q)
+	return args.Get(0).([]models.Profile), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Request(user models.User, f friend.NewFollow) (models.Follow, error) {
+	args := m.Called(user, f)
+	return args.Get(0).(models.Follow), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Cancel(user models.User, id int64) (models.Follow, error) {
+	args := m.Called(user, id)
+	return args.Get(0).(models.Follow), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Accept(user models.User, id int64) (models.Follow, error) {
+	args := m.Called(user, id)
+	return args.Get(0).(models.Follow), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Reject(user models.User, id int64) (models.Follow, error) {
+	args := m.Called(user, id)
+	return args.Get(0).(models.Follow), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Requests(user models.User) ([]models.Follow, error) {
+	args := m.Called(user)
+	return args.Get(0).([]models.Follow), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Followers(user models.User) ([]models.Follow, error) {
+	args := m.Called(user)
+	return args.Get(0).([]models.Follow), args.Error(1)
+}
+
+func (m *MockedFriendUsecase) Following(user models.User) ([]models.Follow, error) {
+	args := m.Called(user)
+	return args.Get(0).([]models.Follow), args.Error(1)
+}
+
+type FriendTestSuite struct {
+	suite.Suite
+	uc   *MockedFriendUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestFriendHTTPHandler(t *testing.T) {
+	suite.Run(t, new(FriendTestSuite))
+}
+
+func (s *FriendTestSuite) SetupTest() {
+	s.uc = new(MockedFriendUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *FriendTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *FriendTestSuite) TestSearch() {
+	profiles := []models.Profile{{ID: 2, Name: "Leslie"}}
+	s.uc.On("Search", s.user, "Les").Return(profiles, nil)
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, SEARCH_ENDPOINT+"?q=Les", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.Profile
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(profiles, actual)
+}
+
+func (s *FriendTestSuite) TestRequest() {
+	f := friend.NewFollow{UserID: 2}
+	s.uc.On("Request", s.user, f).Return(models.Follow{ID: 3, Status: "pending"}, nil)
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	body, _ := json.Marshal(f)
+	req, _ := newTestRequest(http.MethodPost, FOLLOWS_ENDPOINT, body)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Follow
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusCreated, s.r.Code)
+	s.Assert().Equal(int64(3), actual.ID)
+}
+
+func (s *FriendTestSuite) TestRequestInvalidJson() {
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, FOLLOWS_ENDPOINT, []byte("{"))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.InvalidInput.Error(), actual.Message)
+}
+
+func (s *FriendTestSuite) TestRequestUserNotExists() {
+	s.uc.On("Request", s.user, friend.NewFollow{UserID: 9}).Return(models.Follow{}, exceptions.UserNotExists)
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, FOLLOWS_ENDPOINT, []byte(`{"user_id":9}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+}
+
+func (s *FriendTestSuite) TestAccept() {
+	s.uc.On("Accept", s.user, int64(3)).Return(models.Follow{ID: 3, Status: "accepted"}, nil)
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, FOLLOWS_ENDPOINT+"/3/accept", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Follow
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal("accepted", actual.Status)
+}
+
+func (s *FriendTestSuite) TestRejectForbidden() {
+	s.uc.On("Reject", s.user, int64(3)).Return(models.Follow{}, exceptions.Forbidden)
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, FOLLOWS_ENDPOINT+"/3/reject", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusForbidden, s.r.Code)
+}
+
+func (s *FriendTestSuite) TestCancelInvalidID() {
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodDelete, FOLLOWS_ENDPOINT+"/abc", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Cancel", mock.Anything, mock.Anything)
+}
+
+func (s *FriendTestSuite) TestRequests() {
+	follows := []models.Follow{{ID: 3, Follower: models.Profile{ID: 2, Name: "Leslie"}, Status: "pending"}}
+	s.uc.On("Requests", s.user).Return(follows, nil)
+	NewFriendHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, FOLLOW_REQUESTS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.Follow
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Len(actual, 1)
+}
+
+func (s *FriendTestSuite) TestFollowingUnauthorized() {
+	NewFriendHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, FOLLOWING_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+}
@@ -0,0 +1,118 @@
+package friend
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"strings"
+)
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the follows table.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.FollowModel{})
+	if err != nil {
+		r.l.Errorf("migrate follows table error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) SearchUsers(prefix string, excludeID int64, limit int) ([]models.UserModel, error) {
+	var users []models.UserModel
+	like := escapeLike(prefix) + "%"
+	result := r.db.
+		Where("id <> ?", excludeID).
+		Where("name LIKE ? ESCAPE '!' OR email LIKE ? ESCAPE '!'", like, like).
+		Order("name, id").
+		Limit(limit).
+		Find(&users)
+	if result.Error != nil {
+		r.l.Debugf("search users error, prefix: %s\n The error message: %s", prefix, result.Error.Error())
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+func (r *Repository) UserExists(id int64) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.UserModel{}).Where("id = ?", id).Count(&count)
+	if result.Error != nil {
+		r.l.Debugf("find user error, user id: %d\n The error message: %s", id, result.Error.Error())
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *Repository) Find(id int64) (bool, models.FollowModel, error) {
+	return r.first(r.db.Where("id = ?", id))
+}
+
+func (r *Repository) FindBetween(followerID int64, followeeID int64) (bool, models.FollowModel, error) {
+	return r.first(r.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID))
+}
+
+func (r *Repository) first(query *gorm.DB) (bool, models.FollowModel, error) {
+	var follow models.FollowModel
+	result := query.Preload("Follower").Preload("Followee").First(&follow)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.FollowModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find follow error.\n The error message: %s", result.Error.Error())
+		return false, models.FollowModel{}, result.Error
+	}
+	return true, follow, nil
+}
+
+func (r *Repository) Create(follow models.FollowModel) (models.FollowModel, error) {
+	result := r.db.Omit("Follower", "Followee").Create(&follow)
+	if result.Error != nil {
+		r.l.Debugf("create follow error, follower: %d, followee: %d\n The error message: %s", follow.FollowerID, follow.FolloweeID, result.Error.Error())
+		return models.FollowModel{}, result.Error
+	}
+	_, created, err := r.Find(follow.ID)
+	return created, err
+}
+
+func (r *Repository) UpdateStatus(id int64, from friend.Status, to friend.Status) (bool, error) {
+	result := r.db.Model(&models.FollowModel{}).Where("id = ? AND status = ?", id, string(from)).Update("status", string(to))
+	if result.Error != nil {
+		r.l.Debugf("update follow status error, follow id: %d\n The error message: %s", id, result.Error.Error())
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *Repository) Followers(userID int64, status friend.Status) ([]models.FollowModel, error) {
+	return r.list(r.db.Where("followee_id = ? AND status = ?", userID, string(status)))
+}
+
+func (r *Repository) Following(userID int64, status friend.Status) ([]models.FollowModel, error) {
+	return r.list(r.db.Where("follower_id = ? AND status = ?", userID, string(status)))
+}
+
+func (r *Repository) list(query *gorm.DB) ([]models.FollowModel, error) {
+	var follows []models.FollowModel
+	result := query.Preload("Follower").Preload("Followee").Order("updated_at desc, id desc").Find(&follows)
+	if result.Error != nil {
+		r.l.Debugf("list follows error.\n The error message: %s", result.Error.Error())
+		return nil, result.Error
+	}
+	return follows, nil
+}
+
+// escapeLike escapes the LIKE wildcards with '!', a backslash is not portable between sqlite and mysql.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
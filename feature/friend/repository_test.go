@@ -0,0 +1,122 @@
+package friend
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+)
+
+type FriendRepositoryTestSuite struct {
+	suite.Suite
+	db    *gorm.DB
+	repo  *Repository
+	users []models.UserModel
+}
+
+func TestFriendRepository(t *testing.T) {
+	suite.Run(t, new(FriendRepositoryTestSuite))
+}
+
+func (s *FriendRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.UserModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+
+	s.users = []models.UserModel{
+		{Name: "Lester", Email: "lester@gmail.com"},
+		{Name: "Leslie", Email: "leslie@gmail.com"},
+		{Name: "Amy", Email: "le_amy@gmail.com"},
+	}
+	s.Require().NoError(db.Create(&s.users).Error)
+}
+
+func (s *FriendRepositoryTestSuite) TestSearchUsers() {
+	users, err := s.repo.SearchUsers("Les", s.users[0].ID, 20)
+	s.Assert().Nil(err)
+	s.Assert().Len(users, 1)
+	s.Assert().Equal("Leslie", users[0].Name)
+}
+
+func (s *FriendRepositoryTestSuite) TestSearchUsersEscapeWildcard() {
+	users, err := s.repo.SearchUsers("le_", 0, 20)
+	s.Assert().Nil(err)
+	s.Assert().Len(users, 1)
+	s.Assert().Equal("Amy", users[0].Name)
+}
+
+func (s *FriendRepositoryTestSuite) TestUserExists() {
+	exists, err := s.repo.UserExists(s.users[1].ID)
+	s.Assert().Nil(err)
+	s.Assert().True(exists)
+
+	exists, err = s.repo.UserExists(99)
+	s.Assert().Nil(err)
+	s.Assert().False(exists)
+}
+
+func (s *FriendRepositoryTestSuite) TestCreateAndFindBetween() {
+	created, err := s.repo.Create(models.FollowModel{FollowerID: s.users[0].ID, FolloweeID: s.users[1].ID, Status: string(friend.PENDING)})
+	s.Assert().Nil(err)
+	s.Assert().Equal("Lester", created.Follower.Name)
+	s.Assert().Equal("Leslie", created.Followee.Name)
+
+	find, actual, err := s.repo.FindBetween(s.users[0].ID, s.users[1].ID)
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal(created.ID, actual.ID)
+
+	find, _, err = s.repo.FindBetween(s.users[1].ID, s.users[0].ID)
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *FriendRepositoryTestSuite) TestCreateDuplicate() {
+	f := models.FollowModel{FollowerID: s.users[0].ID, FolloweeID: s.users[1].ID, Status: string(friend.PENDING)}
+	_, err := s.repo.Create(f)
+	s.Require().Nil(err)
+
+	_, err = s.repo.Create(f)
+	s.Assert().NotNil(err)
+}
+
+func (s *FriendRepositoryTestSuite) TestUpdateStatusOnlyFromExpected() {
+	created, _ := s.repo.Create(models.FollowModel{FollowerID: s.users[0].ID, FolloweeID: s.users[1].ID, Status: string(friend.PENDING)})
+
+	changed, err := s.repo.UpdateStatus(created.ID, friend.PENDING, friend.ACCEPTED)
+	s.Assert().Nil(err)
+	s.Assert().True(changed)
+
+	changed, err = s.repo.UpdateStatus(created.ID, friend.PENDING, friend.REJECTED)
+	s.Assert().Nil(err)
+	s.Assert().False(changed)
+
+	_, actual, _ := s.repo.Find(created.ID)
+	s.Assert().Equal(string(friend.ACCEPTED), actual.Status)
+}
+
+func (s *FriendRepositoryTestSuite) TestFollowersAndFollowing() {
+	s.repo.Create(models.FollowModel{FollowerID: s.users[0].ID, FolloweeID: s.users[1].ID, Status: string(friend.ACCEPTED)})
+	s.repo.Create(models.FollowModel{FollowerID: s.users[2].ID, FolloweeID: s.users[1].ID, Status: string(friend.PENDING)})
+
+	followers, err := s.repo.Followers(s.users[1].ID, friend.ACCEPTED)
+	s.Assert().Nil(err)
+	s.Assert().Len(followers, 1)
+	s.Assert().Equal(s.users[0].ID, followers[0].Follower.ID)
+
+	requests, err := s.repo.Followers(s.users[1].ID, friend.PENDING)
+	s.Assert().Nil(err)
+	s.Assert().Len(requests, 1)
+	s.Assert().Equal(s.users[2].ID, requests[0].Follower.ID)
+
+	following, err := s.repo.Following(s.users[0].ID, friend.ACCEPTED)
+	s.Assert().Nil(err)
+	s.Assert().Len(following, 1)
+	s.Assert().Equal(s.users[1].ID, following[0].Followee.ID)
+}
@@ -0,0 +1,170 @@
+package friend
+
+import (
+	"myquote/domain"
+	"myquote/domain/exceptions"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"strings"
+)
+
+const SEARCH_LIMIT = 20
+
+type Usecase struct {
+	l domain.Logger
+	r friend.Repository
+}
+
+func NewUsecase(logger domain.Logger, repository friend.Repository) *Usecase {
+	return &Usecase{l: logger, r: repository}
+}
+
+// Search finds other users whose name or email starts with q.
+func (uc *Usecase) Search(user models.User, q string) ([]models.Profile, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, exceptions.InvalidInput
+	}
+	users, err := uc.r.SearchUsers(q, user.ID, SEARCH_LIMIT)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	profiles := make([]models.Profile, 0, len(users))
+	for _, u := range users {
+		profiles = append(profiles, toProfile(u))
+	}
+	return profiles, nil
+}
+
+// Request asks to follow another user. A rejected or cancelled request is applied again.
+func (uc *Usecase) Request(user models.User, f friend.NewFollow) (models.Follow, error) {
+	if f.UserID == user.ID {
+		return models.Follow{}, exceptions.CannotFollowSelf
+	}
+	exists, err := uc.r.UserExists(f.UserID)
+	if err != nil {
+		return models.Follow{}, exceptions.ServerError
+	}
+	if !exists {
+		return models.Follow{}, exceptions.UserNotExists
+	}
+
+	find, follow, err := uc.r.FindBetween(user.ID, f.UserID)
+	if err != nil {
+		return models.Follow{}, exceptions.ServerError
+	}
+	if find {
+		if !friend.Status(follow.Status).CanTransition(friend.PENDING) {
+			return models.Follow{}, exceptions.FollowExists
+		}
+		return uc.transition(follow, friend.PENDING)
+	}
+
+	created, err := uc.r.Create(models.FollowModel{FollowerID: user.ID, FolloweeID: f.UserID, Status: string(friend.PENDING)})
+	if err != nil {
+		return models.Follow{}, exceptions.ServerError
+	}
+	uc.l.Infof("user %d asked to follow user %d", user.ID, f.UserID)
+	return toFollow(created), nil
+}
+
+// Cancel withdraws a pending request or stops following, only the follower can do it.
+func (uc *Usecase) Cancel(user models.User, id int64) (models.Follow, error) {
+	follow, err := uc.find(id, func(f models.FollowModel) bool { return f.FollowerID == user.ID })
+	if err != nil {
+		return models.Follow{}, err
+	}
+	return uc.transition(follow, friend.CANCELLED)
+}
+
+// Accept lets the follower see the quotes of user, only the followee can do it.
+func (uc *Usecase) Accept(user models.User, id int64) (models.Follow, error) {
+	follow, err := uc.find(id, func(f models.FollowModel) bool { return f.FolloweeID == user.ID })
+	if err != nil {
+		return models.Follow{}, err
+	}
+	return uc.transition(follow, friend.ACCEPTED)
+}
+
+func (uc *Usecase) Reject(user models.User, id int64) (models.Follow, error) {
+	follow, err := uc.find(id, func(f models.FollowModel) bool { return f.FolloweeID == user.ID })
+	if err != nil {
+		return models.Follow{}, err
+	}
+	return uc.transition(follow, friend.REJECTED)
+}
+
+// Requests lists the pending requests to follow user.
+func (uc *Usecase) Requests(user models.User) ([]models.Follow, error) {
+	return toFollows(uc.r.Followers(user.ID, friend.PENDING))
+}
+
+func (uc *Usecase) Followers(user models.User) ([]models.Follow, error) {
+	return toFollows(uc.r.Followers(user.ID, friend.ACCEPTED))
+}
+
+func (uc *Usecase) Following(user models.User) ([]models.Follow, error) {
+	return toFollows(uc.r.Following(user.ID, friend.ACCEPTED))
+}
+
+// find returns the follow request when allowed says user may change it.
+func (uc *Usecase) find(id int64, allowed func(f models.FollowModel) bool) (models.FollowModel, error) {
+	find, follow, err := uc.r.Find(id)
+	if err != nil {
+		return models.FollowModel{}, exceptions.ServerError
+	}
+	if !find {
+		return models.FollowModel{}, exceptions.FollowNotExists
+	}
+	if !allowed(follow) {
+		return models.FollowModel{}, exceptions.Forbidden
+	}
+	return follow, nil
+}
+
+func (uc *Usecase) transition(follow models.FollowModel, to friend.Status) (models.Follow, error) {
+	from := friend.Status(follow.Status)
+	if !from.CanTransition(to) {
+		uc.l.Debugf("follow %d cannot change from %s to %s", follow.ID, from, to)
+		return models.Follow{}, exceptions.InvalidFollowStatus
+	}
+	changed, err := uc.r.UpdateStatus(follow.ID, from, to)
+	if err != nil {
+		return models.Follow{}, exceptions.ServerError
+	}
+	if !changed {
+		// another request changed the status first
+		return models.Follow{}, exceptions.InvalidFollowStatus
+	}
+	_, updated, err := uc.r.Find(follow.ID)
+	if err != nil {
+		return models.Follow{}, exceptions.ServerError
+	}
+	return toFollow(updated), nil
+}
+
+func toFollows(follows []models.FollowModel, err error) ([]models.Follow, error) {
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	result := make([]models.Follow, 0, len(follows))
+	for _, f := range follows {
+		result = append(result, toFollow(f))
+	}
+	return result, nil
+}
+
+func toFollow(f models.FollowModel) models.Follow {
+	return models.Follow{
+		ID:        f.ID,
+		Follower:  toProfile(f.Follower),
+		Followee:  toProfile(f.Followee),
+		Status:    f.Status,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
+
+func toProfile(u models.UserModel) models.Profile {
+	return models.Profile{ID: u.ID, Name: u.Name}
+}
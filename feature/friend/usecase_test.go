@@ -0,0 +1,177 @@
+package friend
+
+import (
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"myquote/service/logger"
+	"testing"
+)
+
+type MockedFriendRepo struct {
+	mock.Mock
+}
+
+func (m *MockedFriendRepo) SearchUsers(prefix string, excludeID int64, limit int) ([]models.UserModel, error) {
+	args := m.Called(prefix, excludeID, limit)
+	return args.Get(0).([]models.UserModel), args.Error(1)
+}
+
+func (m *MockedFriendRepo) UserExists(id int64) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockedFriendRepo) Find(id int64) (bool, models.FollowModel, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Get(1).(models.FollowModel), args.Error(2)
+}
+
+func (m *MockedFriendRepo) FindBetween(followerID int64, followeeID int64) (bool, models.FollowModel, error) {
+	args := m.Called(followerID, followeeID)
+	return args.Bool(0), args.Get(1).(models.FollowModel), args.Error(2)
+}
+
+func (m *MockedFriendRepo) Create(follow models.FollowModel) (models.FollowModel, error) {
+	args := m.Called(follow)
+	return args.Get(0).(models.FollowModel), args.Error(1)
+}
+
+func (m *MockedFriendRepo) UpdateStatus(id int64, from friend.Status, to friend.Status) (bool, error) {
+	args := m.Called(id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockedFriendRepo) Followers(userID int64, status friend.Status) ([]models.FollowModel, error) {
+	args := m.Called(userID, status)
+	return args.Get(0).([]models.FollowModel), args.Error(1)
+}
+
+func (m *MockedFriendRepo) Following(userID int64, status friend.Status) ([]models.FollowModel, error) {
+	args := m.Called(userID, status)
+	return args.Get(0).([]models.FollowModel), args.Error(1)
+}
+
+type FriendUsecaseTestSuite struct {
+	suite.Suite
+	uc   *Usecase
+	repo *MockedFriendRepo
+	user models.User
+}
+
+func TestFriendUsecase(t *testing.T) {
+	suite.Run(t, new(FriendUsecaseTestSuite))
+}
+
+func (s *FriendUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedFriendRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo)
+	s.user = models.User{ID: 1, Name: "Lester"}
+}
+
+func (s *FriendUsecaseTestSuite) TestSearch() {
+	s.repo.On("SearchUsers", "Les", int64(1), SEARCH_LIMIT).Return([]models.UserModel{{ID: 2, Name: "Leslie", Email: "leslie@gmail.com"}}, nil)
+	profiles, err := s.uc.Search(s.user, "  Les ")
+	s.Assert().Nil(err)
+	s.Assert().Equal([]models.Profile{{ID: 2, Name: "Leslie"}}, profiles)
+}
+
+func (s *FriendUsecaseTestSuite) TestSearchEmpty() {
+	_, err := s.uc.Search(s.user, " ")
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	s.repo.AssertNotCalled(s.T(), "SearchUsers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *FriendUsecaseTestSuite) TestRequest() {
+	s.repo.On("UserExists", int64(2)).Return(true, nil)
+	s.repo.On("FindBetween", int64(1), int64(2)).Return(false, models.FollowModel{}, nil)
+	s.repo.On("Create", models.FollowModel{FollowerID: 1, FolloweeID: 2, Status: "pending"}).Return(models.FollowModel{ID: 3, FollowerID: 1, FolloweeID: 2, Status: "pending"}, nil)
+	follow, err := s.uc.Request(s.user, friend.NewFollow{UserID: 2})
+	s.Assert().Nil(err)
+	s.Assert().Equal(int64(3), follow.ID)
+	s.Assert().Equal("pending", follow.Status)
+}
+
+func (s *FriendUsecaseTestSuite) TestRequestSelf() {
+	_, err := s.uc.Request(s.user, friend.NewFollow{UserID: 1})
+	s.Assert().Equal(exceptions.CannotFollowSelf, err)
+}
+
+func (s *FriendUsecaseTestSuite) TestRequestUserNotExists() {
+	s.repo.On("UserExists", int64(2)).Return(false, nil)
+	_, err := s.uc.Request(s.user, friend.NewFollow{UserID: 2})
+	s.Assert().Equal(exceptions.UserNotExists, err)
+}
+
+func (s *FriendUsecaseTestSuite) TestRequestExists() {
+	s.repo.On("UserExists", int64(2)).Return(true, nil)
+	s.repo.On("FindBetween", int64(1), int64(2)).Return(true, models.FollowModel{ID: 3, Status: "accepted"}, nil)
+	_, err := s.uc.Request(s.user, friend.NewFollow{UserID: 2})
+	s.Assert().Equal(exceptions.FollowExists, err)
+}
+
+func (s *FriendUsecaseTestSuite) TestRequestAgainAfterRejected() {
+	s.repo.On("UserExists", int64(2)).Return(true, nil)
+	s.repo.On("FindBetween", int64(1), int64(2)).Return(true, models.FollowModel{ID: 3, FollowerID: 1, FolloweeID: 2, Status: "rejected"}, nil)
+	s.repo.On("UpdateStatus", int64(3), friend.REJECTED, friend.PENDING).Return(true, nil)
+	s.repo.On("Find", int64(3)).Return(true, models.FollowModel{ID: 3, FollowerID: 1, FolloweeID: 2, Status: "pending"}, nil)
+	follow, err := s.uc.Request(s.user, friend.NewFollow{UserID: 2})
+	s.Assert().Nil(err)
+	s.Assert().Equal("pending", follow.Status)
+	s.repo.AssertNotCalled(s.T(), "Create", mock.Anything)
+}
+
+func (s *FriendUsecaseTestSuite) TestAccept() {
+	pending := models.FollowModel{ID: 3, FollowerID: 2, FolloweeID: 1, Status: "pending"}
+	accepted := pending
+	accepted.Status = "accepted"
+	s.repo.On("Find", int64(3)).Return(true, pending, nil).Once()
+	s.repo.On("UpdateStatus", int64(3), friend.PENDING, friend.ACCEPTED).Return(true, nil)
+	s.repo.On("Find", int64(3)).Return(true, accepted, nil).Once()
+	follow, err := s.uc.Accept(s.user, 3)
+	s.Assert().Nil(err)
+	s.Assert().Equal("accepted", follow.Status)
+}
+
+func (s *FriendUsecaseTestSuite) TestAcceptByFollower() {
+	s.repo.On("Find", int64(3)).Return(true, models.FollowModel{ID: 3, FollowerID: 1, FolloweeID: 2, Status: "pending"}, nil)
+	_, err := s.uc.Accept(s.user, 3)
+	s.Assert().Equal(exceptions.Forbidden, err)
+	s.repo.AssertNotCalled(s.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *FriendUsecaseTestSuite) TestRejectAccepted() {
+	s.repo.On("Find", int64(3)).Return(true, models.FollowModel{ID: 3, FollowerID: 2, FolloweeID: 1, Status: "accepted"}, nil)
+	_, err := s.uc.Reject(s.user, 3)
+	s.Assert().Equal(exceptions.InvalidFollowStatus, err)
+}
+
+func (s *FriendUsecaseTestSuite) TestCancelNotExists() {
+	s.repo.On("Find", int64(3)).Return(false, models.FollowModel{}, nil)
+	_, err := s.uc.Cancel(s.user, 3)
+	s.Assert().Equal(exceptions.FollowNotExists, err)
+}
+
+func (s *FriendUsecaseTestSuite) TestCancelChangedConcurrently() {
+	s.repo.On("Find", int64(3)).Return(true, models.FollowModel{ID: 3, FollowerID: 1, FolloweeID: 2, Status: "pending"}, nil)
+	s.repo.On("UpdateStatus", int64(3), friend.PENDING, friend.CANCELLED).Return(false, nil)
+	_, err := s.uc.Cancel(s.user, 3)
+	s.Assert().Equal(exceptions.InvalidFollowStatus, err)
+}
+
+func (s *FriendUsecaseTestSuite) TestRequests() {
+	s.repo.On("Followers", int64(1), friend.PENDING).Return([]models.FollowModel{{ID: 3, Follower: models.UserModel{ID: 2, Name: "Leslie"}, Status: "pending"}}, nil)
+	follows, err := s.uc.Requests(s.user)
+	s.Assert().Nil(err)
+	s.Assert().Len(follows, 1)
+	s.Assert().Equal(models.Profile{ID: 2, Name: "Leslie"}, follows[0].Follower)
+}
+
+func (s *FriendUsecaseTestSuite) TestFollowingServerError() {
+	s.repo.On("Following", int64(1), friend.ACCEPTED).Return([]models.FollowModel(nil), errors.New("db error"))
+	_, err := s.uc.Following(s.user)
+	s.Assert().Equal(exceptions.ServerError, err)
+}
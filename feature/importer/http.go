@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	logger     domain.Logger
+	importerUc importer.Usecase
+}
+
+const IMPORTS_ENDPOINT = "/api/imports"
+const MARKDOWN_IMPORT_ENDPOINT = IMPORTS_ENDPOINT + "/markdown"
+const KINDLE_IMPORT_ENDPOINT = IMPORTS_ENDPOINT + "/kindle"
+const READWISE_IMPORT_ENDPOINT = IMPORTS_ENDPOINT + "/readwise"
+const CSV_IMPORT_ENDPOINT = IMPORTS_ENDPOINT + "/csv"
+const FILE_FIELD = "file"
+const MAX_FILE_SIZE = 5 << 20
+
+// NewImporterHTTPHandler registers the import routes.
+func NewImporterHTTPHandler(c *gin.Engine, l domain.Logger, uc importer.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, importerUc: uc}
+	g := c.Group(IMPORTS_ENDPOINT, middlewares...)
+	g.POST("/markdown", handler.markdown)
+	g.POST("/kindle", handler.kindle)
+	g.POST("/readwise", handler.readwise)
+	g.POST("/csv", handler.csv)
+	g.GET("/:id", handler.job)
+	g.POST("/:id/cancel", handler.cancel)
+}
+
+// markdown imports the uploaded notes.
+func (h *handler) markdown(c *gin.Context) {
+	h.upload(c, importer.MARKDOWN)
+}
+
+// kindle imports the uploaded "My Clippings.txt".
+func (h *handler) kindle(c *gin.Context) {
+	h.upload(c, importer.KINDLE)
+}
+
+// readwise imports the uploaded Readwise export.
+func (h *handler) readwise(c *gin.Context) {
+	h.upload(c, importer.READWISE)
+}
+
+// csv imports the uploaded CSV file, the form fields next to the file name the column of each
+// quote field, like text=Quote and book=Title.
+func (h *handler) csv(c *gin.Context) {
+	h.upload(c, importer.CSV)
+}
+
+// upload responds 202 with the queued job, the client polls GET /api/imports/:id for its
+// progress. With ?dry_run=true it responds the preview instead, with ?update=true a changed
+// quote replaces the one at the same position of its chapter.
+func (h *handler) upload(c *gin.Context, format importer.Format) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	dryRun, update, err := readOptions(c)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	source := importer.Source{Format: format}
+	if format == importer.CSV {
+		err = c.ShouldBind(&source.Mapping)
+		if err != nil {
+			h.logger.Debugf("read csv mapping error: %s", err.Error())
+			c.Error(exceptions.InvalidCSVMapping)
+			return
+		}
+	}
+	header, err := c.FormFile(FILE_FIELD)
+	if err != nil {
+		h.logger.Debugf("read import file error: %s", err.Error())
+		c.Error(exceptions.InvalidImportFile)
+		return
+	}
+	if header.Size > MAX_FILE_SIZE {
+		c.Error(exceptions.ImportFileTooLarge)
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.Error(exceptions.ServerError.Wrap(err))
+		return
+	}
+	defer f.Close()
+
+	if dryRun {
+		result, err := h.importerUc.Preview(user, source, f, update)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+	file, err := io.ReadAll(f)
+	if err != nil {
+		c.Error(exceptions.ServerError.Wrap(err))
+		return
+	}
+	job, err := h.importerUc.Start(user, source, file, update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Location", fmt.Sprintf("%s/%d", IMPORTS_ENDPOINT, job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *handler) job(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+	job, err := h.importerUc.Job(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *handler) cancel(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := jobID(c)
+	if !ok {
+		return
+	}
+	job, err := h.importerUc.Cancel(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// readOptions reads ?dry_run= and ?update=, both are false by default.
+func readOptions(c *gin.Context) (bool, bool, error) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		return false, false, err
+	}
+	update, err := strconv.ParseBool(c.DefaultQuery("update", "false"))
+	if err != nil {
+		return false, false, err
+	}
+	return dryRun, update, nil
+}
+
+func jobID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"io"
+	"mime/multipart"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedImporterUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedImporterUsecase) Preview(user models.User, source importer.Source, r io.Reader, update bool) (importer.Result, error) {
+	b, _ := io.ReadAll(r)
+	args := m.Called(user, source, string(b), update)
+	return args.Get(0).(importer.Result), args.Error(1)
+}
+
+func (m *MockedImporterUsecase) Start(user models.User, source importer.Source, file []byte, update bool) (importer.Job, error) {
+	args := m.Called(user, source, string(file), update)
+	return args.Get(0).(importer.Job), args.Error(1)
+}
+
+func (m *MockedImporterUsecase) Job(user models.User, id int64) (importer.Job, error) {
+	args := m.Called(user, id)
+	return args.Get(0).(importer.Job), args.Error(1)
+}
+
+func (m *MockedImporterUsecase) Cancel(user models.User, id int64) (importer.Job, error) {
+	args := m.Called(user, id)
+	return args.Get(0).(importer.Job), args.Error(1)
+}
+
+type ImporterTestSuite struct {
+	suite.Suite
+	uc   *MockedImporterUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestImporterHTTPHandler(t *testing.T) {
+	suite.Run(t, new(ImporterTestSuite))
+}
+
+func (s *ImporterTestSuite) SetupTest() {
+	s.uc = new(MockedImporterUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *ImporterTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newUploadRequest(endpoint string, field string, content string) *http.Request {
+	return newFormRequest(endpoint, field, content, nil)
+}
+
+// newFormRequest uploads content with the form values next to it.
+func newFormRequest(endpoint string, field string, content string, values map[string]string) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for k, v := range values {
+		w.WriteField(k, v)
+	}
+	part, _ := w.CreateFormFile(field, "notes.md")
+	part.Write([]byte(content))
+	w.Close()
+	req, _ := http.NewRequest(http.MethodPost, endpoint, body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func (s *ImporterTestSuite) TestPreview() {
+	content := "## Book1\n- Quote 1\n"
+	result := importer.Result{Quotes: []importer.ParsedQuote{{Line: 2, Book: "Book1", Text: "Quote 1"}}, Warnings: []importer.Warning{}, Inserted: 1}
+	s.uc.On("Preview", s.user, importer.Source{Format: importer.MARKDOWN}, content, false).Return(result, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT+"?dry_run=true", FILE_FIELD, content))
+
+	var actual importer.Result
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(result, actual)
+	s.uc.AssertNotCalled(s.T(), "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterTestSuite) TestImportQueuesJob() {
+	content := "## Book1\n- Quote 1\n"
+	s.uc.On("Start", s.user, importer.Source{Format: importer.MARKDOWN}, content, false).Return(importer.Job{ID: 7, Format: importer.MARKDOWN, Status: importer.JOB_QUEUED}, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT, FILE_FIELD, content))
+
+	var actual importer.Job
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusAccepted, s.r.Code)
+	s.Assert().Equal(IMPORTS_ENDPOINT+"/7", s.r.Header().Get("Location"))
+	s.Assert().Equal(importer.JOB_QUEUED, actual.Status)
+}
+
+func (s *ImporterTestSuite) TestKindleImport() {
+	s.uc.On("Start", s.user, importer.Source{Format: importer.KINDLE}, "clippings", false).Return(importer.Job{ID: 7}, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(KINDLE_IMPORT_ENDPOINT, FILE_FIELD, "clippings"))
+	s.Assert().Equal(http.StatusAccepted, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestReadwiseImport() {
+	s.uc.On("Start", s.user, importer.Source{Format: importer.READWISE}, "Highlight\nQuote 1\n", false).Return(importer.Job{ID: 7}, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(READWISE_IMPORT_ENDPOINT, FILE_FIELD, "Highlight\nQuote 1\n"))
+	s.Assert().Equal(http.StatusAccepted, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestCSVReadsMapping() {
+	content := "Quote,Title\nQuote 1,Book1\n"
+	source := importer.Source{Format: importer.CSV, Mapping: importer.Mapping{Text: "Quote", Book: "Title"}}
+	result := importer.Result{Quotes: []importer.ParsedQuote{{Line: 2, Book: "Book1", Text: "Quote 1"}}, Warnings: []importer.Warning{}}
+	s.uc.On("Preview", s.user, source, content, false).Return(result, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newFormRequest(CSV_IMPORT_ENDPOINT+"?dry_run=true", FILE_FIELD, content, map[string]string{"text": "Quote", "book": "Title"}))
+
+	var actual importer.Result
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(result.Quotes, actual.Quotes)
+}
+
+func (s *ImporterTestSuite) TestCSVUnknownColumn() {
+	s.uc.On("Start", s.user, mock.Anything, mock.Anything, false).Return(importer.Job{}, exceptions.UnknownCSVColumn)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newFormRequest(CSV_IMPORT_ENDPOINT, FILE_FIELD, "Quote\n", map[string]string{"text": "Text"}))
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.UnknownCSVColumn.Error(), m.Message)
+}
+
+func (s *ImporterTestSuite) TestImportWithUpdate() {
+	s.uc.On("Start", s.user, importer.Source{Format: importer.MARKDOWN}, "- Quote 1\n", true).Return(importer.Job{ID: 7}, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT+"?update=true", FILE_FIELD, "- Quote 1\n"))
+	s.Assert().Equal(http.StatusAccepted, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestGetJob() {
+	job := importer.Job{ID: 7, Format: importer.MARKDOWN, Status: importer.JOB_RUNNING, Total: 10, Processed: 4, Inserted: 4, Warnings: []importer.Warning{{Line: 3, Message: "oops"}}}
+	s.uc.On("Job", s.user, int64(7)).Return(job, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := http.NewRequest(http.MethodGet, IMPORTS_ENDPOINT+"/7", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual importer.Job
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(job, actual)
+}
+
+func (s *ImporterTestSuite) TestGetJobNotExists() {
+	s.uc.On("Job", s.user, int64(7)).Return(importer.Job{}, exceptions.ImportJobNotExists)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := http.NewRequest(http.MethodGet, IMPORTS_ENDPOINT+"/7", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Error
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+	s.Assert().Equal("import_job_not_exists", m.Code)
+}
+
+func (s *ImporterTestSuite) TestGetJobInvalidID() {
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := http.NewRequest(http.MethodGet, IMPORTS_ENDPOINT+"/abc", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestCancelJob() {
+	s.uc.On("Cancel", s.user, int64(7)).Return(importer.Job{ID: 7, Status: importer.JOB_CANCELED}, nil)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := http.NewRequest(http.MethodPost, IMPORTS_ENDPOINT+"/7/cancel", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual importer.Job
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(importer.JOB_CANCELED, actual.Status)
+}
+
+func (s *ImporterTestSuite) TestCancelFinishedJob() {
+	s.uc.On("Cancel", s.user, int64(7)).Return(importer.Job{}, exceptions.ImportJobFinished)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := http.NewRequest(http.MethodPost, IMPORTS_ENDPOINT+"/7/cancel", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusConflict, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestInvalidUpdateOption() {
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT+"?update=maybe", FILE_FIELD, "- Quote 1\n"))
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestMissingFile() {
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT, "other", "- Quote 1"))
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.InvalidImportFile.Error(), m.Message)
+}
+
+func (s *ImporterTestSuite) TestRespondServerErrorWhenImportFailure() {
+	s.uc.On("Start", s.user, mock.Anything, mock.Anything, false).Return(importer.Job{}, exceptions.ServerError)
+	NewImporterHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT, FILE_FIELD, "- Quote 1"))
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+}
+
+func (s *ImporterTestSuite) TestRespondUnauthorizedWithoutUser() {
+	NewImporterHTTPHandler(s.g, s.l, s.uc)
+	s.g.ServeHTTP(s.r, newUploadRequest(MARKDOWN_IMPORT_ENDPOINT, FILE_FIELD, "- Quote 1"))
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+}
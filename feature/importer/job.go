@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"time"
+)
+
+// JOB_BATCH_SIZE is how many quotes a job saves between two progress updates. Every batch is
+// saved in a transaction of its own, a job stopped between two batches keeps the earlier ones.
+const JOB_BATCH_SIZE = 100
+
+// JOB_STALE_AFTER is how long a running job goes without progress before it counts as stopped.
+// A job runs again from the start, the quotes it already saved are skipped then.
+const JOB_STALE_AFTER = 2 * time.Minute
+
+// JOB_HEARTBEAT is how often a running job tells it still makes progress, also while it plans
+// or saves a batch.
+const JOB_HEARTBEAT = JOB_STALE_AFTER / 4
+
+func (uc *Usecase) Queued() <-chan struct{} {
+	return uc.queued
+}
+
+// wake tells a waiting worker that a job may be queued.
+func (uc *Usecase) wake() {
+	select {
+	case uc.queued <- struct{}{}:
+	default:
+	}
+}
+
+func (uc *Usecase) Recover() error {
+	count, err := uc.r.RequeueJobs(uc.now().Add(-JOB_STALE_AFTER))
+	if err != nil {
+		return exceptions.ServerError
+	}
+	if count > 0 {
+		uc.l.Infof("queued %d stopped import jobs again", count)
+		uc.wake()
+	}
+	return nil
+}
+
+func (uc *Usecase) RunNext(ctx context.Context) (bool, error) {
+	claimed, job, err := uc.r.ClaimJob()
+	if err != nil {
+		return false, exceptions.ServerError
+	}
+	if !claimed {
+		return false, nil
+	}
+	// another worker may take the next job meanwhile
+	uc.wake()
+	uc.process(ctx, job)
+	return true, nil
+}
+
+// process runs a claimed job. When ctx is done the job is left running, Recover queues it again.
+// When the worker loses the job it stops and leaves it to its new owner.
+func (uc *Usecase) process(ctx context.Context, job models.ImportJobModel) {
+	ctx, cancel := context.WithCancel(ctx)
+	beating := make(chan struct{})
+	// the heartbeat gets its own copy, the worker changes job as it goes
+	go func(job models.ImportJobModel) {
+		defer close(beating)
+		uc.heartbeat(ctx, cancel, job)
+	}(job)
+	defer func() {
+		cancel()
+		<-beating
+	}()
+
+	user := models.User{ID: job.UserID, DefaultVisibility: job.Visibility}
+	source := importer.Source{Format: importer.Format(job.Format)}
+	if job.Mapping != "" {
+		err := json.Unmarshal([]byte(job.Mapping), &source.Mapping)
+		if err != nil {
+			uc.finish(job, importer.JOB_FAILED, exceptions.InvalidCSVMapping)
+			return
+		}
+	}
+	quotes, warnings, err := uc.parse(user, source, bytes.NewReader(job.File))
+	if err != nil {
+		uc.finish(job, importer.JOB_FAILED, err)
+		return
+	}
+	p, err := uc.plan(user, quotes, job.UpdateChanged)
+	if err != nil {
+		uc.stop(ctx, job)
+		return
+	}
+	b, err := json.Marshal(warnings)
+	if err == nil && len(warnings) > 0 {
+		job.Warnings = string(b)
+	}
+	job.Total, job.Skipped, job.Inserted, job.Updated = len(p.created)+len(p.updated), p.skipped, 0, 0
+	if !uc.update(ctx, job) {
+		return
+	}
+
+	for start := 0; start < job.Total; start += JOB_BATCH_SIZE {
+		if ctx.Err() != nil {
+			return
+		}
+		canceled, err := uc.r.IsCanceled(job.ID)
+		if err != nil {
+			uc.stop(ctx, job)
+			return
+		}
+		if canceled {
+			uc.finish(job, importer.JOB_CANCELED, nil)
+			return
+		}
+		created, updated := p.batch(start, start+JOB_BATCH_SIZE)
+		var bookAuthors map[string]string
+		if start+JOB_BATCH_SIZE >= job.Total {
+			// the books of every quote exist by the last batch
+			bookAuthors = authors(quotes)
+		}
+		err = uc.r.Save(created, updated, bookAuthors)
+		if err != nil {
+			uc.stop(ctx, job)
+			return
+		}
+		job.Inserted += len(created)
+		job.Updated += len(updated)
+		if !uc.update(ctx, job) {
+			return
+		}
+	}
+	if !uc.finish(job, importer.JOB_DONE, nil) {
+		return
+	}
+	uc.l.Infof("user %d imported quotes, %d inserted, %d updated, %d skipped", job.UserID, job.Inserted, job.Updated, job.Skipped)
+}
+
+// heartbeat touches the job every uc.beat until ctx is done, so Recover does not queue it again
+// while the worker is busy. It cancels the work when the worker lost the job.
+func (uc *Usecase) heartbeat(ctx context.Context, cancel context.CancelFunc, job models.ImportJobModel) {
+	ticker := time.NewTicker(uc.beat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		owned, err := uc.r.TouchJob(job, uc.now())
+		if err != nil {
+			uc.l.Errorf("touch import job error, job id: %d\n The error message: %s", job.ID, err.Error())
+			continue
+		}
+		if !owned {
+			uc.l.Infof("import job %d was taken over, its old worker stops", job.ID)
+			cancel()
+			return
+		}
+	}
+}
+
+// update saves the progress of the job, false when the worker has to stop.
+func (uc *Usecase) update(ctx context.Context, job models.ImportJobModel) bool {
+	owned, err := uc.save(job)
+	if err != nil {
+		uc.stop(ctx, job)
+		return false
+	}
+	return owned
+}
+
+// save writes the job, false when the worker lost it.
+func (uc *Usecase) save(job models.ImportJobModel) (bool, error) {
+	owned, err := uc.r.UpdateJob(job)
+	if err == nil && !owned {
+		uc.l.Infof("import job %d was taken over, its old worker stops", job.ID)
+	}
+	return owned, err
+}
+
+// stop handles a failed database call, it fails the job unless the server is stopping.
+func (uc *Usecase) stop(ctx context.Context, job models.ImportJobModel) {
+	if ctx.Err() != nil {
+		return
+	}
+	uc.l.Errorf("import job %d of user %d failed on a database error", job.ID, job.UserID)
+	uc.finish(job, importer.JOB_FAILED, exceptions.ServerError)
+}
+
+// finish records the end of a job and drops its file, false when it could not.
+func (uc *Usecase) finish(job models.ImportJobModel, status importer.JobStatus, cause error) bool {
+	now := uc.now()
+	job.Status, job.FinishedAt, job.File = string(status), &now, nil
+	if cause != nil {
+		job.Error = cause.Error()
+	}
+	owned, err := uc.save(job)
+	if err != nil {
+		uc.l.Errorf("finish import job error, job id: %d\n The error message: %s", job.ID, err.Error())
+	}
+	return owned
+}
+
+// batch returns the quotes from start to end of the created ones followed by the updated ones.
+func (p plan) batch(start int, end int) ([]models.QuoteModel, []models.QuoteModel) {
+	split := func(quotes []models.QuoteModel, start int, end int) []models.QuoteModel {
+		start, end = clamp(start, len(quotes)), clamp(end, len(quotes))
+		return quotes[start:end]
+	}
+	created := split(p.created, start, end)
+	updated := split(p.updated, start-len(p.created), end-len(p.created))
+	return created, updated
+}
+
+func clamp(i int, max int) int {
+	if i < 0 {
+		return 0
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
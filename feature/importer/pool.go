@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"context"
+	"myquote/domain"
+	"myquote/domain/importer"
+	"sync"
+	"time"
+)
+
+// Pool runs import jobs on a fixed number of workers. The jobs wait in the database, so none
+// is lost when the server stops, and every interval the stopped ones are queued again.
+type Pool struct {
+	l        domain.Logger
+	w        importer.Worker
+	size     int
+	interval time.Duration
+}
+
+func NewPool(logger domain.Logger, worker importer.Worker, size int, interval time.Duration) *Pool {
+	return &Pool{l: logger, w: worker, size: size, interval: interval}
+}
+
+// Run blocks until ctx is done and every worker finished its batch.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		err := p.w.Recover()
+		if err != nil {
+			p.l.Errorf("recover import jobs error: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// work runs jobs until none is queued, then waits for the next one.
+func (p *Pool) work(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			ran, err := p.w.RunNext(ctx)
+			if err != nil {
+				p.l.Errorf("run import job error: %s", err.Error())
+			}
+			if !ran {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.w.Queued():
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/service/quotelink"
+	"time"
+)
+
+const BATCH_SIZE = 100
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the import jobs table. It needs the quotes table.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.ImportJobModel{})
+	if err != nil {
+		r.l.Errorf("migrate import jobs table error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Hashes looks the hashes up in batches, databases limit the parameters of a query.
+func (r *Repository) Hashes(userID int64, hashes []string) (map[string]bool, error) {
+	found := map[string]bool{}
+	for start := 0; start < len(hashes); start += BATCH_SIZE {
+		end := start + BATCH_SIZE
+		if end > len(hashes) {
+			end = len(hashes)
+		}
+		var existing []string
+		err := r.db.Model(&models.QuoteModel{}).
+			Where("user_id = ? AND content_hash IN ?", userID, hashes[start:end]).
+			Distinct().Pluck("content_hash", &existing).Error
+		if err != nil {
+			r.l.Debugf("find content hashes error, user id: %d\n The error message: %s", userID, err.Error())
+			return nil, err
+		}
+		for _, h := range existing {
+			found[h] = true
+		}
+	}
+	return found, nil
+}
+
+func (r *Repository) FindChapter(userID int64, book string, chapter string) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.Where("user_id = ? AND book = ? AND chapter = ?", userID, book, chapter).Order("id").Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("find chapter quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+// Save runs in one transaction, either every quote passed is saved or none. A job saves its
+// quotes in batches of JOB_BATCH_SIZE, so a job that is canceled or fails keeps the batches
+// saved before. An author never overwrites the one a book already has.
+func (r *Repository) Save(created []models.QuoteModel, updated []models.QuoteModel, authors map[string]string) error {
+	var userID int64
+	if len(created) > 0 {
+		userID = created[0].UserID
+	} else if len(updated) > 0 {
+		userID = updated[0].UserID
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		link := quotelink.New(tx)
+		for i := range created {
+			err := link.Book(&created[i])
+			if err != nil {
+				return err
+			}
+		}
+		if len(created) > 0 {
+			err := tx.CreateInBatches(&created, BATCH_SIZE).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, q := range created {
+			err := link.Tags(q, "")
+			if err != nil {
+				return err
+			}
+		}
+		// an update only changes the text, the tags stay linked
+		for i := range updated {
+			err := link.Book(&updated[i])
+			if err != nil {
+				return err
+			}
+			err = tx.Save(&updated[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		// the quotes of an import belong to one user
+		for title, author := range authors {
+			err := tx.Model(&models.BookModel{}).
+				Where("user_id = ? AND title = ? AND author = ?", userID, title, "").
+				Update("author", author).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.l.Debugf("import quotes error, user id: %d\n The error message: %s", userID, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) CreateJob(job models.ImportJobModel) (models.ImportJobModel, error) {
+	result := r.db.Create(&job)
+	if result.Error != nil {
+		r.l.Debugf("create import job error, user id: %d\n The error message: %s", job.UserID, result.Error.Error())
+		return models.ImportJobModel{}, result.Error
+	}
+	return job, nil
+}
+
+func (r *Repository) FindJob(userID int64, id int64) (bool, models.ImportJobModel, error) {
+	var job models.ImportJobModel
+	result := r.db.Omit("file").Where("id = ? AND user_id = ?", id, userID).First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.ImportJobModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find import job error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return false, models.ImportJobModel{}, result.Error
+	}
+	return true, job, nil
+}
+
+// ClaimJob retries when another worker claims the same job first.
+func (r *Repository) ClaimJob() (bool, models.ImportJobModel, error) {
+	for {
+		var job models.ImportJobModel
+		result := r.db.Where("status = ?", importer.JOB_QUEUED).Order("id").Limit(1).Find(&job)
+		if result.Error != nil {
+			r.l.Debugf("find queued import job error: %s", result.Error.Error())
+			return false, models.ImportJobModel{}, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, models.ImportJobModel{}, nil
+		}
+		claim := r.db.Model(&models.ImportJobModel{}).Where("id = ? AND status = ?", job.ID, importer.JOB_QUEUED).
+			Updates(map[string]interface{}{"status": importer.JOB_RUNNING, "attempt": gorm.Expr("attempt + 1")})
+		if claim.Error != nil {
+			r.l.Debugf("claim import job error, job id: %d\n The error message: %s", job.ID, claim.Error.Error())
+			return false, models.ImportJobModel{}, claim.Error
+		}
+		if claim.RowsAffected == 1 {
+			job.Status = string(importer.JOB_RUNNING)
+			job.Attempt++
+			return true, job, nil
+		}
+	}
+}
+
+// UpdateJob writes the file only when the job finished, to drop it.
+func (r *Repository) UpdateJob(job models.ImportJobModel) (bool, error) {
+	columns := []string{"status", "total", "inserted", "updated", "skipped", "warnings", "error", "finished_at", "updated_at"}
+	if job.FinishedAt != nil {
+		columns = append(columns, "file")
+	}
+	result := r.db.Model(&job).Scopes(owned(job)).Select(columns).Updates(&job)
+	if result.Error != nil {
+		r.l.Debugf("update import job error, job id: %d\n The error message: %s", job.ID, result.Error.Error())
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *Repository) TouchJob(job models.ImportJobModel, now time.Time) (bool, error) {
+	result := r.db.Model(&models.ImportJobModel{}).Where("id = ?", job.ID).Scopes(owned(job)).Update("updated_at", now)
+	if result.Error != nil {
+		r.l.Debugf("touch import job error, job id: %d\n The error message: %s", job.ID, result.Error.Error())
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// owned keeps the job while it runs the attempt the worker claimed.
+func owned(job models.ImportJobModel) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND attempt = ?", importer.JOB_RUNNING, job.Attempt)
+	}
+}
+
+func (r *Repository) CancelJob(userID int64, id int64, now time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ImportJobModel{}).Where("id = ? AND user_id = ? AND status = ?", id, userID, importer.JOB_QUEUED).
+			Updates(map[string]interface{}{"status": importer.JOB_CANCELED, "file": nil, "finished_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.ImportJobModel{}).Where("id = ? AND user_id = ? AND status = ?", id, userID, importer.JOB_RUNNING).
+			Update("canceled", true).Error
+	})
+	if err != nil {
+		r.l.Debugf("cancel import job error, user id: %d\n The error message: %s", userID, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) IsCanceled(id int64) (bool, error) {
+	var canceled []bool
+	result := r.db.Model(&models.ImportJobModel{}).Where("id = ?", id).Pluck("canceled", &canceled)
+	if result.Error != nil {
+		r.l.Debugf("find import job error, job id: %d\n The error message: %s", id, result.Error.Error())
+		return false, result.Error
+	}
+	return len(canceled) == 1 && canceled[0], nil
+}
+
+func (r *Repository) RequeueJobs(before time.Time) (int64, error) {
+	result := r.db.Model(&models.ImportJobModel{}).Where("status = ? AND updated_at < ?", importer.JOB_RUNNING, before).
+		Update("status", importer.JOB_QUEUED)
+	if result.Error != nil {
+		r.l.Debugf("requeue import jobs error: %s", result.Error.Error())
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
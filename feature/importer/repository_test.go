@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type ImporterRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+func TestImporterRepository(t *testing.T) {
+	suite.Run(t, new(ImporterRepositoryTestSuite))
+}
+
+func (s *ImporterRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.QuoteModel{}, &models.BookModel{}, &models.ChapterModel{}, &models.TagModel{}, &models.QuoteTagModel{}, &models.ImportJobModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+}
+
+func (s *ImporterRepositoryTestSuite) TestSave() {
+	err := s.repo.Save([]models.QuoteModel{
+		{UserID: 1, Book: "Book1", Text: "Quote 1"},
+		{UserID: 1, Book: "Book1", Text: "Quote 2"},
+	}, nil, nil)
+	s.Assert().Nil(err)
+
+	var count int64
+	s.db.Model(&models.QuoteModel{}).Count(&count)
+	s.Assert().Equal(int64(2), count)
+}
+
+func (s *ImporterRepositoryTestSuite) TestSaveRollsBackOnFailure() {
+	s.db.Create(&models.QuoteModel{ID: 2, UserID: 1, Text: "existing"})
+	err := s.repo.Save([]models.QuoteModel{
+		{ID: 1, UserID: 1, Text: "Quote 1"},
+		{ID: 2, UserID: 1, Text: "Quote 2"},
+	}, nil, nil)
+	s.Assert().NotNil(err)
+
+	var count int64
+	s.db.Model(&models.QuoteModel{}).Count(&count)
+	s.Assert().Equal(int64(1), count)
+}
+
+func (s *ImporterRepositoryTestSuite) TestSaveFillsMissingAuthors() {
+	s.db.Create(&models.BookModel{UserID: 1, Title: "Book1", Author: "Known"})
+	s.db.Create(&models.BookModel{UserID: 2, Title: "Book2"})
+	err := s.repo.Save([]models.QuoteModel{
+		{UserID: 1, Book: "Book1", Text: "Quote 1"},
+		{UserID: 1, Book: "Book2", Text: "Quote 2"},
+	}, nil, map[string]string{"Book1": "Author1", "Book2": "Author2"})
+	s.Assert().Nil(err)
+
+	var books []models.BookModel
+	s.db.Order("id").Find(&books)
+	s.Require().Len(books, 3)
+	s.Assert().Equal("Known", books[0].Author)
+	s.Assert().Equal("", books[1].Author)
+	s.Assert().Equal(int64(1), books[2].UserID)
+	s.Assert().Equal("Author2", books[2].Author)
+}
+
+func (s *ImporterRepositoryTestSuite) TestSaveKeepsCreatedAt() {
+	added := time.Date(2021, 5, 13, 4, 33, 0, 0, time.UTC)
+	err := s.repo.Save([]models.QuoteModel{{UserID: 1, Text: "Quote 1", CreatedAt: added}}, nil, nil)
+	s.Assert().Nil(err)
+
+	var q models.QuoteModel
+	s.db.First(&q)
+	s.Assert().True(added.Equal(q.CreatedAt))
+	s.Assert().True(q.UpdatedAt.After(added))
+}
+
+func (s *ImporterRepositoryTestSuite) TestSaveUpdatesQuotes() {
+	s.Require().Nil(s.repo.Save([]models.QuoteModel{{UserID: 1, Book: "Book1", Text: "Quote 1"}}, nil, nil))
+	quotes, err := s.repo.FindChapter(1, "Book1", "")
+	s.Require().Nil(err)
+	s.Require().Len(quotes, 1)
+
+	quotes[0].Text = "Quote 1, edited"
+	err = s.repo.Save(nil, quotes, nil)
+	s.Assert().Nil(err)
+
+	var q models.QuoteModel
+	s.db.First(&q, quotes[0].ID)
+	s.Assert().Equal("Quote 1, edited", q.Text)
+	s.Assert().Equal(models.ContentHash("quote 1,  EDITED"), q.ContentHash)
+}
+
+func (s *ImporterRepositoryTestSuite) TestHashes() {
+	s.Require().Nil(s.repo.Save([]models.QuoteModel{
+		{UserID: 1, Text: "Quote 1"},
+		{UserID: 1, Text: "Quote 1"},
+		{UserID: 2, Text: "Quote 2"},
+	}, nil, nil))
+	hashes := []string{models.ContentHash("Quote 1"), models.ContentHash("Quote 2")}
+	for i := 0; i < BATCH_SIZE; i++ {
+		hashes = append(hashes, models.ContentHash(fmt.Sprintf("missing %d", i)))
+	}
+
+	found, err := s.repo.Hashes(1, hashes)
+	s.Assert().Nil(err)
+	s.Assert().Equal(map[string]bool{models.ContentHash("Quote 1"): true}, found)
+}
+
+func (s *ImporterRepositoryTestSuite) TestFindChapterInCreationOrder() {
+	s.Require().Nil(s.repo.Save([]models.QuoteModel{
+		{UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 1"},
+		{UserID: 1, Book: "Book1", Chapter: "Chapter 2", Text: "Quote 2"},
+		{UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 3"},
+		{UserID: 2, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 4"},
+	}, nil, nil))
+
+	quotes, err := s.repo.FindChapter(1, "Book1", "Chapter 1")
+	s.Assert().Nil(err)
+	s.Require().Len(quotes, 2)
+	s.Assert().Equal("Quote 1", quotes[0].Text)
+	s.Assert().Equal("Quote 3", quotes[1].Text)
+}
+
+func (s *ImporterRepositoryTestSuite) queue(userID int64) models.ImportJobModel {
+	job, err := s.repo.CreateJob(models.ImportJobModel{UserID: userID, Format: "markdown", File: []byte("- Quote 1\n"), Status: string(importer.JOB_QUEUED)})
+	s.Require().Nil(err)
+	return job
+}
+
+func (s *ImporterRepositoryTestSuite) TestFindJobOfUser() {
+	job := s.queue(1)
+
+	exists, found, err := s.repo.FindJob(1, job.ID)
+	s.Assert().Nil(err)
+	s.Assert().True(exists)
+	s.Assert().Equal("queued", found.Status)
+	s.Assert().Nil(found.File)
+
+	exists, _, err = s.repo.FindJob(2, job.ID)
+	s.Assert().Nil(err)
+	s.Assert().False(exists)
+}
+
+func (s *ImporterRepositoryTestSuite) TestClaimJobsInOrder() {
+	first := s.queue(1)
+	second := s.queue(2)
+
+	claimed, job, err := s.repo.ClaimJob()
+	s.Assert().Nil(err)
+	s.Assert().True(claimed)
+	s.Assert().Equal(first.ID, job.ID)
+	s.Assert().Equal("running", job.Status)
+	s.Assert().Equal([]byte("- Quote 1\n"), job.File)
+
+	_, job, _ = s.repo.ClaimJob()
+	s.Assert().Equal(second.ID, job.ID)
+
+	claimed, _, err = s.repo.ClaimJob()
+	s.Assert().Nil(err)
+	s.Assert().False(claimed)
+}
+
+func (s *ImporterRepositoryTestSuite) TestUpdateJobKeepsCancelRequest() {
+	s.queue(1)
+	_, job, _ := s.repo.ClaimJob()
+	s.Require().Nil(s.repo.CancelJob(1, job.ID, time.Now()))
+
+	job.Total, job.Inserted = 10, 5
+	owned, err := s.repo.UpdateJob(job)
+	s.Assert().Nil(err)
+	s.Assert().True(owned)
+
+	canceled, err := s.repo.IsCanceled(job.ID)
+	s.Assert().Nil(err)
+	s.Assert().True(canceled)
+	var saved models.ImportJobModel
+	s.db.First(&saved, job.ID)
+	s.Assert().Equal("running", saved.Status)
+	s.Assert().Equal(5, saved.Inserted)
+	s.Assert().NotNil(saved.File)
+}
+
+func (s *ImporterRepositoryTestSuite) TestUpdateFinishedJobDropsFile() {
+	s.queue(1)
+	_, job, _ := s.repo.ClaimJob()
+	now := time.Now()
+	job.Status, job.FinishedAt, job.File = "done", &now, nil
+	owned, err := s.repo.UpdateJob(job)
+	s.Assert().Nil(err)
+	s.Assert().True(owned)
+
+	var saved models.ImportJobModel
+	s.db.First(&saved, job.ID)
+	s.Assert().Equal("done", saved.Status)
+	s.Assert().NotNil(saved.FinishedAt)
+	s.Assert().Nil(saved.File)
+}
+
+func (s *ImporterRepositoryTestSuite) TestUpdateJobOfAnotherWorker() {
+	s.queue(1)
+	_, old, _ := s.repo.ClaimJob()
+	s.Require().Equal(1, old.Attempt)
+	_, err := s.repo.RequeueJobs(time.Now().Add(time.Minute))
+	s.Require().Nil(err)
+
+	owned, err := s.repo.TouchJob(old, time.Now())
+	s.Assert().Nil(err)
+	s.Assert().False(owned)
+
+	_, job, _ := s.repo.ClaimJob()
+	s.Assert().Equal(2, job.Attempt)
+	old.Inserted = 5
+	owned, err = s.repo.UpdateJob(old)
+	s.Assert().Nil(err)
+	s.Assert().False(owned)
+	owned, err = s.repo.TouchJob(job, time.Now())
+	s.Assert().Nil(err)
+	s.Assert().True(owned)
+
+	var saved models.ImportJobModel
+	s.db.First(&saved, job.ID)
+	s.Assert().Equal("running", saved.Status)
+	s.Assert().Zero(saved.Inserted)
+}
+
+func (s *ImporterRepositoryTestSuite) TestCancelQueuedJob() {
+	job := s.queue(1)
+	s.Assert().Nil(s.repo.CancelJob(2, job.ID, time.Now()))
+	_, found, _ := s.repo.FindJob(1, job.ID)
+	s.Assert().Equal("queued", found.Status)
+
+	s.Assert().Nil(s.repo.CancelJob(1, job.ID, time.Now()))
+	var saved models.ImportJobModel
+	s.db.First(&saved, job.ID)
+	s.Assert().Equal("canceled", saved.Status)
+	s.Assert().NotNil(saved.FinishedAt)
+	s.Assert().Nil(saved.File)
+
+	claimed, _, _ := s.repo.ClaimJob()
+	s.Assert().False(claimed)
+}
+
+func (s *ImporterRepositoryTestSuite) TestRequeueStaleJobs() {
+	s.queue(1)
+	s.queue(1)
+	_, stale, _ := s.repo.ClaimJob()
+	_, fresh, _ := s.repo.ClaimJob()
+	s.db.Model(&models.ImportJobModel{}).Where("id = ?", stale.ID).UpdateColumn("updated_at", time.Now().Add(-time.Hour))
+
+	count, err := s.repo.RequeueJobs(time.Now().Add(-time.Minute))
+	s.Assert().Nil(err)
+	s.Assert().Equal(int64(1), count)
+
+	_, job, _ := s.repo.ClaimJob()
+	s.Assert().Equal(stale.ID, job.ID)
+	_, job, _ = s.repo.FindJob(1, fresh.ID)
+	s.Assert().Equal("running", job.Status)
+}
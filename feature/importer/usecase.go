@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"myquote/domain"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"time"
+)
+
+type Usecase struct {
+	l       domain.Logger
+	r       importer.Repository
+	parsers importer.Parsers
+	queued  chan struct{}
+	beat    time.Duration
+	now     func() time.Time
+}
+
+func NewUsecase(logger domain.Logger, repository importer.Repository, parsers importer.Parsers) *Usecase {
+	return &Usecase{l: logger, r: repository, parsers: parsers, queued: make(chan struct{}, 1), beat: JOB_HEARTBEAT, now: time.Now}
+}
+
+func (uc *Usecase) Preview(user models.User, source importer.Source, r io.Reader, update bool) (importer.Result, error) {
+	quotes, warnings, err := uc.parse(user, source, r)
+	if err != nil {
+		return importer.Result{}, err
+	}
+	result := importer.Result{Quotes: quotes, Warnings: warnings}
+	if result.Warnings == nil {
+		result.Warnings = []importer.Warning{}
+	}
+	plan, err := uc.plan(user, quotes, update)
+	if err != nil {
+		return importer.Result{}, exceptions.ServerError
+	}
+	result.Inserted, result.Updated, result.Skipped = len(plan.created), len(plan.updated), plan.skipped
+	return result, nil
+}
+
+// Start parses the file once to reject it right away when it cannot be imported, the worker
+// parses it again.
+func (uc *Usecase) Start(user models.User, source importer.Source, file []byte, update bool) (importer.Job, error) {
+	_, _, err := uc.parse(user, source, bytes.NewReader(file))
+	if err != nil {
+		return importer.Job{}, err
+	}
+	job := models.ImportJobModel{
+		UserID:        user.ID,
+		Format:        string(source.Format),
+		UpdateChanged: update,
+		Visibility:    string(quote.ResolveVisibility("", user.DefaultVisibility)),
+		File:          file,
+		Status:        string(importer.JOB_QUEUED),
+	}
+	if source.Format == importer.CSV {
+		b, err := json.Marshal(source.Mapping)
+		if err != nil {
+			return importer.Job{}, exceptions.ServerError
+		}
+		job.Mapping = string(b)
+	}
+	created, err := uc.r.CreateJob(job)
+	if err != nil {
+		return importer.Job{}, exceptions.ServerError
+	}
+	uc.wake()
+	uc.l.Infof("user %d queued import job %d", user.ID, created.ID)
+	return toJob(created), nil
+}
+
+func (uc *Usecase) Job(user models.User, id int64) (importer.Job, error) {
+	exists, job, err := uc.r.FindJob(user.ID, id)
+	if err != nil {
+		return importer.Job{}, exceptions.ServerError
+	}
+	if !exists {
+		return importer.Job{}, exceptions.ImportJobNotExists
+	}
+	return toJob(job), nil
+}
+
+// Cancel stops a queued job right away, a running job stops before its next batch. The
+// quotes a running job saved before stay.
+func (uc *Usecase) Cancel(user models.User, id int64) (importer.Job, error) {
+	job, err := uc.Job(user, id)
+	if err != nil {
+		return importer.Job{}, err
+	}
+	if job.Status.Finished() {
+		return importer.Job{}, exceptions.ImportJobFinished
+	}
+	err = uc.r.CancelJob(user.ID, id, uc.now())
+	if err != nil {
+		return importer.Job{}, exceptions.ServerError
+	}
+	uc.l.Infof("user %d canceled import job %d", user.ID, id)
+	return uc.Job(user, id)
+}
+
+// parse reads the file with the parser of its format, the error is the one the user sees.
+func (uc *Usecase) parse(user models.User, source importer.Source, r io.Reader) ([]importer.ParsedQuote, []importer.Warning, error) {
+	var p importer.Parser
+	switch source.Format {
+	case importer.MARKDOWN:
+		p = uc.parsers.Markdown
+	case importer.KINDLE:
+		p = uc.parsers.Kindle
+	case importer.READWISE:
+		p = uc.parsers.Readwise
+	case importer.CSV:
+		p = uc.parsers.CSV(source.Mapping)
+	default:
+		return nil, nil, exceptions.InvalidInput
+	}
+	quotes, warnings, err := p.Parse(r)
+	if errors.Is(err, exceptions.InvalidCSVMapping) || errors.Is(err, exceptions.UnknownCSVColumn) {
+		return nil, nil, err
+	}
+	if err != nil {
+		uc.l.Debugf("parse import file error, user id: %d\n The error message: %s", user.ID, err.Error())
+		return nil, nil, exceptions.InvalidImportFile
+	}
+	if len(quotes) == 0 {
+		return nil, nil, exceptions.NothingToImport
+	}
+	return quotes, warnings, nil
+}
+
+// plan is what an import changes.
+type plan struct {
+	created []models.QuoteModel
+	updated []models.QuoteModel
+	skipped int
+}
+
+// chapterKey names a chapter of a book, the position of a quote counts inside it.
+type chapterKey struct {
+	book    string
+	chapter string
+}
+
+// plan skips the quotes the user already has, also when the file repeats one. With update
+// a new text replaces the quote at the same position of its chapter, unless that quote is
+// still in the file and only moved.
+func (uc *Usecase) plan(user models.User, quotes []importer.ParsedQuote, update bool) (plan, error) {
+	hashes := make([]string, len(quotes))
+	inFile := map[string]bool{}
+	for i, q := range quotes {
+		hashes[i] = models.ContentHash(q.Text)
+		inFile[hashes[i]] = true
+	}
+	existing, err := uc.r.Hashes(user.ID, hashes)
+	if err != nil {
+		return plan{}, err
+	}
+
+	var p plan
+	seen := map[string]bool{}
+	positions := map[chapterKey]int{}
+	chapters := map[chapterKey][]models.QuoteModel{}
+	for i, q := range quotes {
+		key := chapterKey{book: q.Book, chapter: q.Chapter}
+		position := positions[key]
+		positions[key]++
+		if existing[hashes[i]] || seen[hashes[i]] {
+			p.skipped++
+			continue
+		}
+		seen[hashes[i]] = true
+
+		if update {
+			saved, ok := chapters[key]
+			if !ok {
+				saved, err = uc.r.FindChapter(user.ID, q.Book, q.Chapter)
+				if err != nil {
+					return plan{}, err
+				}
+				chapters[key] = saved
+			}
+			if position < len(saved) && !inFile[saved[position].ContentHash] {
+				changed := saved[position]
+				changed.Text = q.Text
+				p.updated = append(p.updated, changed)
+				continue
+			}
+		}
+		p.created = append(p.created, toModel(user, q))
+	}
+	return p, nil
+}
+
+// authors maps the books of the quotes to the author the file names.
+func authors(quotes []importer.ParsedQuote) map[string]string {
+	authors := map[string]string{}
+	for _, q := range quotes {
+		if q.Book != "" && q.Author != "" {
+			authors[q.Book] = q.Author
+		}
+	}
+	return authors
+}
+
+func toJob(m models.ImportJobModel) importer.Job {
+	warnings := []importer.Warning{}
+	if m.Warnings != "" {
+		// the warnings are written by the worker, a broken value is left out
+		_ = json.Unmarshal([]byte(m.Warnings), &warnings)
+	}
+	return importer.Job{
+		ID:         m.ID,
+		Format:     importer.Format(m.Format),
+		Status:     importer.JobStatus(m.Status),
+		Total:      m.Total,
+		Processed:  m.Inserted + m.Updated,
+		Inserted:   m.Inserted,
+		Updated:    m.Updated,
+		Skipped:    m.Skipped,
+		Warnings:   warnings,
+		Error:      m.Error,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		FinishedAt: m.FinishedAt,
+	}
+}
+
+func toModel(user models.User, q importer.ParsedQuote) models.QuoteModel {
+	m := models.QuoteModel{
+		UserID:     user.ID,
+		Text:       q.Text,
+		Book:       q.Book,
+		Chapter:    q.Chapter,
+		Page:       q.Page,
+		Tags:       models.JoinTags(q.Tags),
+		Visibility: string(quote.ResolveVisibility("", user.DefaultVisibility)),
+	}
+	if q.Date != nil {
+		m.CreatedAt = *q.Date
+	}
+	return m
+}
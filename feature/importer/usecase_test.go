@@ -0,0 +1,516 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"myquote/domain/models"
+	"myquote/service/csvfile"
+	"myquote/service/kindle"
+	"myquote/service/logger"
+	"myquote/service/markdown"
+	"strings"
+	"testing"
+	"time"
+)
+
+type MockedImporterRepo struct {
+	mock.Mock
+}
+
+func (m *MockedImporterRepo) Hashes(userID int64, hashes []string) (map[string]bool, error) {
+	args := m.Called(userID, hashes)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockedImporterRepo) FindChapter(userID int64, book string, chapter string) ([]models.QuoteModel, error) {
+	args := m.Called(userID, book, chapter)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedImporterRepo) Save(created []models.QuoteModel, updated []models.QuoteModel, authors map[string]string) error {
+	args := m.Called(created, updated, authors)
+	return args.Error(0)
+}
+
+func (m *MockedImporterRepo) CreateJob(job models.ImportJobModel) (models.ImportJobModel, error) {
+	args := m.Called(job)
+	return args.Get(0).(models.ImportJobModel), args.Error(1)
+}
+
+func (m *MockedImporterRepo) FindJob(userID int64, id int64) (bool, models.ImportJobModel, error) {
+	args := m.Called(userID, id)
+	return args.Bool(0), args.Get(1).(models.ImportJobModel), args.Error(2)
+}
+
+func (m *MockedImporterRepo) ClaimJob() (bool, models.ImportJobModel, error) {
+	args := m.Called()
+	return args.Bool(0), args.Get(1).(models.ImportJobModel), args.Error(2)
+}
+
+func (m *MockedImporterRepo) UpdateJob(job models.ImportJobModel) (bool, error) {
+	args := m.Called(job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockedImporterRepo) TouchJob(job models.ImportJobModel, now time.Time) (bool, error) {
+	args := m.Called(job, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockedImporterRepo) CancelJob(userID int64, id int64, now time.Time) error {
+	args := m.Called(userID, id, now)
+	return args.Error(0)
+}
+
+func (m *MockedImporterRepo) IsCanceled(id int64) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockedImporterRepo) RequeueJobs(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type ImporterUsecaseTestSuite struct {
+	suite.Suite
+	uc   *Usecase
+	repo *MockedImporterRepo
+	user models.User
+	now  time.Time
+}
+
+func TestNewImporterUsecase(t *testing.T) {
+	suite.Run(t, new(ImporterUsecaseTestSuite))
+}
+
+func (s *ImporterUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedImporterRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, importer.Parsers{
+		Markdown: markdown.NewParser(),
+		Kindle:   kindle.NewParser(),
+		Readwise: csvfile.NewReadwiseParser(),
+		CSV:      func(m importer.Mapping) importer.Parser { return csvfile.NewParser(m) },
+	})
+	s.now = time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	s.uc.now = func() time.Time { return s.now }
+	s.user = models.User{ID: 1}
+}
+
+// existing makes the repository report the hashes of texts as saved.
+func (s *ImporterUsecaseTestSuite) existing(texts ...string) {
+	hashes := map[string]bool{}
+	for _, t := range texts {
+		hashes[models.ContentHash(t)] = true
+	}
+	s.repo.On("Hashes", int64(1), mock.Anything).Return(hashes, nil)
+}
+
+// claim makes the repository hand out a running job importing file, the worker may save and
+// update it.
+func (s *ImporterUsecaseTestSuite) claim(format importer.Format, file string, update bool) {
+	job := models.ImportJobModel{ID: 7, UserID: 1, Format: string(format), UpdateChanged: update, Visibility: "followers", File: []byte(file), Status: string(importer.JOB_RUNNING)}
+	s.claimJob(job)
+}
+
+func (s *ImporterUsecaseTestSuite) claimJob(job models.ImportJobModel) {
+	s.repo.On("ClaimJob").Return(true, job, nil)
+	s.repo.On("UpdateJob", mock.Anything).Return(true, nil)
+	s.repo.On("IsCanceled", job.ID).Return(false, nil)
+}
+
+// run runs the claimed job and returns it as the worker updated it last.
+func (s *ImporterUsecaseTestSuite) run() models.ImportJobModel {
+	ran, err := s.uc.RunNext(context.Background())
+	s.Require().Nil(err)
+	s.Require().True(ran)
+	return s.lastUpdate()
+}
+
+func (s *ImporterUsecaseTestSuite) lastUpdate() models.ImportJobModel {
+	var job models.ImportJobModel
+	for _, c := range s.repo.Calls {
+		if c.Method == "UpdateJob" {
+			job = c.Arguments.Get(0).(models.ImportJobModel)
+		}
+	}
+	return job
+}
+
+func (s *ImporterUsecaseTestSuite) TestPreviewDoesNotSave() {
+	s.existing()
+	file := "## Book1\n### Chapter 1\n- Quote 1\noops\n"
+	result, err := s.uc.Preview(s.user, importer.Source{Format: importer.MARKDOWN}, strings.NewReader(file), false)
+
+	s.Assert().Nil(err)
+	s.Assert().Len(result.Quotes, 1)
+	s.Assert().Len(result.Warnings, 1)
+	s.Assert().Equal(4, result.Warnings[0].Line)
+	s.Assert().Equal(1, result.Inserted)
+	s.repo.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything, mock.Anything)
+	s.repo.AssertNotCalled(s.T(), "CreateJob", mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestPreviewCountsKnownQuotes() {
+	s.existing("Quote 1")
+	result, err := s.uc.Preview(s.user, importer.Source{Format: importer.MARKDOWN}, strings.NewReader("- Quote 1\n- Quote 2\n"), false)
+
+	s.Assert().Nil(err)
+	s.Assert().Equal(1, result.Inserted)
+	s.Assert().Equal(1, result.Skipped)
+}
+
+func (s *ImporterUsecaseTestSuite) TestPreviewNothing() {
+	_, err := s.uc.Preview(s.user, importer.Source{Format: importer.MARKDOWN}, strings.NewReader("## Book1\n"), false)
+	s.Assert().Equal(exceptions.NothingToImport, err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestPreviewUnknownFormat() {
+	_, err := s.uc.Preview(s.user, importer.Source{Format: "docx"}, strings.NewReader("- Quote 1\n"), false)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestCSVMappingErrors() {
+	_, err := s.uc.Preview(s.user, importer.Source{Format: importer.CSV, Mapping: importer.Mapping{Book: "Title"}}, strings.NewReader("Quote\nQuote 1\n"), false)
+	s.Assert().Equal(exceptions.InvalidCSVMapping, err)
+
+	_, err = s.uc.Preview(s.user, importer.Source{Format: importer.CSV, Mapping: importer.Mapping{Text: "Text"}}, strings.NewReader("Quote\nQuote 1\n"), false)
+	s.Assert().ErrorIs(err, exceptions.UnknownCSVColumn)
+}
+
+func (s *ImporterUsecaseTestSuite) TestHashesThrowServerError() {
+	s.repo.On("Hashes", int64(1), mock.Anything).Return(map[string]bool{}, errors.New("database is gone"))
+	_, err := s.uc.Preview(s.user, importer.Source{Format: importer.MARKDOWN}, strings.NewReader("- Quote 1\n"), false)
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestStartQueuesJob() {
+	s.user.DefaultVisibility = "private"
+	file := []byte("- Quote 1\n")
+	s.repo.On("CreateJob", models.ImportJobModel{
+		UserID: 1, Format: "markdown", UpdateChanged: true, Visibility: "private", File: file, Status: "queued",
+	}).Return(models.ImportJobModel{ID: 7, Format: "markdown", Status: "queued"}, nil)
+	job, err := s.uc.Start(s.user, importer.Source{Format: importer.MARKDOWN}, file, true)
+
+	s.Assert().Nil(err)
+	s.Assert().Equal(int64(7), job.ID)
+	s.Assert().Equal(importer.JOB_QUEUED, job.Status)
+	s.Assert().Equal([]importer.Warning{}, job.Warnings)
+	s.Assert().Len(s.uc.Queued(), 1)
+	s.repo.AssertNotCalled(s.T(), "Hashes", mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestStartKeepsCSVMapping() {
+	s.repo.On("CreateJob", mock.MatchedBy(func(job models.ImportJobModel) bool {
+		return job.Mapping == `{"text":"Quote","book":"Title","author":"","chapter":"","page":"","tags":"","date":"","note":""}`
+	})).Return(models.ImportJobModel{ID: 7}, nil)
+	_, err := s.uc.Start(s.user, importer.Source{Format: importer.CSV, Mapping: importer.Mapping{Text: "Quote", Book: "Title"}}, []byte("Quote,Title\nQuote 1,Book1\n"), false)
+	s.Assert().Nil(err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestStartRejectsFileRightAway() {
+	_, err := s.uc.Start(s.user, importer.Source{Format: importer.MARKDOWN}, []byte("## Book1\n"), false)
+	s.Assert().Equal(exceptions.NothingToImport, err)
+	s.repo.AssertNotCalled(s.T(), "CreateJob", mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestStartThrowServerError() {
+	s.repo.On("CreateJob", mock.Anything).Return(models.ImportJobModel{}, errors.New("database is gone"))
+	_, err := s.uc.Start(s.user, importer.Source{Format: importer.MARKDOWN}, []byte("- Quote 1\n"), false)
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestJob() {
+	s.repo.On("FindJob", int64(1), int64(7)).Return(true, models.ImportJobModel{
+		ID: 7, Status: "running", Total: 10, Inserted: 3, Updated: 2, Warnings: `[{"line":4,"message":"oops"}]`,
+	}, nil)
+	job, err := s.uc.Job(s.user, 7)
+
+	s.Assert().Nil(err)
+	s.Assert().Equal(importer.JOB_RUNNING, job.Status)
+	s.Assert().Equal(5, job.Processed)
+	s.Assert().Equal([]importer.Warning{{Line: 4, Message: "oops"}}, job.Warnings)
+}
+
+func (s *ImporterUsecaseTestSuite) TestJobNotExists() {
+	s.repo.On("FindJob", int64(1), int64(7)).Return(false, models.ImportJobModel{}, nil)
+	_, err := s.uc.Job(s.user, 7)
+	s.Assert().Equal(exceptions.ImportJobNotExists, err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestCancel() {
+	s.repo.On("FindJob", int64(1), int64(7)).Return(true, models.ImportJobModel{ID: 7, Status: "queued"}, nil).Once()
+	s.repo.On("CancelJob", int64(1), int64(7), s.now).Return(nil)
+	s.repo.On("FindJob", int64(1), int64(7)).Return(true, models.ImportJobModel{ID: 7, Status: "canceled"}, nil)
+	job, err := s.uc.Cancel(s.user, 7)
+
+	s.Assert().Nil(err)
+	s.Assert().Equal(importer.JOB_CANCELED, job.Status)
+}
+
+func (s *ImporterUsecaseTestSuite) TestCancelFinishedJob() {
+	s.repo.On("FindJob", int64(1), int64(7)).Return(true, models.ImportJobModel{ID: 7, Status: "done"}, nil)
+	_, err := s.uc.Cancel(s.user, 7)
+
+	s.Assert().Equal(exceptions.ImportJobFinished, err)
+	s.repo.AssertNotCalled(s.T(), "CancelJob", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunNextWithoutJob() {
+	s.repo.On("ClaimJob").Return(false, models.ImportJobModel{}, nil)
+	ran, err := s.uc.RunNext(context.Background())
+
+	s.Assert().Nil(err)
+	s.Assert().False(ran)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunSavesAllQuotes() {
+	s.existing()
+	s.claim(importer.MARKDOWN, "## Book1\n### Chapter 1\n- Quote 1\n- Quote 2\n", false)
+	s.repo.On("Save", []models.QuoteModel{
+		{UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 1", Visibility: "followers"},
+		{UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 2", Visibility: "followers"},
+	}, []models.QuoteModel(nil), map[string]string{}).Return(nil)
+	job := s.run()
+
+	s.Assert().Equal("done", job.Status)
+	s.Assert().Equal(2, job.Total)
+	s.Assert().Equal(2, job.Inserted)
+	s.Assert().Equal(&s.now, job.FinishedAt)
+	s.Assert().Nil(job.File)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunUsesJobVisibility() {
+	s.existing()
+	s.claimJob(models.ImportJobModel{ID: 7, UserID: 1, Format: "markdown", Visibility: "private", File: []byte("- Quote 1\n")})
+	s.repo.On("Save", []models.QuoteModel{{UserID: 1, Text: "Quote 1", Visibility: "private"}}, []models.QuoteModel(nil), map[string]string{}).Return(nil)
+	job := s.run()
+	s.Assert().Equal("done", job.Status)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunSavesInBatches() {
+	s.existing()
+	var file strings.Builder
+	for i := 0; i < JOB_BATCH_SIZE*2+50; i++ {
+		fmt.Fprintf(&file, "- Quote %d\n", i)
+	}
+	s.claim(importer.MARKDOWN, file.String(), false)
+	s.repo.On("Save", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	job := s.run()
+
+	var saves []mock.Arguments
+	for _, c := range s.repo.Calls {
+		if c.Method == "Save" {
+			saves = append(saves, c.Arguments)
+		}
+	}
+	s.Require().Len(saves, 3)
+	s.Assert().Len(saves[0].Get(0), JOB_BATCH_SIZE)
+	s.Assert().Len(saves[2].Get(0), 50)
+	s.Assert().Nil(saves[0].Get(2).(map[string]string))
+	s.Assert().NotNil(saves[2].Get(2).(map[string]string))
+	s.Assert().Equal(JOB_BATCH_SIZE*2+50, job.Inserted)
+	s.Assert().Equal("done", job.Status)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunKindleSavesPagesNotesAndAuthors() {
+	s.existing()
+	file := "Book1 (Author1)\n- Your Highlight on page 3 | Location 10-12 | Added on Monday, May 2, 2022 8:00:00 AM\n\nQuote 1\n==========\n" +
+		"Book1 (Author1)\n- Your Note on page 3 | Location 12 | Added on Monday, May 2, 2022 8:01:00 AM\n\nMy note\n==========\n"
+	s.claim(importer.KINDLE, file, false)
+	s.repo.On("Save", []models.QuoteModel{
+		{UserID: 1, Book: "Book1", Page: 3, Text: "Quote 1", Visibility: "followers"},
+		{UserID: 1, Book: "Book1", Page: 3, Text: "My note", Tags: "note", Visibility: "followers"},
+	}, []models.QuoteModel(nil), map[string]string{"Book1": "Author1"}).Return(nil)
+	job := s.run()
+
+	s.Assert().Equal(2, job.Inserted)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunCSVKeepsDatesAndWarnings() {
+	s.existing()
+	file := "Quote,Title,Added\nQuote 1,Book1,2021-05-13\n,Book1,2021-05-14\nQuote 3,Book1,yesterday\n"
+	s.claimJob(models.ImportJobModel{ID: 7, UserID: 1, Format: "csv", Mapping: `{"text":"Quote","book":"Title","date":"Added"}`, File: []byte(file)})
+	added := time.Date(2021, 5, 13, 0, 0, 0, 0, time.UTC)
+	s.repo.On("Save", []models.QuoteModel{
+		{UserID: 1, Book: "Book1", Text: "Quote 1", Visibility: "followers", CreatedAt: added},
+	}, []models.QuoteModel(nil), map[string]string{}).Return(nil)
+	job := s.run()
+
+	s.Assert().Equal(1, job.Inserted)
+	s.Assert().Equal([]importer.Warning{
+		{Line: 3, Message: "row has no text"},
+		{Line: 4, Message: `invalid date "yesterday"`},
+	}, toJob(job).Warnings)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunSkipsKnownAndRepeatedQuotes() {
+	s.existing("Quote 1")
+	s.claim(importer.MARKDOWN, "## Book1\n- quote   1\n- Quote 2\n- Quote 2\n", false)
+	s.repo.On("Save", []models.QuoteModel{
+		{UserID: 1, Book: "Book1", Text: "Quote 2", Visibility: "followers"},
+	}, []models.QuoteModel(nil), map[string]string{}).Return(nil)
+	job := s.run()
+
+	s.Assert().Equal(1, job.Total)
+	s.Assert().Equal(1, job.Inserted)
+	s.Assert().Equal(2, job.Skipped)
+	s.repo.AssertNotCalled(s.T(), "FindChapter", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunNothingNewSavesNothing() {
+	s.existing("Quote 1")
+	s.claim(importer.MARKDOWN, "- Quote 1\n", false)
+	job := s.run()
+
+	s.Assert().Equal("done", job.Status)
+	s.Assert().Equal(1, job.Skipped)
+	s.repo.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunUpdateReplacesChangedQuotesByPosition() {
+	saved := []models.QuoteModel{
+		{ID: 1, UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 1", ContentHash: models.ContentHash("Quote 1")},
+		{ID: 2, UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 2", ContentHash: models.ContentHash("Quote 2")},
+		{ID: 3, UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 3", ContentHash: models.ContentHash("Quote 3")},
+	}
+	s.existing("Quote 1", "Quote 2", "Quote 3")
+	s.repo.On("FindChapter", int64(1), "Book1", "Chapter 1").Return(saved, nil)
+	// Quote 2 is edited, Quote 3 moves behind a new quote, which must not replace it
+	s.claim(importer.MARKDOWN, "## Book1\n### Chapter 1\n- Quote 1\n- Quote 2, edited\n- Quote 2b\n- Quote 3\n", true)
+	changed := saved[1]
+	changed.Text = "Quote 2, edited"
+	s.repo.On("Save", []models.QuoteModel{
+		{UserID: 1, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 2b", Visibility: "followers"},
+	}, []models.QuoteModel{changed}, map[string]string{}).Return(nil)
+	job := s.run()
+
+	s.Assert().Equal(1, job.Inserted)
+	s.Assert().Equal(1, job.Updated)
+	s.Assert().Equal(2, job.Skipped)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunStopsCanceledJob() {
+	s.existing()
+	s.repo.On("IsCanceled", int64(7)).Return(true, nil)
+	s.claim(importer.MARKDOWN, "- Quote 1\n", false)
+	job := s.run()
+
+	s.Assert().Equal("canceled", job.Status)
+	s.Assert().Nil(job.File)
+	s.repo.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunCanceledMidwayKeepsSavedBatches() {
+	s.existing()
+	var file strings.Builder
+	for i := 0; i < JOB_BATCH_SIZE*2+50; i++ {
+		fmt.Fprintf(&file, "- Quote %d\n", i)
+	}
+	// the user cancels while the first batch is saved
+	s.repo.On("IsCanceled", int64(7)).Return(false, nil).Once()
+	s.repo.On("IsCanceled", int64(7)).Return(true, nil)
+	s.claim(importer.MARKDOWN, file.String(), false)
+	s.repo.On("Save", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	job := s.run()
+
+	s.repo.AssertNumberOfCalls(s.T(), "Save", 1)
+	var saved mock.Call
+	for _, c := range s.repo.Calls {
+		if c.Method == "Save" {
+			saved = c
+		}
+	}
+	s.Assert().Len(saved.Arguments.Get(0), JOB_BATCH_SIZE)
+	s.Assert().Equal("Quote 0", saved.Arguments.Get(0).([]models.QuoteModel)[0].Text)
+	s.Assert().Equal("canceled", job.Status)
+	s.Assert().Equal(JOB_BATCH_SIZE*2+50, job.Total)
+	s.Assert().Equal(JOB_BATCH_SIZE, job.Inserted)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunFailsInvalidFile() {
+	s.claim(importer.MARKDOWN, "## Book1\n", false)
+	job := s.run()
+
+	s.Assert().Equal("failed", job.Status)
+	s.Assert().Equal(exceptions.NothingToImport.Error(), job.Error)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunFailsOnServerError() {
+	s.existing()
+	s.claim(importer.MARKDOWN, "- Quote 1\n", false)
+	s.repo.On("Save", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("database is gone"))
+	job := s.run()
+
+	s.Assert().Equal("failed", job.Status)
+	s.Assert().Equal(exceptions.ServerError.Error(), job.Error)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunLeavesJobRunningWhenStopping() {
+	s.existing()
+	s.claim(importer.MARKDOWN, "- Quote 1\n", false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran, err := s.uc.RunNext(ctx)
+
+	s.Assert().Nil(err)
+	s.Assert().True(ran)
+	s.Assert().Equal("running", s.lastUpdate().Status)
+	s.repo.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunStopsWhenJobWasTakenOver() {
+	s.existing()
+	s.repo.On("ClaimJob").Return(true, models.ImportJobModel{ID: 7, UserID: 1, Format: "markdown", File: []byte("- Quote 1\n"), Status: "running"}, nil)
+	s.repo.On("UpdateJob", mock.Anything).Return(false, nil)
+	ran, err := s.uc.RunNext(context.Background())
+
+	s.Assert().Nil(err)
+	s.Assert().True(ran)
+	s.repo.AssertNumberOfCalls(s.T(), "UpdateJob", 1)
+	s.repo.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunTouchesJobWhilePlanning() {
+	s.uc.beat = time.Millisecond
+	s.repo.On("Hashes", int64(1), mock.Anything).Return(map[string]bool{}, nil).After(20 * time.Millisecond)
+	s.claim(importer.MARKDOWN, "- Quote 1\n", false)
+	s.repo.On("TouchJob", mock.Anything, s.now).Return(true, nil)
+	s.repo.On("Save", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	job := s.run()
+
+	s.Assert().Equal("done", job.Status)
+	s.repo.AssertCalled(s.T(), "TouchJob", mock.Anything, s.now)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunStopsWhenHeartbeatFindsJobTakenOver() {
+	s.uc.beat = time.Millisecond
+	s.repo.On("Hashes", int64(1), mock.Anything).Return(map[string]bool{}, nil).After(20 * time.Millisecond)
+	s.claim(importer.MARKDOWN, "- Quote 1\n", false)
+	s.repo.On("TouchJob", mock.Anything, s.now).Return(false, nil)
+	ran, err := s.uc.RunNext(context.Background())
+
+	s.Assert().Nil(err)
+	s.Assert().True(ran)
+	s.Assert().Equal("running", s.lastUpdate().Status)
+	s.repo.AssertNotCalled(s.T(), "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRunNextThrowServerError() {
+	s.repo.On("ClaimJob").Return(false, models.ImportJobModel{}, errors.New("database is gone"))
+	_, err := s.uc.RunNext(context.Background())
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *ImporterUsecaseTestSuite) TestRecoverQueuesStaleJobs() {
+	s.repo.On("RequeueJobs", s.now.Add(-JOB_STALE_AFTER)).Return(int64(1), nil)
+	err := s.uc.Recover()
+
+	s.Assert().Nil(err)
+	s.Assert().Len(s.uc.Queued(), 1)
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"net/http"
+)
+
+// NewErrorMiddleware renders the last error a handler added with c.Error as a common.Error.
+// An exceptions.Error sets the status and the code, any other error is a server error. It
+// has to run before the handlers, so it is registered on the engine.
+func NewErrorMiddleware(l domain.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		errs := c.Errors.ByType(gin.ErrorTypePrivate)
+		if len(errs) == 0 {
+			return
+		}
+		err := errs.Last().Err
+		var e *exceptions.Error
+		if !errors.As(err, &e) {
+			e = exceptions.ServerError.Wrap(err)
+			err = e
+		}
+		if e.Status >= http.StatusInternalServerError {
+			l.Errorf("request error, path: %s\n The error message: %s", c.FullPath(), cause(err))
+		}
+		c.JSON(e.Status, common.Error{Message: common.Message{Message: err.Error()}, Code: e.Code})
+	}
+}
+
+// cause returns the innermost error, the one that tells what went wrong.
+func cause(err error) string {
+	for errors.Unwrap(err) != nil {
+		err = errors.Unwrap(err)
+	}
+	return err.Error()
+}
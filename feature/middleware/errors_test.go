@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const FAILING_ENDPOINT = "/failing"
+
+type ErrorMiddlewareTestSuite struct {
+	suite.Suite
+	g *gin.Engine
+	r *httptest.ResponseRecorder
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	suite.Run(t, new(ErrorMiddlewareTestSuite))
+}
+
+func (s *ErrorMiddlewareTestSuite) SetupTest() {
+	s.g = gin.Default()
+	s.g.Use(NewErrorMiddleware(logger.NewLogger("")))
+	s.r = httptest.NewRecorder()
+}
+
+// serve answers a request with a handler that fails with err.
+func (s *ErrorMiddlewareTestSuite) serve(err error) common.Error {
+	s.g.GET(FAILING_ENDPOINT, func(c *gin.Context) {
+		c.Error(err)
+	})
+	req, _ := http.NewRequest(http.MethodGet, FAILING_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var body common.Error
+	json.Unmarshal(s.r.Body.Bytes(), &body)
+	return body
+}
+
+func (s *ErrorMiddlewareTestSuite) TestRenderError() {
+	body := s.serve(exceptions.QuoteNotExists)
+
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+	s.Assert().Equal("quote_not_exists", body.Code)
+	s.Assert().Equal(exceptions.QuoteNotExists.Error(), body.Message.Message)
+}
+
+func (s *ErrorMiddlewareTestSuite) TestRenderWrappedErrorMessage() {
+	body := s.serve(fmt.Errorf("%w: %s", exceptions.UnknownCSVColumn, "Quote"))
+
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal("unknown_csv_column", body.Code)
+	s.Assert().Equal(exceptions.UnknownCSVColumn.Error()+": Quote", body.Message.Message)
+}
+
+func (s *ErrorMiddlewareTestSuite) TestHideCause() {
+	body := s.serve(exceptions.ServerError.Wrap(errors.New("database is gone")))
+
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+	s.Assert().Equal("server_error", body.Code)
+	s.Assert().Equal(exceptions.ServerError.Error(), body.Message.Message)
+}
+
+func (s *ErrorMiddlewareTestSuite) TestRenderUnknownErrorAsServerError() {
+	body := s.serve(errors.New("database is gone"))
+
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+	s.Assert().Equal("server_error", body.Code)
+	s.Assert().Equal(exceptions.ServerError.Error(), body.Message.Message)
+}
+
+func (s *ErrorMiddlewareTestSuite) TestKeepResponseWithoutError() {
+	s.g.GET(FAILING_ENDPOINT, func(c *gin.Context) {
+		c.JSON(http.StatusOK, common.Message{Message: "ok"})
+	})
+	req, _ := http.NewRequest(http.MethodGet, FAILING_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().JSONEq(`{"message":"ok"}`, s.r.Body.String())
+}
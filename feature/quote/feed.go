@@ -0,0 +1,55 @@
+package quote
+
+import (
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+)
+
+// Feed returns a page of the quotes of the users the user follows, newest first by default.
+func (uc *Usecase) Feed(user models.User, req quote.PageRequest) (common.Page[models.Quote], error) {
+	p, err := page(req)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	found, err := uc.r.Feed(user.ID, p)
+	if err != nil {
+		return common.Page[models.Quote]{}, exceptions.ServerError
+	}
+	quotes, err := uc.attribute(user, found)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	return paginate(quotes, p), nil
+}
+
+// attribute converts the quotes and adds the owner to the ones user did not write.
+func (uc *Usecase) attribute(user models.User, found []models.QuoteModel) ([]models.Quote, error) {
+	var ids []int64
+	seen := map[int64]bool{}
+	for _, m := range found {
+		if m.UserID != user.ID && !seen[m.UserID] {
+			seen[m.UserID] = true
+			ids = append(ids, m.UserID)
+		}
+	}
+	owners := map[int64]models.UserModel{}
+	if len(ids) > 0 {
+		var err error
+		owners, err = uc.r.Owners(ids)
+		if err != nil {
+			return nil, exceptions.ServerError
+		}
+	}
+
+	quotes := make([]models.Quote, 0, len(found))
+	for _, m := range found {
+		q := m.ToQuote()
+		if owner, ok := owners[m.UserID]; ok {
+			q.Owner = &models.Profile{ID: owner.ID, Name: owner.Name}
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, nil
+}
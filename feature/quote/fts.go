@@ -0,0 +1,93 @@
+package quote
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/models"
+	"strings"
+)
+
+// FTSRepository searches with the sqlite FTS5 extension. The quotes_fts table indexes the text,
+// book and tags of the quotes table, triggers on quotes keep it up to date.
+type FTSRepository struct {
+	l      domain.Logger
+	db     *gorm.DB
+	quotes *Repository
+}
+
+func NewFTSRepository(logger domain.Logger, db *gorm.DB) *FTSRepository {
+	return &FTSRepository{l: logger, db: db, quotes: NewRepository(logger, db)}
+}
+
+var ftsSchema = []string{
+	"CREATE VIRTUAL TABLE IF NOT EXISTS quotes_fts USING fts5(text, book, tags, content='quotes', content_rowid='id', tokenize='unicode61 remove_diacritics 0')",
+	`CREATE TRIGGER IF NOT EXISTS quotes_fts_insert AFTER INSERT ON quotes BEGIN
+		INSERT INTO quotes_fts (rowid, text, book, tags) VALUES (new.id, new.text, new.book, new.tags);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS quotes_fts_delete AFTER DELETE ON quotes BEGIN
+		INSERT INTO quotes_fts (quotes_fts, rowid, text, book, tags) VALUES ('delete', old.id, old.text, old.book, old.tags);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS quotes_fts_update AFTER UPDATE OF text, book, tags ON quotes BEGIN
+		INSERT INTO quotes_fts (quotes_fts, rowid, text, book, tags) VALUES ('delete', old.id, old.text, old.book, old.tags);
+		INSERT INTO quotes_fts (rowid, text, book, tags) VALUES (new.id, new.text, new.book, new.tags);
+	END`,
+}
+
+// Migrate creates the search index and its triggers, it needs the quotes table. The index is
+// rebuilt whenever any of them was missing, which also covers quotes saved before search existed.
+func (r *FTSRepository) Migrate() error {
+	var existing int64
+	err := r.db.Raw(
+		"SELECT COUNT(*) FROM sqlite_master WHERE name IN ?",
+		[]string{"quotes_fts", "quotes_fts_insert", "quotes_fts_delete", "quotes_fts_update"},
+	).Scan(&existing).Error
+	if err != nil {
+		r.l.Errorf("migrate quotes search error: %s", err.Error())
+		return err
+	}
+	if int(existing) == len(ftsSchema) {
+		return nil
+	}
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range ftsSchema {
+			err := tx.Exec(stmt).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Exec("INSERT INTO quotes_fts (quotes_fts) VALUES ('rebuild')").Error
+	})
+	if err != nil {
+		r.l.Errorf("migrate quotes search error: %s", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *FTSRepository) Search(viewerID int64, terms []string, limit int) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.Model(&models.QuoteModel{}).
+		Joins("JOIN quotes_fts ON quotes_fts.rowid = quotes.id").
+		Where("quotes_fts MATCH ?", ftsQuery(terms)).
+		Scopes(r.quotes.visibleTo(viewerID)).
+		Order(fmt.Sprintf("bm25(quotes_fts, %g, %g, %g), quotes.id desc", TEXT_WEIGHT, BOOK_WEIGHT, TAGS_WEIGHT)).
+		Limit(limit).
+		Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("search quotes error, viewer id: %d\n The error message: %s", viewerID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+// ftsQuery matches every term as a prefix, like "habit"* "life"*. Quoting keeps FTS5 from reading
+// a term as an operator such as NOT.
+func ftsQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		parts = append(parts, `"`+t+`"*`)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,145 @@
+package quote
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+)
+
+// SearchRepositoryTestSuite runs the same searches against every search backend.
+type SearchRepositoryTestSuite struct {
+	suite.Suite
+	db     *gorm.DB
+	repo   *Repository
+	search quote.SearchRepository
+	open   func(db *gorm.DB) (quote.SearchRepository, error)
+}
+
+func TestFTSRepository(t *testing.T) {
+	suite.Run(t, &SearchRepositoryTestSuite{open: func(db *gorm.DB) (quote.SearchRepository, error) {
+		r := NewFTSRepository(logger.NewLogger(""), db)
+		return r, r.Migrate()
+	}})
+}
+
+func TestLikeRepository(t *testing.T) {
+	suite.Run(t, &SearchRepositoryTestSuite{open: func(db *gorm.DB) (quote.SearchRepository, error) {
+		return NewLikeRepository(logger.NewLogger(""), db), nil
+	}})
+}
+
+func (s *SearchRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.UserModel{}, &models.FollowModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+	s.search, err = s.open(db)
+	s.Require().NoError(err)
+}
+
+func (s *SearchRepositoryTestSuite) create(q models.QuoteModel) models.QuoteModel {
+	if q.Visibility == "" {
+		q.Visibility = string(quote.PRIVATE)
+	}
+	created, err := s.repo.Create(q)
+	s.Require().NoError(err)
+	return created
+}
+
+func (s *SearchRepositoryTestSuite) TestMatchesTextBookAndTags() {
+	text := s.create(models.QuoteModel{UserID: 1, Text: "Habits compound"})
+	book := s.create(models.QuoteModel{UserID: 1, Text: "Quote", Book: "Atomic Habits"})
+	tag := s.create(models.QuoteModel{UserID: 1, Text: "Quote", Tags: "life,habit"})
+	s.create(models.QuoteModel{UserID: 1, Text: "Rehabilitation"})
+
+	quotes, err := s.search.Search(1, []string{"habit"}, 10)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{text.ID, book.ID, tag.ID}, modelIDs(quotes))
+}
+
+func (s *SearchRepositoryTestSuite) TestMatchesAllTerms() {
+	both := s.create(models.QuoteModel{UserID: 1, Text: "Small habits", Book: "Atomic Habits"})
+	s.create(models.QuoteModel{UserID: 1, Text: "Small steps"})
+
+	quotes, err := s.search.Search(1, []string{"small", "atomic"}, 10)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{both.ID}, modelIDs(quotes))
+}
+
+func (s *SearchRepositoryTestSuite) TestBestMatchAmongManyNewerOnes() {
+	best := s.create(models.QuoteModel{UserID: 1, Text: "Habit after habit", Book: "Habits"})
+	newer := make([]models.QuoteModel, 0, MAX_LIKE_CANDIDATES)
+	for i := 0; i < MAX_LIKE_CANDIDATES; i++ {
+		newer = append(newer, models.QuoteModel{UserID: 1, Text: fmt.Sprintf("Quote %d", i), Tags: "habit", Visibility: string(quote.PRIVATE)})
+	}
+	s.Require().NoError(s.db.CreateInBatches(&newer, 100).Error)
+
+	quotes, err := s.search.Search(1, []string{"habit"}, 1)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{best.ID}, modelIDs(quotes))
+}
+
+func (s *SearchRepositoryTestSuite) TestLimit() {
+	s.create(models.QuoteModel{UserID: 1, Text: "habit 1"})
+	s.create(models.QuoteModel{UserID: 1, Text: "habit 2"})
+	s.create(models.QuoteModel{UserID: 1, Text: "habit 3"})
+
+	quotes, err := s.search.Search(1, []string{"habit"}, 2)
+	s.Assert().Nil(err)
+	s.Assert().Len(quotes, 2)
+}
+
+func (s *SearchRepositoryTestSuite) TestOnlyFindsVisibleQuotes() {
+	public := s.create(models.QuoteModel{UserID: 2, Text: "public habit", Visibility: string(quote.PUBLIC)})
+	followers := s.create(models.QuoteModel{UserID: 2, Text: "followers habit", Visibility: string(quote.FOLLOWERS)})
+	s.create(models.QuoteModel{UserID: 2, Text: "private habit"})
+
+	quotes, err := s.search.Search(1, []string{"habit"}, 10)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{public.ID}, modelIDs(quotes))
+
+	s.db.Create(&models.FollowModel{FollowerID: 1, FolloweeID: 2, Status: string(friend.ACCEPTED)})
+	quotes, err = s.search.Search(1, []string{"habit"}, 10)
+	s.Assert().Nil(err)
+	s.Assert().ElementsMatch([]int64{public.ID, followers.ID}, modelIDs(quotes))
+}
+
+func (s *SearchRepositoryTestSuite) TestFollowsChanges() {
+	q := s.create(models.QuoteModel{UserID: 1, Text: "habit"})
+	q.Text = "routine"
+	s.Require().NoError(s.repo.Update(q))
+
+	quotes, _ := s.search.Search(1, []string{"habit"}, 10)
+	s.Assert().Empty(quotes)
+	quotes, _ = s.search.Search(1, []string{"routine"}, 10)
+	s.Assert().Equal([]int64{q.ID}, modelIDs(quotes))
+
+	s.Require().NoError(s.repo.Delete(q.ID))
+	quotes, _ = s.search.Search(1, []string{"routine"}, 10)
+	s.Assert().Empty(quotes)
+}
+
+func (s *SearchRepositoryTestSuite) TestFindsQuotesSavedBeforeSearch() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.UserModel{}, &models.FollowModel{}))
+	repo := NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(repo.Migrate())
+	old, _ := repo.Create(models.QuoteModel{UserID: 1, Text: "old habit"})
+
+	search, err := s.open(db)
+	s.Require().NoError(err)
+	search, err = s.open(db)
+	s.Require().NoError(err)
+	quotes, err := search.Search(1, []string{"habit"}, 10)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{old.ID}, modelIDs(quotes))
+}
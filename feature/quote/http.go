@@ -0,0 +1,338 @@
+package quote
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/quote"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type handler struct {
+	logger  domain.Logger
+	quoteUc quote.Usecase
+}
+
+const QUOTES_ENDPOINT = "/api/quotes"
+const FEED_ENDPOINT = "/api/feed"
+const DEFAULT_PAGE_SIZE = 20
+const USER_QUOTES_ENDPOINT = "/api/users/:id/quotes"
+const DEFAULT_SEARCH_LIMIT = 20
+
+// NewQuoteHTTPHandler registers the quote routes.
+func NewQuoteHTTPHandler(c *gin.Engine, l domain.Logger, uc quote.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, quoteUc: uc}
+	g := c.Group(QUOTES_ENDPOINT, middlewares...)
+	g.POST("", handler.create)
+	g.GET("", handler.findAll)
+	g.GET("/random", handler.random)
+	g.GET("/search", handler.search)
+	g.GET("/due", handler.due)
+	g.GET("/:id", handler.find)
+	g.PUT("/:id", handler.update)
+	g.DELETE("/:id", handler.delete)
+	g.POST("/:id/tags", handler.addTags)
+	g.DELETE("/:id/tags", handler.removeTags)
+	g.POST("/:id/review", handler.review)
+	c.Group(FEED_ENDPOINT, middlewares...).GET("", handler.feed)
+	c.Group(USER_QUOTES_ENDPOINT, middlewares...).GET("", handler.findByUser)
+}
+
+func (h *handler) create(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	var q quote.NewQuote
+	err := c.Bind(&q)
+	if err != nil {
+		h.logger.Debugf("Convert new quote json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	created, err := h.quoteUc.Create(user, q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (h *handler) findAll(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	quotes, err := h.quoteUc.FindAll(user, tagFilter(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, quotes)
+}
+
+func (h *handler) findByUser(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	ownerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	quotes, err := h.quoteUc.FindByUser(user, ownerID, tagFilter(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, quotes)
+}
+
+func (h *handler) random(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(c.DefaultQuery("n", "1"))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	following, err := strconv.ParseBool(c.DefaultQuery("following", "false"))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	quotes, err := h.quoteUc.Random(user, n, quote.RandomOptions{Following: following, Tags: tagFilter(c)})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, quotes)
+}
+
+// search finds quotes by words, like GET /api/quotes/search?q=habit&limit=10
+func (h *handler) search(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DEFAULT_SEARCH_LIMIT)))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	results, err := h.quoteUc.Search(user, c.Query("q"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+func (h *handler) feed(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	req, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+	quotes, err := h.quoteUc.Feed(user, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, quotes)
+}
+
+func (h *handler) find(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := quoteID(c)
+	if !ok {
+		return
+	}
+	q, err := h.quoteUc.Find(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+func (h *handler) update(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := quoteID(c)
+	if !ok {
+		return
+	}
+	var q quote.NewQuote
+	err := c.Bind(&q)
+	if err != nil {
+		h.logger.Debugf("Convert quote json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	updated, err := h.quoteUc.Update(user, id, q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *handler) delete(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := quoteID(c)
+	if !ok {
+		return
+	}
+	err := h.quoteUc.Delete(user, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, common.Message{Message: "delete quote successful"})
+}
+
+func (h *handler) addTags(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := quoteID(c)
+	if !ok {
+		return
+	}
+	var u quote.TagsUpdate
+	err := c.Bind(&u)
+	if err != nil {
+		h.logger.Debugf("Convert tags json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	updated, err := h.quoteUc.AddTags(user, id, u)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// removeTags takes the tags from the query, like DELETE /api/quotes/1/tags?tags=life,habit
+func (h *handler) removeTags(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := quoteID(c)
+	if !ok {
+		return
+	}
+	updated, err := h.quoteUc.RemoveTags(user, id, quote.TagsUpdate{Tags: tagFilter(c).Tags})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *handler) review(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, ok := quoteID(c)
+	if !ok {
+		return
+	}
+	var f quote.ReviewFeedback
+	err := c.Bind(&f)
+	if err != nil {
+		h.logger.Debugf("Convert review json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	review, err := h.quoteUc.Review(user, id, f)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// due returns the quotes to review now, like GET /api/quotes/due?n=10
+func (h *handler) due(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(c.DefaultQuery("n", strconv.Itoa(DEFAULT_PAGE_SIZE)))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	quotes, err := h.quoteUc.Due(user, n)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, quotes)
+}
+
+// tagFilter reads the comma separated tags query and whether quotes should match all or any of them.
+func tagFilter(c *gin.Context) quote.TagFilter {
+	filter := quote.TagFilter{Match: quote.Match(c.Query("match"))}
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+	return filter
+}
+
+// pageRequest reads the page query, like ?limit=20&sort=book&order=asc for the first page
+// and ?limit=20&cursor=... for the next ones.
+func pageRequest(c *gin.Context) (quote.PageRequest, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DEFAULT_PAGE_SIZE)))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return quote.PageRequest{}, false
+	}
+	return quote.PageRequest{
+		Limit:  limit,
+		Cursor: c.Query("cursor"),
+		Sort:   quote.SortKey(c.Query("sort")),
+		Order:  quote.Order(c.Query("order")),
+	}, true
+}
+
+func quoteID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return 0, false
+	}
+	return id, true
+}
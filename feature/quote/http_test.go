@@ -0,0 +1,459 @@
+package quote
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedQuoteUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedQuoteUsecase) Create(user models.User, q quote.NewQuote) (models.Quote, error) {
+	args := m.Called(user, q)
+	return args.Get(0).(models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) FindAll(user models.User, filter quote.TagFilter, page quote.PageRequest) (common.Page[models.Quote], error) {
+	args := m.Called(user, filter, page)
+	return args.Get(0).(common.Page[models.Quote]), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Find(user models.User, id int64) (models.Quote, error) {
+	args := m.Called(user, id)
+	return args.Get(0).(models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Update(user models.User, id int64, q quote.NewQuote) (models.Quote, error) {
+	args := m.Called(user, id, q)
+	return args.Get(0).(models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Delete(user models.User, id int64) error {
+	args := m.Called(user, id)
+	return args.Error(0)
+}
+
+func (m *MockedQuoteUsecase) Random(user models.User, n int, options quote.RandomOptions) ([]models.Quote, error) {
+	args := m.Called(user, n, options)
+	return args.Get(0).([]models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Draw(user models.User, n int, options quote.RandomOptions) ([]models.Quote, error) {
+	args := m.Called(user, n, options)
+	return args.Get(0).([]models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Feed(user models.User, page quote.PageRequest) (common.Page[models.Quote], error) {
+	args := m.Called(user, page)
+	return args.Get(0).(common.Page[models.Quote]), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) FindByUser(user models.User, ownerID int64, filter quote.TagFilter, page quote.PageRequest) (common.Page[models.Quote], error) {
+	args := m.Called(user, ownerID, filter, page)
+	return args.Get(0).(common.Page[models.Quote]), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) AddTags(user models.User, id int64, u quote.TagsUpdate) (models.Quote, error) {
+	args := m.Called(user, id, u)
+	return args.Get(0).(models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) RemoveTags(user models.User, id int64, u quote.TagsUpdate) (models.Quote, error) {
+	args := m.Called(user, id, u)
+	return args.Get(0).(models.Quote), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Search(user models.User, query string, limit int) ([]models.SearchResult, error) {
+	args := m.Called(user, query, limit)
+	return args.Get(0).([]models.SearchResult), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Review(user models.User, id int64, f quote.ReviewFeedback) (models.Review, error) {
+	args := m.Called(user, id, f)
+	return args.Get(0).(models.Review), args.Error(1)
+}
+
+func (m *MockedQuoteUsecase) Deliver(user models.User, quotes []models.Quote, channel quote.Channel) error {
+	args := m.Called(user, quotes, channel)
+	return args.Error(0)
+}
+
+func (m *MockedQuoteUsecase) Due(user models.User, n int) ([]models.Quote, error) {
+	args := m.Called(user, n)
+	return args.Get(0).([]models.Quote), args.Error(1)
+}
+
+// defaultRequest is what the handler asks for without any page query.
+var defaultRequest = quote.PageRequest{Limit: DEFAULT_PAGE_SIZE}
+
+type QuoteTestSuite struct {
+	suite.Suite
+	uc   *MockedQuoteUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestQuoteHTTPHandler(t *testing.T) {
+	suite.Run(t, new(QuoteTestSuite))
+}
+
+func (s *QuoteTestSuite) SetupTest() {
+	s.uc = new(MockedQuoteUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1, Name: "Lester", Email: "123@gmail.com"}
+}
+
+func (s *QuoteTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *QuoteTestSuite) TestCreateSuccess() {
+	q := quote.NewQuote{Text: "Stay hungry, stay foolish.", Book: "Book1", Chapter: "Chapter 1", Tags: []string{"life"}}
+	body, _ := json.Marshal(q)
+	s.uc.On("Create", s.user, q).Return(models.Quote{ID: 1, UserID: 1, Text: q.Text, Book: q.Book}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT, body)
+	s.g.ServeHTTP(s.r, req)
+
+	var created models.Quote
+	json.Unmarshal(s.r.Body.Bytes(), &created)
+	s.Assert().Equal(http.StatusCreated, s.r.Code)
+	s.Assert().Equal(q.Text, created.Text)
+}
+
+func (s *QuoteTestSuite) TestCreateInvalidInput() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT, []byte("not json"))
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.InvalidInput.Error(), m.Message)
+}
+
+func (s *QuoteTestSuite) TestRespondUnauthorizedWithoutUser() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.Assert().Equal(exceptions.Unauthorized.Error(), m.Message)
+	s.uc.AssertNotCalled(s.T(), "FindAll", mock.Anything)
+}
+
+func (s *QuoteTestSuite) TestFindAllSuccess() {
+	quotes := []models.Quote{{ID: 1, UserID: 1, Text: "Quote 1"}, {ID: 2, UserID: 1, Text: "Quote 2"}}
+	s.uc.On("FindAll", s.user, quote.TagFilter{}, defaultRequest).Return(common.Page[models.Quote]{Items: quotes, NextCursor: "next"}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Page[models.Quote]
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Len(actual.Items, 2)
+	s.Assert().Equal("next", actual.NextCursor)
+}
+
+func (s *QuoteTestSuite) TestFindAllWithCursorAndSort() {
+	req := quote.PageRequest{Limit: 5, Cursor: "abc", Sort: quote.SORT_BOOK, Order: quote.DESC}
+	s.uc.On("FindAll", s.user, quote.TagFilter{}, req).Return(common.Page[models.Quote]{Items: []models.Quote{}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	r, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"?limit=5&cursor=abc&sort=book&order=desc", nil)
+	s.g.ServeHTTP(s.r, r)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestFindAllInvalidCursor() {
+	s.uc.On("FindAll", s.user, quote.TagFilter{}, mock.Anything).Return(common.Page[models.Quote]{}, exceptions.InvalidCursor)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"?cursor=abc", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.InvalidCursor.Error(), m.Message)
+}
+
+func (s *QuoteTestSuite) TestFindAllInvalidLimit() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"?limit=all", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "FindAll", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *QuoteTestSuite) TestFindNotExists() {
+	s.uc.On("Find", s.user, int64(3)).Return(models.Quote{}, exceptions.QuoteNotExists)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/3", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestFindInvalidID() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/abc", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestUpdateForbidden() {
+	q := quote.NewQuote{Text: "changed"}
+	body, _ := json.Marshal(q)
+	s.uc.On("Update", s.user, int64(2), q).Return(models.Quote{}, exceptions.Forbidden)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPut, QUOTES_ENDPOINT+"/2", body)
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusForbidden, s.r.Code)
+	s.Assert().Equal(exceptions.Forbidden.Error(), m.Message)
+}
+
+func (s *QuoteTestSuite) TestDeleteSuccess() {
+	s.uc.On("Delete", s.user, int64(1)).Return(nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodDelete, QUOTES_ENDPOINT+"/1", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestRespondServerErrorWhenDeleteFailure() {
+	s.uc.On("Delete", s.user, int64(1)).Return(exceptions.ServerError)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodDelete, QUOTES_ENDPOINT+"/1", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestRandomDefaultsToOneQuote() {
+	s.uc.On("Random", s.user, 1, quote.RandomOptions{}).Return([]models.Quote{{ID: 1, Text: "Quote 1"}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/random", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.Quote
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Len(actual, 1)
+}
+
+func (s *QuoteTestSuite) TestRandomWithCount() {
+	s.uc.On("Random", s.user, 3, quote.RandomOptions{}).Return([]models.Quote{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/random?n=3", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestRandomInvalidCount() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/random?n=abc", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Random", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *QuoteTestSuite) TestRandomFromFollowing() {
+	s.uc.On("Random", s.user, 2, quote.RandomOptions{Following: true}).Return([]models.Quote{{ID: 1}, {ID: 2}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/random?n=2&following=true", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestFeed() {
+	quotes := []models.Quote{{ID: 5, UserID: 2, Text: "Quote 5", Tags: []string{}, Owner: &models.Profile{ID: 2, Name: "Leslie"}}}
+	s.uc.On("Feed", s.user, quote.PageRequest{Limit: 10, Cursor: "abc"}).Return(common.Page[models.Quote]{Items: quotes}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, FEED_ENDPOINT+"?cursor=abc&limit=10", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Page[models.Quote]
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(common.Page[models.Quote]{Items: quotes}, actual)
+}
+
+func (s *QuoteTestSuite) TestFeedDefaultPage() {
+	s.uc.On("Feed", s.user, defaultRequest).Return(common.Page[models.Quote]{Items: []models.Quote{}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, FEED_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestFeedUnauthorized() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, FEED_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestFindByUser() {
+	s.uc.On("FindByUser", s.user, int64(9), quote.TagFilter{}, defaultRequest).Return(common.Page[models.Quote]{Items: []models.Quote{{ID: 2, UserID: 9, Visibility: "public"}}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, "/api/users/9/quotes", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Page[models.Quote]
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Len(actual.Items, 1)
+}
+
+func (s *QuoteTestSuite) TestCreateInvalidVisibility() {
+	s.uc.On("Create", s.user, mock.Anything).Return(models.Quote{}, exceptions.InvalidVisibility)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT, []byte(`{"text":"Quote 1","visibility":"friends"}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.InvalidVisibility.Error(), actual.Message)
+}
+
+func (s *QuoteTestSuite) TestFindAllByTags() {
+	filter := quote.TagFilter{Tags: []string{"life", "love"}, Match: quote.MATCH_ANY}
+	s.uc.On("FindAll", s.user, filter, defaultRequest).Return(common.Page[models.Quote]{Items: []models.Quote{{ID: 1, UserID: 1, Tags: []string{"life"}}}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"?tags=life,love&match=any", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual common.Page[models.Quote]
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Len(actual.Items, 1)
+}
+
+func (s *QuoteTestSuite) TestAddTags() {
+	u := quote.TagsUpdate{Tags: []string{"love"}}
+	s.uc.On("AddTags", s.user, int64(2), u).Return(models.Quote{ID: 2, UserID: 1, Tags: []string{"life", "love"}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	body, _ := json.Marshal(u)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT+"/2/tags", body)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Quote
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal([]string{"life", "love"}, actual.Tags)
+}
+
+func (s *QuoteTestSuite) TestRemoveTags() {
+	s.uc.On("RemoveTags", s.user, int64(2), quote.TagsUpdate{Tags: []string{"life", "habit"}}).Return(models.Quote{ID: 2, UserID: 1, Tags: []string{}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodDelete, QUOTES_ENDPOINT+"/2/tags?tags=life,habit", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestAddTagsForbidden() {
+	s.uc.On("AddTags", s.user, int64(2), mock.Anything).Return(models.Quote{}, exceptions.Forbidden)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT+"/2/tags", []byte(`{"tags":["love"]}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusForbidden, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestSearch() {
+	results := []models.SearchResult{{Quote: models.Quote{ID: 1, Text: "habit"}, Highlight: models.Highlight{Text: "<mark>habit</mark>"}}}
+	s.uc.On("Search", s.user, "habit loop", DEFAULT_SEARCH_LIMIT).Return(results, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/search?q=habit+loop", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.SearchResult
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(results, actual)
+}
+
+func (s *QuoteTestSuite) TestSearchInvalidLimit() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/search?q=habit&limit=ten", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Search", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *QuoteTestSuite) TestReview() {
+	s.uc.On("Review", s.user, int64(2), quote.ReviewFeedback{Feedback: quote.LOVED}).Return(models.Review{QuoteID: 2, Ease: 2.6, Interval: 1, Repetitions: 1}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT+"/2/review", []byte(`{"feedback":"loved"}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Review
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(1, actual.Interval)
+}
+
+func (s *QuoteTestSuite) TestReviewInvalidFeedback() {
+	s.uc.On("Review", s.user, int64(2), quote.ReviewFeedback{Feedback: "wow"}).Return(models.Review{}, exceptions.InvalidFeedback)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT+"/2/review", []byte(`{"feedback":"wow"}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestReviewNotExists() {
+	s.uc.On("Review", s.user, int64(2), mock.Anything).Return(models.Review{}, exceptions.QuoteNotExists)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPost, QUOTES_ENDPOINT+"/2/review", []byte(`{"feedback":"meh"}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+}
+
+func (s *QuoteTestSuite) TestDue() {
+	s.uc.On("Due", s.user, DEFAULT_PAGE_SIZE).Return([]models.Quote{{ID: 3}, {ID: 1}}, nil)
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/due", nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.Quote
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Len(actual, 2)
+}
+
+func (s *QuoteTestSuite) TestDueInvalidCount() {
+	NewQuoteHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, QUOTES_ENDPOINT+"/due?n=all", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Due", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,100 @@
+package quote
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/models"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// MAX_LIKE_CANDIDATES caps the quotes LikeRepository ranks for one search, the database picks
+// the ones that contain the terms most often.
+const MAX_LIKE_CANDIDATES = 500
+
+// LikeRepository searches with plain LIKE queries, it works on every database. The database
+// scores the matches by how often they contain the terms, Go ranks the best ones by word starts.
+type LikeRepository struct {
+	l      domain.Logger
+	db     *gorm.DB
+	quotes *Repository
+}
+
+func NewLikeRepository(logger domain.Logger, db *gorm.DB) *LikeRepository {
+	return &LikeRepository{l: logger, db: db, quotes: NewRepository(logger, db)}
+}
+
+func (r *LikeRepository) Search(viewerID int64, terms []string, limit int) ([]models.QuoteModel, error) {
+	query := r.db.Scopes(r.quotes.visibleTo(viewerID))
+	var scores []string
+	var vars []interface{}
+	for _, t := range terms {
+		// terms only hold letters and numbers, nothing in them needs escaping
+		like := "%" + t + "%"
+		query = query.Where("(LOWER(quotes.text) LIKE ? OR LOWER(quotes.book) LIKE ? OR LOWER(quotes.tags) LIKE ?)", like, like, like)
+		for _, f := range []struct {
+			column string
+			weight float64
+		}{{"quotes.text", TEXT_WEIGHT}, {"quotes.book", BOOK_WEIGHT}, {"quotes.tags", TAGS_WEIGHT}} {
+			// how often the term is in the column, from the length the column loses without it
+			scores = append(scores, fmt.Sprintf("%g * (LENGTH(LOWER(%s)) - LENGTH(REPLACE(LOWER(%s), ?, ''))) / ?", f.weight, f.column, f.column))
+			vars = append(vars, t, utf8.RuneCountInString(t))
+		}
+	}
+	var quotes []models.QuoteModel
+	result := query.
+		Select("quotes.*, "+strings.Join(scores, " + ")+" AS score", vars...).
+		Order("score desc, quotes.id desc").
+		Limit(MAX_LIKE_CANDIDATES).
+		Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("search quotes error, viewer id: %d\n The error message: %s", viewerID, result.Error.Error())
+		return nil, result.Error
+	}
+	ranked := rank(quotes, terms)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// rank orders the quotes that match every term by how often and where they match, newest first on a tie.
+// The quotes that miss a term are dropped.
+func rank(quotes []models.QuoteModel, terms []string) []models.QuoteModel {
+	type scored struct {
+		quote models.QuoteModel
+		score float64
+	}
+	var matched []scored
+	for _, q := range quotes {
+		score := 0.0
+		all := true
+		for _, t := range terms {
+			one := []string{t}
+			s := TEXT_WEIGHT*float64(len(matchSpans(q.Text, one))) +
+				BOOK_WEIGHT*float64(len(matchSpans(q.Book, one))) +
+				TAGS_WEIGHT*float64(len(matchSpans(q.Tags, one)))
+			if s == 0 {
+				all = false
+				break
+			}
+			score += s
+		}
+		if all {
+			matched = append(matched, scored{quote: q, score: score})
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].score != matched[j].score {
+			return matched[i].score > matched[j].score
+		}
+		return matched[i].quote.ID > matched[j].quote.ID
+	})
+	ranked := make([]models.QuoteModel, 0, len(matched))
+	for _, m := range matched {
+		ranked = append(ranked, m.quote)
+	}
+	return ranked
+}
@@ -0,0 +1,74 @@
+package quote
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+)
+
+const MAX_PAGE_SIZE = 100
+
+// page checks the request and turns it into the page the repository reads. A cursor carries
+// its sort, so the pages after the first only need the cursor.
+func page(req quote.PageRequest) (quote.Page, error) {
+	if req.Limit < 1 || req.Limit > MAX_PAGE_SIZE {
+		return quote.Page{}, exceptions.InvalidInput
+	}
+	// one quote more than asked tells whether there is a next page
+	p := quote.Page{Sort: req.Sort, Order: req.Order, Limit: req.Limit + 1}
+	if req.Cursor != "" {
+		c, err := decodeCursor(req.Cursor)
+		if err != nil || (p.Sort != "" && p.Sort != c.Sort) || (p.Order != "" && p.Order != c.Order) {
+			return quote.Page{}, exceptions.InvalidCursor
+		}
+		p.Sort, p.Order, p.After = c.Sort, c.Order, &c
+	}
+	if p.Sort == "" {
+		p.Sort = quote.SORT_CREATED_AT
+	}
+	if p.Order == "" {
+		p.Order = p.Sort.DefaultOrder()
+	}
+	if !p.Sort.Valid() || !p.Order.Valid() {
+		return quote.Page{}, exceptions.InvalidSort
+	}
+	return p, nil
+}
+
+// paginate drops the extra quote page read and points the next cursor at the last quote left.
+func paginate(quotes []models.Quote, p quote.Page) common.Page[models.Quote] {
+	size := p.Limit - 1
+	if len(quotes) <= size {
+		return common.Page[models.Quote]{Items: quotes}
+	}
+	quotes = quotes[:size]
+	last := quotes[size-1]
+	c := quote.Cursor{Sort: p.Sort, Order: p.Order, ID: last.ID}
+	switch p.Sort {
+	case quote.SORT_CREATED_AT:
+		c.Time = last.CreatedAt
+	case quote.SORT_UPDATED_AT:
+		c.Time = last.UpdatedAt
+	case quote.SORT_BOOK:
+		c.Book = last.Book
+	}
+	return common.Page[models.Quote]{Items: quotes, NextCursor: encodeCursor(c)}
+}
+
+func encodeCursor(c quote.Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (quote.Cursor, error) {
+	var c quote.Cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return quote.Cursor{}, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
@@ -0,0 +1,154 @@
+package quote
+
+import (
+	"math"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"sort"
+	"time"
+)
+
+const MAX_RANDOM_QUOTES = 20
+
+// MAX_WEIGHT_DAYS caps how much a quote gains from not being shown, a quote never shown gets the cap.
+const MAX_WEIGHT_DAYS = 30
+
+// Random draws n quotes and records them as delivered through options.Channel.
+func (uc *Usecase) Random(user models.User, n int, options quote.RandomOptions) ([]models.Quote, error) {
+	quotes, err := uc.Draw(user, n, options)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.Deliver(user, quotes, options.Channel)
+	if err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+// Draw picks n distinct quotes of the user, and of the users they follow when options.Following is set.
+// Quotes shown in the last uc.window draws are skipped unless there are not enough other quotes,
+// and quotes not shown for a long time are more likely picked. Nothing is recorded.
+func (uc *Usecase) Draw(user models.User, n int, options quote.RandomOptions) ([]models.Quote, error) {
+	if n < 1 || n > MAX_RANDOM_QUOTES {
+		return nil, exceptions.InvalidInput
+	}
+	filter, err := cleanFilter(options.Tags)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := uc.r.FindAll(user.ID, filter)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	if options.Following {
+		following, err := uc.r.FindFollowing(user.ID, filter)
+		if err != nil {
+			return nil, exceptions.ServerError
+		}
+		candidates = append(candidates, following...)
+	}
+	var recent []models.QuoteDrawModel
+	// a limit of 0 reads every draw, a window below 1 skips nothing instead
+	if uc.window > 0 {
+		recent, err = uc.r.RecentDraws(user.ID, uc.window)
+		if err != nil {
+			return nil, exceptions.ServerError
+		}
+	}
+	last, err := uc.r.LastDrawn(user.ID)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+
+	now := uc.now()
+	picked := uc.pick(candidates, excluded(recent), last, n, now)
+	return uc.attribute(user, picked)
+}
+
+// Deliver records a draw of every quote, so the history of the user shows where their quotes went
+// and Random skips the quotes just delivered. A due quote mailed in a digest waits like a skipped
+// one, so the next digest does not mail it again before the user had time to review it.
+func (uc *Usecase) Deliver(user models.User, quotes []models.Quote, channel quote.Channel) error {
+	if channel == "" {
+		channel = quote.CHANNEL_API
+	}
+	now := uc.now()
+	draws := make([]models.QuoteDrawModel, 0, len(quotes))
+	ids := make([]int64, 0, len(quotes))
+	for _, q := range quotes {
+		draws = append(draws, models.QuoteDrawModel{UserID: user.ID, QuoteID: q.ID, Channel: string(channel), DrawnAt: now})
+		ids = append(ids, q.ID)
+	}
+	err := uc.r.CreateDraws(draws)
+	if err != nil {
+		return exceptions.ServerError
+	}
+	if channel != quote.CHANNEL_DIGEST {
+		return nil
+	}
+	err = uc.r.Postpone(user.ID, ids, now, now.AddDate(0, 0, SKIP_DAYS))
+	if err != nil {
+		return exceptions.ServerError
+	}
+	return nil
+}
+
+// pick draws n quotes without replacement, weighted by weight, see Efraimidis & Spirakis (2006).
+// When skipping the excluded quotes leaves fewer than n, the least recently shown excluded quotes fill up.
+func (uc *Usecase) pick(candidates []models.QuoteModel, skip map[int64]bool, last map[int64]time.Time, n int, now time.Time) []models.QuoteModel {
+	var pool, held []models.QuoteModel
+	for _, m := range candidates {
+		if skip[m.ID] {
+			held = append(held, m)
+			continue
+		}
+		pool = append(pool, m)
+	}
+
+	type keyed struct {
+		quote models.QuoteModel
+		key   float64
+	}
+	keys := make([]keyed, 0, len(pool))
+	for _, m := range pool {
+		keys = append(keys, keyed{quote: m, key: math.Pow(uc.random.Float64(), 1/weight(last, m.ID, now))})
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+
+	picked := make([]models.QuoteModel, 0, n)
+	for _, k := range keys {
+		if len(picked) == n {
+			return picked
+		}
+		picked = append(picked, k.quote)
+	}
+
+	sort.SliceStable(held, func(i, j int) bool { return last[held[i].ID].Before(last[held[j].ID]) })
+	for _, m := range held {
+		if len(picked) == n {
+			break
+		}
+		picked = append(picked, m)
+	}
+	return picked
+}
+
+// weight grows by one for every day since the quote was last shown.
+func weight(last map[int64]time.Time, id int64, now time.Time) float64 {
+	drawnAt, ok := last[id]
+	if !ok {
+		return MAX_WEIGHT_DAYS + 1
+	}
+	days := now.Sub(drawnAt).Hours() / 24
+	return 1 + math.Max(0, math.Min(days, MAX_WEIGHT_DAYS))
+}
+
+func excluded(draws []models.QuoteDrawModel) map[int64]bool {
+	skip := make(map[int64]bool, len(draws))
+	for _, d := range draws {
+		skip[d.QuoteID] = true
+	}
+	return skip
+}
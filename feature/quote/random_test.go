@@ -0,0 +1,199 @@
+package quote
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type RandomQuoteTestSuite struct {
+	suite.Suite
+	uc     *Usecase
+	repo   *MockedQuoteRepo
+	random *sequence
+	user   models.User
+	now    time.Time
+	quotes []models.QuoteModel
+}
+
+func TestRandomQuote(t *testing.T) {
+	suite.Run(t, new(RandomQuoteTestSuite))
+}
+
+func (s *RandomQuoteTestSuite) SetupTest() {
+	s.repo = new(MockedQuoteRepo)
+	s.random = &sequence{numbers: []float64{0.5}}
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, new(MockedSearchRepo), s.random, 2)
+	s.now = time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	s.uc.now = func() time.Time { return s.now }
+	s.user = models.User{ID: 1}
+	s.quotes = []models.QuoteModel{
+		{ID: 1, UserID: 1, Text: "Quote 1"},
+		{ID: 2, UserID: 1, Text: "Quote 2"},
+		{ID: 3, UserID: 1, Text: "Quote 3"},
+		{ID: 4, UserID: 1, Text: "Quote 4"},
+	}
+}
+
+func (s *RandomQuoteTestSuite) draw(quoteID int64, daysAgo int) models.QuoteDrawModel {
+	return models.QuoteDrawModel{UserID: 1, QuoteID: quoteID, DrawnAt: s.now.AddDate(0, 0, -daysAgo)}
+}
+
+func ids(quotes []models.Quote) []int64 {
+	var result []int64
+	for _, q := range quotes {
+		result = append(result, q.ID)
+	}
+	return result
+}
+
+func (s *RandomQuoteTestSuite) TestInvalidCount() {
+	_, err := s.uc.Random(s.user, 0, quote.RandomOptions{})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Random(s.user, MAX_RANDOM_QUOTES+1, quote.RandomOptions{})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+}
+
+func (s *RandomQuoteTestSuite) TestSkipRecentlyShownQuotes() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes, nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{s.draw(1, 0), s.draw(2, 1)}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{1: s.now, 2: s.now.AddDate(0, 0, -1)}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	quotes, err := s.uc.Random(s.user, 2, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.Assert().ElementsMatch([]int64{3, 4}, ids(quotes))
+	s.repo.AssertCalled(s.T(), "CreateDraws", []models.QuoteDrawModel{
+		{UserID: 1, QuoteID: quotes[0].ID, Channel: "api", DrawnAt: s.now},
+		{UserID: 1, QuoteID: quotes[1].ID, Channel: "api", DrawnAt: s.now},
+	})
+}
+
+func (s *RandomQuoteTestSuite) TestWindowZeroSkipsNothing() {
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, new(MockedSearchRepo), s.random, 0)
+	s.uc.now = func() time.Time { return s.now }
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes[:2], nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{1: s.now, 2: s.now}, nil)
+
+	quotes, err := s.uc.Draw(s.user, 2, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.Assert().ElementsMatch([]int64{1, 2}, ids(quotes))
+	s.repo.AssertNotCalled(s.T(), "RecentDraws", mock.Anything, mock.Anything)
+}
+
+func (s *RandomQuoteTestSuite) TestRecordDeliveryChannel() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes[:1], nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+	s.repo.On("Postpone", int64(1), []int64{1}, s.now, s.now.AddDate(0, 0, SKIP_DAYS)).Return(nil)
+
+	_, err := s.uc.Random(s.user, 1, quote.RandomOptions{Channel: quote.CHANNEL_DIGEST})
+	s.Assert().Nil(err)
+	s.repo.AssertCalled(s.T(), "CreateDraws", []models.QuoteDrawModel{{UserID: 1, QuoteID: 1, Channel: "digest", DrawnAt: s.now}})
+	s.repo.AssertCalled(s.T(), "Postpone", int64(1), []int64{1}, s.now, s.now.AddDate(0, 0, SKIP_DAYS))
+}
+
+func (s *RandomQuoteTestSuite) TestDeliverThroughApiKeepsReviews() {
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	err := s.uc.Deliver(s.user, []models.Quote{{ID: 1}}, quote.CHANNEL_API)
+	s.Assert().Nil(err)
+	s.repo.AssertNotCalled(s.T(), "Postpone", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *RandomQuoteTestSuite) TestFillWithLeastRecentlyShownWhenNotEnough() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes[:3], nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{s.draw(1, 0), s.draw(2, 1)}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{1: s.now, 2: s.now.AddDate(0, 0, -1)}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	quotes, err := s.uc.Random(s.user, 2, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{3, 2}, ids(quotes))
+}
+
+func (s *RandomQuoteTestSuite) TestReturnAllWhenCollectionIsSmall() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes[:2], nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	quotes, err := s.uc.Random(s.user, 5, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.Assert().ElementsMatch([]int64{1, 2}, ids(quotes))
+}
+
+func (s *RandomQuoteTestSuite) TestPreferQuotesNotShownForLong() {
+	// with the same random number, the quote with the larger weight has the larger key
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes, nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{
+		1: s.now.AddDate(0, 0, -3),
+		2: s.now.AddDate(0, 0, -20),
+		3: s.now.AddDate(0, 0, -1),
+	}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	quotes, err := s.uc.Random(s.user, 4, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{4, 2, 1, 3}, ids(quotes))
+}
+
+func (s *RandomQuoteTestSuite) TestRandomNumbersDecideBetweenEqualWeights() {
+	s.random.numbers = []float64{0.1, 0.9, 0.5, 0.3}
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes, nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	quotes, err := s.uc.Random(s.user, 2, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{2, 3}, ids(quotes))
+}
+
+func (s *RandomQuoteTestSuite) TestThrowServerErrorWhenRecordDrawsFailure() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes, nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(exceptions.ServerError)
+
+	_, err := s.uc.Random(s.user, 1, quote.RandomOptions{})
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *RandomQuoteTestSuite) TestFromFollowingWithOwner() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes[:1], nil)
+	s.repo.On("FindFollowing", int64(1), noFilter).Return([]models.QuoteModel{{ID: 5, UserID: 2, Text: "Quote 5"}}, nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{}, nil)
+	s.repo.On("Owners", []int64{2}).Return(map[int64]models.UserModel{2: {ID: 2, Name: "Leslie"}}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	quotes, err := s.uc.Random(s.user, 2, quote.RandomOptions{Following: true})
+	s.Assert().Nil(err)
+	s.Assert().ElementsMatch([]int64{1, 5}, ids(quotes))
+	for _, q := range quotes {
+		if q.UserID == s.user.ID {
+			s.Assert().Nil(q.Owner)
+		} else {
+			s.Assert().Equal(&models.Profile{ID: 2, Name: "Leslie"}, q.Owner)
+		}
+	}
+}
+
+func (s *RandomQuoteTestSuite) TestOwnQuotesOnlyByDefault() {
+	s.repo.On("FindAll", int64(1), noFilter).Return(s.quotes[:1], nil)
+	s.repo.On("RecentDraws", int64(1), 2).Return([]models.QuoteDrawModel{}, nil)
+	s.repo.On("LastDrawn", int64(1)).Return(map[int64]time.Time{}, nil)
+	s.repo.On("CreateDraws", mock.Anything).Return(nil)
+
+	_, err := s.uc.Random(s.user, 2, quote.RandomOptions{})
+	s.Assert().Nil(err)
+	s.repo.AssertNotCalled(s.T(), "FindFollowing", mock.Anything)
+}
@@ -0,0 +1,399 @@
+package quote
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/quotelink"
+	"myquote/service/sm2"
+	"time"
+)
+
+const HASH_BATCH_SIZE = 100
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the quotes table and the books, chapters and tags saving a quote links to,
+// and sets the content hash of the quotes saved before quotes had one.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.QuoteModel{}, &models.QuoteDrawModel{}, &models.BookModel{}, &models.ChapterModel{}, &models.TagModel{}, &models.QuoteTagModel{}, &models.ReviewModel{})
+	if err != nil {
+		r.l.Errorf("migrate quotes table error: %s", err.Error())
+		return err
+	}
+
+	var quotes []models.QuoteModel
+	result := r.db.Where("content_hash = '' OR content_hash IS NULL").FindInBatches(&quotes, HASH_BATCH_SIZE, func(tx *gorm.DB, batch int) error {
+		for _, q := range quotes {
+			// UpdateColumn keeps updated_at, hashing does not change the quote
+			err := tx.Model(&q).UpdateColumn("content_hash", models.ContentHash(q.Text)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		r.l.Errorf("hash quotes error: %s", result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
+
+// Create saves the quote linked to its book, chapter and tags, which are created when missing.
+func (r *Repository) Create(quote models.QuoteModel) (models.QuoteModel, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		link := quotelink.New(tx)
+		err := link.Book(&quote)
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&quote).Error
+		if err != nil {
+			return err
+		}
+		return link.Tags(quote, "")
+	})
+	if err != nil {
+		r.l.Debugf("create quote error, user id: %d\n The error message: %s", quote.UserID, err.Error())
+		return models.QuoteModel{}, err
+	}
+	return quote, nil
+}
+
+func (r *Repository) FindAll(userID int64, filter quote.TagFilter) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.Scopes(r.tagged(filter)).Where("user_id = ?", userID).Order("created_at desc, id desc").Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("find quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+func (r *Repository) List(userID int64, filter quote.TagFilter, page quote.Page) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.Scopes(r.tagged(filter), paged(page)).Where("user_id = ?", userID).Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("list quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+func (r *Repository) Find(id int64) (bool, models.QuoteModel, error) {
+	var quote models.QuoteModel
+	result := r.db.First(&quote, "id = ?", id)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.QuoteModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find quote error, quote id: %d\n The error message: %s", id, result.Error.Error())
+		return false, models.QuoteModel{}, result.Error
+	}
+	return true, quote, nil
+}
+
+func (r *Repository) FindVisible(viewerID int64, id int64) (bool, models.QuoteModel, error) {
+	var quote models.QuoteModel
+	result := r.db.Scopes(r.visibleTo(viewerID)).First(&quote, "id = ?", id)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.QuoteModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find visible quote error, quote id: %d\n The error message: %s", id, result.Error.Error())
+		return false, models.QuoteModel{}, result.Error
+	}
+	return true, quote, nil
+}
+
+func (r *Repository) FindByUser(ownerID int64, viewerID int64, filter quote.TagFilter, page quote.Page) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.Scopes(r.visibleTo(viewerID), r.tagged(filter), paged(page)).Where("user_id = ?", ownerID).Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("find quotes of user error, user id: %d\n The error message: %s", ownerID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+// Update saves the quote linked to its book and chapter again, the titles may have changed. The
+// tag links are only touched when the tags changed.
+func (r *Repository) Update(quote models.QuoteModel) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var previous []string
+		err := tx.Model(&models.QuoteModel{}).Where("id = ?", quote.ID).Pluck("tags", &previous).Error
+		if err != nil {
+			return err
+		}
+		link := quotelink.New(tx)
+		err = link.Book(&quote)
+		if err != nil {
+			return err
+		}
+		err = tx.Save(&quote).Error
+		if err != nil {
+			return err
+		}
+		if len(previous) == 0 {
+			return link.Tags(quote, "")
+		}
+		return link.Tags(quote, previous[0])
+	})
+	if err != nil {
+		r.l.Debugf("update quote error, quote id: %d\n The error message: %s", quote.ID, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Delete(id int64) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("quote_id = ?", id).Delete(&models.QuoteDrawModel{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("quote_id = ?", id).Delete(&models.QuoteTagModel{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("quote_id = ?", id).Delete(&models.ReviewModel{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.QuoteModel{}, id).Error
+	})
+	if err != nil {
+		r.l.Debugf("delete quote error, quote id: %d\n The error message: %s", id, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) RecentDraws(userID int64, limit int) ([]models.QuoteDrawModel, error) {
+	var draws []models.QuoteDrawModel
+	result := r.db.Where("user_id = ?", userID).Order("drawn_at desc, id desc").Limit(limit).Find(&draws)
+	if result.Error != nil {
+		r.l.Debugf("find recent draws error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return draws, nil
+}
+
+// LastDrawn maps the quote id to the last time it was shown to the user, it reads one draw per quote.
+func (r *Repository) LastDrawn(userID int64) (map[int64]time.Time, error) {
+	// the draws are joined to their maximum, MAX alone loses the column type on some databases
+	latest := r.db.Model(&models.QuoteDrawModel{}).
+		Select("quote_id, MAX(drawn_at) AS drawn_at").
+		Where("user_id = ?", userID).
+		Group("quote_id")
+	var draws []models.QuoteDrawModel
+	result := r.db.
+		Joins("JOIN (?) AS latest ON latest.quote_id = quote_draws.quote_id AND latest.drawn_at = quote_draws.drawn_at", latest).
+		Where("quote_draws.user_id = ?", userID).
+		Find(&draws)
+	if result.Error != nil {
+		r.l.Debugf("find last drawn error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	last := make(map[int64]time.Time, len(draws))
+	for _, d := range draws {
+		last[d.QuoteID] = d.DrawnAt
+	}
+	return last, nil
+}
+
+func (r *Repository) CreateDraws(draws []models.QuoteDrawModel) error {
+	if len(draws) == 0 {
+		return nil
+	}
+	result := r.db.Create(&draws)
+	if result.Error != nil {
+		r.l.Debugf("create draws error, count: %d\n The error message: %s", len(draws), result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
+
+func (r *Repository) FindFollowing(userID int64, filter quote.TagFilter) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.
+		Scopes(r.visibleTo(userID), r.tagged(filter)).
+		Where("user_id IN (?)", r.followees(userID)).
+		Order("created_at desc, id desc").
+		Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("find following quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+func (r *Repository) Feed(userID int64, page quote.Page) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.
+		Scopes(r.visibleTo(userID), paged(page)).
+		Where("user_id IN (?)", r.followees(userID)).
+		Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("find feed error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+func (r *Repository) FindReview(userID int64, quoteID int64) (bool, models.ReviewModel, error) {
+	var review models.ReviewModel
+	result := r.db.First(&review, "user_id = ? AND quote_id = ?", userID, quoteID)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.ReviewModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find review error, quote id: %d\n The error message: %s", quoteID, result.Error.Error())
+		return false, models.ReviewModel{}, result.Error
+	}
+	return true, review, nil
+}
+
+func (r *Repository) SaveReview(review models.ReviewModel) (models.ReviewModel, error) {
+	result := r.db.Save(&review)
+	if result.Error != nil {
+		r.l.Debugf("save review error, quote id: %d\n The error message: %s", review.QuoteID, result.Error.Error())
+		return models.ReviewModel{}, result.Error
+	}
+	return review, nil
+}
+
+// Due returns the quotes userID should review at now, the reviewed ones that are due first
+// and earliest first, then their own quotes they have never reviewed.
+func (r *Repository) Due(userID int64, now time.Time, limit int) ([]models.QuoteModel, error) {
+	var quotes []models.QuoteModel
+	result := r.db.
+		Scopes(r.visibleTo(userID)).
+		Joins("LEFT JOIN reviews ON reviews.quote_id = quotes.id AND reviews.user_id = ?", userID).
+		Where("(reviews.id IS NULL AND quotes.user_id = ?) OR reviews.due_at <= ?", userID, now).
+		Order("CASE WHEN reviews.id IS NULL THEN 1 ELSE 0 END, reviews.due_at, quotes.id").
+		Limit(limit).
+		Find(&quotes)
+	if result.Error != nil {
+		r.l.Debugf("find due quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return quotes, nil
+}
+
+func (r *Repository) Postpone(userID int64, quoteIDs []int64, now time.Time, dueAt time.Time) error {
+	if len(quoteIDs) == 0 {
+		return nil
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ReviewModel{}).
+			Where("user_id = ? AND quote_id IN ? AND due_at <= ?", userID, quoteIDs, now).
+			Update("due_at", dueAt).Error
+		if err != nil {
+			return err
+		}
+		var unreviewed []int64
+		err = tx.Model(&models.QuoteModel{}).
+			Where("id IN ? AND user_id = ?", quoteIDs, userID).
+			Where("NOT EXISTS (SELECT 1 FROM reviews WHERE reviews.quote_id = quotes.id AND reviews.user_id = ?)", userID).
+			Pluck("id", &unreviewed).Error
+		if err != nil || len(unreviewed) == 0 {
+			return err
+		}
+		reviews := make([]models.ReviewModel, 0, len(unreviewed))
+		for _, id := range unreviewed {
+			reviews = append(reviews, models.ReviewModel{UserID: userID, QuoteID: id, Ease: sm2.INITIAL_EASE, DueAt: dueAt})
+		}
+		return tx.Create(&reviews).Error
+	})
+	if err != nil {
+		r.l.Debugf("postpone reviews error, user id: %d\n The error message: %s", userID, err.Error())
+		return err
+	}
+	return nil
+}
+
+// followees selects the ids of the users userID follows with an accepted request.
+func (r *Repository) followees(userID int64) *gorm.DB {
+	return r.db.Model(&models.FollowModel{}).
+		Select("followee_id").
+		Where("follower_id = ? AND status = ?", userID, string(friend.ACCEPTED))
+}
+
+// visibleTo keeps the quotes viewerID may read: their own, public ones and
+// followers-only ones of the users they follow with an accepted request.
+func (r *Repository) visibleTo(viewerID int64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"quotes.user_id = ? OR quotes.visibility = ? OR (quotes.visibility = ? AND quotes.user_id IN (?))",
+			viewerID, string(quote.PUBLIC), string(quote.FOLLOWERS), r.followees(viewerID),
+		)
+	}
+}
+
+// tagged keeps the quotes with all or any of the tags of filter.
+func (r *Repository) tagged(filter quote.TagFilter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(filter.Tags) == 0 {
+			return db
+		}
+		ids := r.db.Model(&models.QuoteTagModel{}).
+			Select("quote_tags.quote_id").
+			Joins("JOIN tags ON tags.id = quote_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags)
+		if filter.Match == quote.MATCH_ALL {
+			ids = ids.Group("quote_tags.quote_id").Having("COUNT(DISTINCT tags.name) = ?", len(filter.Tags))
+		}
+		return db.Where("quotes.id IN (?)", ids)
+	}
+}
+
+// paged sorts the quotes by the sort key of page and keeps the ones after its cursor. The id breaks
+// ties, and unlike an offset the cursor is not shifted by quotes inserted in the meantime.
+func paged(page quote.Page) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		column := "quotes." + string(page.Sort)
+		cmp := "<"
+		if page.Order == quote.ASC {
+			cmp = ">"
+		}
+		if page.After != nil {
+			var value interface{} = page.After.Time
+			if page.Sort == quote.SORT_BOOK {
+				value = page.After.Book
+			}
+			db = db.Where(fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND quotes.id %[2]s ?)", column, cmp), value, value, page.After.ID)
+		}
+		return db.Order(fmt.Sprintf("%s %s, quotes.id %s", column, page.Order, page.Order)).Limit(page.Limit)
+	}
+}
+
+// Owners maps the user id to the user for the given ids.
+func (r *Repository) Owners(userIDs []int64) (map[int64]models.UserModel, error) {
+	owners := make(map[int64]models.UserModel, len(userIDs))
+	if len(userIDs) == 0 {
+		return owners, nil
+	}
+	var users []models.UserModel
+	result := r.db.Where("id IN ?", userIDs).Find(&users)
+	if result.Error != nil {
+		r.l.Debugf("find owners error, count: %d\n The error message: %s", len(userIDs), result.Error.Error())
+		return nil, result.Error
+	}
+	for _, u := range users {
+		owners[u.ID] = u
+	}
+	return owners, nil
+}
@@ -0,0 +1,450 @@
+package quote
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/friend"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type QuoteRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+// newest reads the first 100 quotes, newest first.
+var newest = quote.Page{Sort: quote.SORT_CREATED_AT, Order: quote.DESC, Limit: 100}
+
+func TestQuoteRepository(t *testing.T) {
+	suite.Run(t, new(QuoteRepositoryTestSuite))
+}
+
+func (s *QuoteRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.UserModel{}, &models.FollowModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+}
+
+func (s *QuoteRepositoryTestSuite) TestCreateAndFind() {
+	created, err := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1", Book: "Book1", Tags: "life"})
+	s.Require().Nil(err)
+	s.Assert().NotZero(created.ID)
+
+	find, q, err := s.repo.Find(created.ID)
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal("Quote 1", q.Text)
+	s.Assert().Equal("life", q.Tags)
+}
+
+func (s *QuoteRepositoryTestSuite) TestFindNotExists() {
+	find, _, err := s.repo.Find(99)
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *QuoteRepositoryTestSuite) TestFindAllOnlyReturnsOwnQuotes() {
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	s.repo.Create(models.QuoteModel{UserID: 2, Text: "Quote 2"})
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 3"})
+
+	quotes, err := s.repo.FindAll(1, quote.TagFilter{})
+	s.Assert().Nil(err)
+	s.Assert().Len(quotes, 2)
+	s.Assert().Equal("Quote 3", quotes[0].Text)
+}
+
+func (s *QuoteRepositoryTestSuite) TestUpdate() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	created.Text = "changed"
+	s.Assert().Nil(s.repo.Update(created))
+
+	_, q, _ := s.repo.Find(created.ID)
+	s.Assert().Equal("changed", q.Text)
+	s.Assert().Equal(created.CreatedAt.Unix(), q.CreatedAt.Unix())
+}
+
+func (s *QuoteRepositoryTestSuite) TestDelete() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	s.Assert().Nil(s.repo.Delete(created.ID))
+
+	find, _, _ := s.repo.Find(created.ID)
+	s.Assert().False(find)
+}
+
+func (s *QuoteRepositoryTestSuite) TestDraws() {
+	now := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	err := s.repo.CreateDraws([]models.QuoteDrawModel{
+		{UserID: 1, QuoteID: 1, DrawnAt: now.AddDate(0, 0, -2)},
+		{UserID: 1, QuoteID: 2, DrawnAt: now.AddDate(0, 0, -1)},
+		{UserID: 1, QuoteID: 1, DrawnAt: now},
+		{UserID: 2, QuoteID: 3, DrawnAt: now},
+	})
+	s.Require().Nil(err)
+
+	recent, err := s.repo.RecentDraws(1, 2)
+	s.Assert().Nil(err)
+	s.Assert().Len(recent, 2)
+	s.Assert().Equal(int64(1), recent[0].QuoteID)
+	s.Assert().Equal(int64(2), recent[1].QuoteID)
+
+	last, err := s.repo.LastDrawn(1)
+	s.Assert().Nil(err)
+	s.Assert().Len(last, 2)
+	s.Assert().True(now.Equal(last[1]))
+	s.Assert().True(now.AddDate(0, 0, -1).Equal(last[2]))
+}
+
+func (s *QuoteRepositoryTestSuite) TestDeleteRemovesDraws() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	s.repo.CreateDraws([]models.QuoteDrawModel{{UserID: 1, QuoteID: created.ID, DrawnAt: time.Now()}})
+	s.Require().Nil(s.repo.Delete(created.ID))
+
+	recent, _ := s.repo.RecentDraws(1, 10)
+	s.Assert().Empty(recent)
+}
+
+func (s *QuoteRepositoryTestSuite) TestSaveAndFindReview() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	find, _, err := s.repo.FindReview(1, created.ID)
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+
+	saved, err := s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: created.ID, Ease: 2.5, Interval: 1, Repetitions: 1, DueAt: time.Now()})
+	s.Require().Nil(err)
+	saved.Interval = 6
+	_, err = s.repo.SaveReview(saved)
+	s.Require().Nil(err)
+
+	find, review, err := s.repo.FindReview(1, created.ID)
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal(saved.ID, review.ID)
+	s.Assert().Equal(6, review.Interval)
+}
+
+func (s *QuoteRepositoryTestSuite) TestMigrateHashesOldQuotes() {
+	updated := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	s.db.Model(&models.QuoteModel{}).Where("id = ?", created.ID).UpdateColumns(map[string]interface{}{"content_hash": "", "updated_at": updated})
+
+	err := s.repo.Migrate()
+	s.Assert().Nil(err)
+
+	var q models.QuoteModel
+	s.db.First(&q, created.ID)
+	s.Assert().Equal(models.ContentHash("Quote 1"), q.ContentHash)
+	s.Assert().True(updated.Equal(q.UpdatedAt))
+}
+
+func (s *QuoteRepositoryTestSuite) TestDue() {
+	now := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	var quotes []models.QuoteModel
+	for _, text := range []string{"new", "later", "due", "overdue"} {
+		created, err := s.repo.Create(models.QuoteModel{UserID: 1, Text: text})
+		s.Require().NoError(err)
+		quotes = append(quotes, created)
+	}
+	s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: quotes[1].ID, DueAt: now.Add(time.Hour)})
+	s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: quotes[2].ID, DueAt: now})
+	s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: quotes[3].ID, DueAt: now.AddDate(0, 0, -1)})
+	// a review of another user does not schedule user 1
+	s.repo.SaveReview(models.ReviewModel{UserID: 2, QuoteID: quotes[0].ID, DueAt: now.AddDate(0, 0, 7)})
+
+	due, err := s.repo.Due(1, now, 10)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]string{"overdue", "due", "new"}, texts(due))
+	due, _ = s.repo.Due(1, now, 2)
+	s.Assert().Equal([]string{"overdue", "due"}, texts(due))
+}
+
+func (s *QuoteRepositoryTestSuite) TestPostponeLeavesMailedQuotesOutOfDue() {
+	now := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	var quotes []models.QuoteModel
+	for _, text := range []string{"new", "later", "due"} {
+		created, err := s.repo.Create(models.QuoteModel{UserID: 1, Text: text})
+		s.Require().NoError(err)
+		quotes = append(quotes, created)
+	}
+	later, _ := s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: quotes[1].ID, DueAt: now.AddDate(0, 0, 7)})
+	s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: quotes[2].ID, DueAt: now})
+
+	tomorrow := now.AddDate(0, 0, 1)
+	s.Assert().Nil(s.repo.Postpone(1, []int64{quotes[0].ID, quotes[1].ID, quotes[2].ID}, now, tomorrow))
+	due, _ := s.repo.Due(1, now, 10)
+	s.Assert().Empty(due)
+	due, _ = s.repo.Due(1, tomorrow, 10)
+	s.Assert().Equal([]string{"new", "due"}, texts(due))
+	_, review, _ := s.repo.FindReview(1, later.QuoteID)
+	s.Assert().True(review.DueAt.Equal(later.DueAt))
+}
+
+func (s *QuoteRepositoryTestSuite) TestDueOnlyVisibleQuotes() {
+	now := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	quotes := s.shareQuotes()
+	for _, q := range quotes {
+		s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: q.ID, DueAt: now})
+	}
+
+	due, err := s.repo.Due(1, now, 10)
+	s.Assert().Nil(err)
+	// quotes of others are only due once reviewed and never when hidden
+	s.Assert().Equal([]string{"public"}, texts(due))
+}
+
+func (s *QuoteRepositoryTestSuite) TestDeleteRemovesReviews() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	s.repo.SaveReview(models.ReviewModel{UserID: 1, QuoteID: created.ID, DueAt: time.Now()})
+	s.Require().Nil(s.repo.Delete(created.ID))
+
+	find, _, err := s.repo.FindReview(1, created.ID)
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+// follow makes followerID follow followeeID with the status.
+func (s *QuoteRepositoryTestSuite) follow(followerID int64, followeeID int64, status friend.Status) {
+	s.Require().NoError(s.db.Create(&models.FollowModel{FollowerID: followerID, FolloweeID: followeeID, Status: string(status)}).Error)
+}
+
+func (s *QuoteRepositoryTestSuite) TestFindFollowingOnlyAccepted() {
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Own"})
+	s.repo.Create(models.QuoteModel{UserID: 2, Text: "Accepted"})
+	s.repo.Create(models.QuoteModel{UserID: 3, Text: "Pending"})
+	s.follow(1, 2, friend.ACCEPTED)
+	s.follow(1, 3, friend.PENDING)
+	s.follow(3, 1, friend.ACCEPTED)
+
+	quotes, err := s.repo.FindFollowing(1, quote.TagFilter{})
+	s.Assert().Nil(err)
+	s.Assert().Len(quotes, 1)
+	s.Assert().Equal("Accepted", quotes[0].Text)
+}
+
+func (s *QuoteRepositoryTestSuite) TestFeedPages() {
+	s.follow(1, 2, friend.ACCEPTED)
+	base := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		s.repo.Create(models.QuoteModel{UserID: 2, Text: "Quote", CreatedAt: base.AddDate(0, 0, i)})
+	}
+
+	page := quote.Page{Sort: quote.SORT_CREATED_AT, Order: quote.DESC, Limit: 2}
+	first, err := s.repo.Feed(1, page)
+	s.Assert().Nil(err)
+	s.Assert().Len(first, 2)
+	s.Assert().Equal(base.AddDate(0, 0, 2), first[0].CreatedAt.UTC())
+
+	page.After = &quote.Cursor{Time: first[1].CreatedAt, ID: first[1].ID}
+	second, err := s.repo.Feed(1, page)
+	s.Assert().Nil(err)
+	s.Assert().Len(second, 1)
+	s.Assert().Equal(base, second[0].CreatedAt.UTC())
+}
+
+func (s *QuoteRepositoryTestSuite) TestOwners() {
+	users := []models.UserModel{{Name: "Lester", Email: "lester@gmail.com"}, {Name: "Leslie", Email: "leslie@gmail.com"}}
+	s.Require().NoError(s.db.Create(&users).Error)
+
+	owners, err := s.repo.Owners([]int64{users[1].ID, 99})
+	s.Assert().Nil(err)
+	s.Assert().Len(owners, 1)
+	s.Assert().Equal("Leslie", owners[users[1].ID].Name)
+}
+
+// shareQuotes makes user 2 write one quote of every visibility and returns them by visibility.
+func (s *QuoteRepositoryTestSuite) shareQuotes() map[quote.Visibility]models.QuoteModel {
+	quotes := map[quote.Visibility]models.QuoteModel{}
+	for _, v := range []quote.Visibility{quote.PRIVATE, quote.FOLLOWERS, quote.PUBLIC} {
+		created, err := s.repo.Create(models.QuoteModel{UserID: 2, Text: string(v), Visibility: string(v)})
+		s.Require().NoError(err)
+		quotes[v] = created
+	}
+	return quotes
+}
+
+func texts(quotes []models.QuoteModel) []string {
+	var result []string
+	for _, q := range quotes {
+		result = append(result, q.Text)
+	}
+	return result
+}
+
+func (s *QuoteRepositoryTestSuite) TestDefaultVisibility() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	_, q, _ := s.repo.Find(created.ID)
+	s.Assert().Equal("followers", q.Visibility)
+}
+
+func (s *QuoteRepositoryTestSuite) TestOwnerSeesEveryVisibility() {
+	quotes := s.shareQuotes()
+	for _, q := range quotes {
+		find, _, err := s.repo.FindVisible(2, q.ID)
+		s.Assert().Nil(err)
+		s.Assert().True(find)
+	}
+	found, err := s.repo.FindByUser(2, 2, quote.TagFilter{}, newest)
+	s.Assert().Nil(err)
+	s.Assert().Len(found, 3)
+}
+
+func (s *QuoteRepositoryTestSuite) TestFollowerSeesFollowersAndPublic() {
+	quotes := s.shareQuotes()
+	s.follow(1, 2, friend.ACCEPTED)
+
+	find, _, _ := s.repo.FindVisible(1, quotes[quote.PRIVATE].ID)
+	s.Assert().False(find)
+	find, _, _ = s.repo.FindVisible(1, quotes[quote.FOLLOWERS].ID)
+	s.Assert().True(find)
+
+	found, _ := s.repo.FindByUser(2, 1, quote.TagFilter{}, newest)
+	s.Assert().ElementsMatch([]string{"followers", "public"}, texts(found))
+	following, _ := s.repo.FindFollowing(1, quote.TagFilter{})
+	s.Assert().ElementsMatch([]string{"followers", "public"}, texts(following))
+	feed, _ := s.repo.Feed(1, newest)
+	s.Assert().ElementsMatch([]string{"followers", "public"}, texts(feed))
+}
+
+func (s *QuoteRepositoryTestSuite) TestNonFollowerNeverSeesFollowersOnly() {
+	for _, status := range []friend.Status{"", friend.PENDING, friend.REJECTED, friend.CANCELLED} {
+		s.SetupTest()
+		quotes := s.shareQuotes()
+		if status != "" {
+			s.follow(1, 2, status)
+		}
+		// a follow in the other direction gives user 1 nothing
+		s.follow(2, 1, friend.ACCEPTED)
+
+		find, _, err := s.repo.FindVisible(1, quotes[quote.FOLLOWERS].ID)
+		s.Assert().Nil(err)
+		s.Assert().False(find, status)
+		find, _, _ = s.repo.FindVisible(1, quotes[quote.PRIVATE].ID)
+		s.Assert().False(find, status)
+		find, _, _ = s.repo.FindVisible(1, quotes[quote.PUBLIC].ID)
+		s.Assert().True(find, status)
+
+		found, _ := s.repo.FindByUser(2, 1, quote.TagFilter{}, newest)
+		s.Assert().Equal([]string{"public"}, texts(found), status)
+		following, _ := s.repo.FindFollowing(1, quote.TagFilter{})
+		s.Assert().Empty(following, status)
+		feed, _ := s.repo.Feed(1, newest)
+		s.Assert().Empty(feed, status)
+	}
+}
+
+func (s *QuoteRepositoryTestSuite) TestTagsAreLinkedOnCreateAndUpdate() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1", Tags: "life,love"})
+	var links int64
+	s.db.Model(&models.QuoteTagModel{}).Where("quote_id = ?", created.ID).Count(&links)
+	s.Assert().Equal(int64(2), links)
+
+	created.Tags = "love"
+	s.Assert().Nil(s.repo.Update(created))
+	var names []string
+	s.db.Model(&models.TagModel{}).
+		Joins("JOIN quote_tags ON quote_tags.tag_id = tags.id").
+		Where("quote_tags.quote_id = ?", created.ID).
+		Pluck("tags.name", &names)
+	s.Assert().Equal([]string{"love"}, names)
+
+	s.Assert().Nil(s.repo.Delete(created.ID))
+	s.db.Model(&models.QuoteTagModel{}).Where("quote_id = ?", created.ID).Count(&links)
+	s.Assert().Zero(links)
+}
+
+func (s *QuoteRepositoryTestSuite) TestUpdateKeepsTagLinksWhenTagsDidNotChange() {
+	created, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1", Tags: "life,love"})
+	s.db.Where("quote_id = ?", created.ID).Delete(&models.QuoteTagModel{})
+
+	created.Text = "Quote 2"
+	s.Assert().Nil(s.repo.Update(created))
+	var links int64
+	s.db.Model(&models.QuoteTagModel{}).Where("quote_id = ?", created.ID).Count(&links)
+	s.Assert().Zero(links)
+}
+
+func (s *QuoteRepositoryTestSuite) TestFindAllByTags() {
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1", Tags: "life,love"})
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 2", Tags: "life"})
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 3", Tags: "work"})
+	s.repo.Create(models.QuoteModel{UserID: 2, Text: "Quote 4", Tags: "life,love"})
+
+	all, err := s.repo.FindAll(1, quote.TagFilter{Tags: []string{"life", "love"}, Match: quote.MATCH_ALL})
+	s.Assert().Nil(err)
+	s.Assert().Len(all, 1)
+	s.Assert().Equal("Quote 1", all[0].Text)
+
+	any, err := s.repo.FindAll(1, quote.TagFilter{Tags: []string{"love", "work"}, Match: quote.MATCH_ANY})
+	s.Assert().Nil(err)
+	s.Assert().Len(any, 2)
+	s.Assert().Equal("Quote 3", any[0].Text)
+	s.Assert().Equal("Quote 1", any[1].Text)
+}
+
+func (s *QuoteRepositoryTestSuite) TestListByBook() {
+	c, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1", Book: "C"})
+	a, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 2", Book: "A"})
+	b1, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 3", Book: "B"})
+	b2, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 4", Book: "B"})
+	s.repo.Create(models.QuoteModel{UserID: 2, Text: "Quote 5", Book: "A"})
+
+	page := quote.Page{Sort: quote.SORT_BOOK, Order: quote.ASC, Limit: 2}
+	first, err := s.repo.List(1, quote.TagFilter{}, page)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{a.ID, b1.ID}, modelIDs(first))
+
+	page.After = &quote.Cursor{Book: "B", ID: b1.ID}
+	second, err := s.repo.List(1, quote.TagFilter{}, page)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{b2.ID, c.ID}, modelIDs(second))
+}
+
+func (s *QuoteRepositoryTestSuite) TestListByUpdatedAt() {
+	old, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 1"})
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote 2"})
+	old.Text = "changed"
+	s.Require().NoError(s.repo.Update(old))
+
+	quotes, err := s.repo.List(1, quote.TagFilter{}, quote.Page{Sort: quote.SORT_UPDATED_AT, Order: quote.DESC, Limit: 1})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{old.ID}, modelIDs(quotes))
+}
+
+func (s *QuoteRepositoryTestSuite) TestListIsStableUnderInserts() {
+	base := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	var created []models.QuoteModel
+	for i := 0; i < 4; i++ {
+		// two quotes share each time, the id tells them apart
+		q, _ := s.repo.Create(models.QuoteModel{UserID: 1, Text: "Quote", CreatedAt: base.AddDate(0, 0, i/2)})
+		created = append(created, q)
+	}
+
+	page := quote.Page{Sort: quote.SORT_CREATED_AT, Order: quote.DESC, Limit: 2}
+	first, _ := s.repo.List(1, quote.TagFilter{}, page)
+	s.Assert().Equal([]int64{created[3].ID, created[2].ID}, modelIDs(first))
+
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Newer", CreatedAt: base.AddDate(0, 0, 5)})
+	s.repo.Create(models.QuoteModel{UserID: 1, Text: "Same time", CreatedAt: base.AddDate(0, 0, 1)})
+
+	last := first[len(first)-1]
+	page.After = &quote.Cursor{Time: last.CreatedAt, ID: last.ID}
+	second, _ := s.repo.List(1, quote.TagFilter{}, page)
+	s.Assert().Equal([]int64{created[1].ID, created[0].ID}, modelIDs(second))
+}
+
+func modelIDs(quotes []models.QuoteModel) []int64 {
+	ids := make([]int64, 0, len(quotes))
+	for _, q := range quotes {
+		ids = append(ids, q.ID)
+	}
+	return ids
+}
@@ -0,0 +1,79 @@
+package quote
+
+import (
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/sm2"
+)
+
+const MAX_DUE_QUOTES = 100
+
+// SKIP_DAYS is how long a skipped quote waits before it is due again.
+const SKIP_DAYS = 1
+
+// qualities grade the feedback for SM-2, a skipped quote is not graded.
+var qualities = map[quote.Feedback]int{
+	quote.LOVED: 5,
+	quote.MEH:   3,
+}
+
+// Review schedules the next review of a quote user may read. Loving a quote spaces its reviews
+// out the most, a skipped quote keeps its state and comes back after SKIP_DAYS.
+func (uc *Usecase) Review(user models.User, id int64, f quote.ReviewFeedback) (models.Review, error) {
+	if !f.Feedback.Valid() {
+		return models.Review{}, exceptions.InvalidFeedback
+	}
+	find, _, err := uc.r.FindVisible(user.ID, id)
+	if err != nil {
+		return models.Review{}, exceptions.ServerError
+	}
+	if !find {
+		return models.Review{}, exceptions.QuoteNotExists
+	}
+	find, review, err := uc.r.FindReview(user.ID, id)
+	if err != nil {
+		return models.Review{}, exceptions.ServerError
+	}
+	if !find {
+		review = models.ReviewModel{UserID: user.ID, QuoteID: id, Ease: sm2.INITIAL_EASE}
+	}
+
+	now := uc.now()
+	if f.Feedback == quote.SKIP {
+		review.DueAt = now.AddDate(0, 0, SKIP_DAYS)
+	} else {
+		state := sm2.Next(sm2.State{Ease: review.Ease, Interval: review.Interval, Repetitions: review.Repetitions}, qualities[f.Feedback])
+		review.Ease, review.Interval, review.Repetitions = state.Ease, state.Interval, state.Repetitions
+		review.DueAt = now.AddDate(0, 0, state.Interval)
+		review.LastReviewedAt = &now
+	}
+
+	saved, err := uc.r.SaveReview(review)
+	if err != nil {
+		return models.Review{}, exceptions.ServerError
+	}
+	return toReview(saved), nil
+}
+
+func (uc *Usecase) Due(user models.User, n int) ([]models.Quote, error) {
+	if n < 1 || n > MAX_DUE_QUOTES {
+		return nil, exceptions.InvalidInput
+	}
+	found, err := uc.r.Due(user.ID, uc.now(), n)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	return uc.attribute(user, found)
+}
+
+func toReview(m models.ReviewModel) models.Review {
+	return models.Review{
+		QuoteID:        m.QuoteID,
+		Ease:           m.Ease,
+		Interval:       m.Interval,
+		Repetitions:    m.Repetitions,
+		DueAt:          m.DueAt,
+		LastReviewedAt: m.LastReviewedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package quote
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/logger"
+	"myquote/service/sm2"
+	"testing"
+	"time"
+)
+
+type ReviewQuoteTestSuite struct {
+	suite.Suite
+	uc   *Usecase
+	repo *MockedQuoteRepo
+	user models.User
+	now  time.Time
+}
+
+func TestReviewQuote(t *testing.T) {
+	suite.Run(t, new(ReviewQuoteTestSuite))
+}
+
+func (s *ReviewQuoteTestSuite) SetupTest() {
+	s.repo = new(MockedQuoteRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, new(MockedSearchRepo), &sequence{numbers: []float64{0.5}}, 2)
+	s.now = time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	s.uc.now = func() time.Time { return s.now }
+	s.user = models.User{ID: 1}
+}
+
+func (s *ReviewQuoteTestSuite) TestFirstReviewLoved() {
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1}, nil)
+	s.repo.On("FindReview", int64(1), int64(2)).Return(false, models.ReviewModel{}, nil)
+	s.repo.On("SaveReview", mock.Anything).Return(func(r models.ReviewModel) models.ReviewModel { return r }, nil)
+
+	actual, err := s.uc.Review(s.user, 2, quote.ReviewFeedback{Feedback: quote.LOVED})
+	s.Require().Nil(err)
+	s.Assert().Equal(int64(2), actual.QuoteID)
+	s.Assert().Equal(1, actual.Interval)
+	s.Assert().Equal(1, actual.Repetitions)
+	s.Assert().InDelta(2.6, actual.Ease, 0.0001)
+	s.Assert().Equal(s.now.AddDate(0, 0, 1), actual.DueAt)
+	s.Assert().Equal(&s.now, actual.LastReviewedAt)
+}
+
+func (s *ReviewQuoteTestSuite) TestMehAfterRepetitions() {
+	saved := models.ReviewModel{ID: 7, UserID: 1, QuoteID: 2, Ease: 2.5, Interval: 6, Repetitions: 2}
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1}, nil)
+	s.repo.On("FindReview", int64(1), int64(2)).Return(true, saved, nil)
+	s.repo.On("SaveReview", mock.MatchedBy(func(r models.ReviewModel) bool { return r.ID == 7 })).Return(func(r models.ReviewModel) models.ReviewModel { return r }, nil)
+
+	actual, err := s.uc.Review(s.user, 2, quote.ReviewFeedback{Feedback: quote.MEH})
+	s.Require().Nil(err)
+	s.Assert().Equal(15, actual.Interval)
+	s.Assert().Equal(3, actual.Repetitions)
+	s.Assert().InDelta(2.36, actual.Ease, 0.0001)
+	s.Assert().Equal(s.now.AddDate(0, 0, 15), actual.DueAt)
+}
+
+func (s *ReviewQuoteTestSuite) TestSkipKeepsState() {
+	saved := models.ReviewModel{ID: 7, UserID: 1, QuoteID: 2, Ease: 2.5, Interval: 6, Repetitions: 2}
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1}, nil)
+	s.repo.On("FindReview", int64(1), int64(2)).Return(true, saved, nil)
+	s.repo.On("SaveReview", mock.Anything).Return(func(r models.ReviewModel) models.ReviewModel { return r }, nil)
+
+	actual, err := s.uc.Review(s.user, 2, quote.ReviewFeedback{Feedback: quote.SKIP})
+	s.Require().Nil(err)
+	s.Assert().Equal(6, actual.Interval)
+	s.Assert().Equal(2, actual.Repetitions)
+	s.Assert().Equal(sm2.INITIAL_EASE, actual.Ease)
+	s.Assert().Equal(s.now.AddDate(0, 0, SKIP_DAYS), actual.DueAt)
+	s.Assert().Nil(actual.LastReviewedAt)
+}
+
+func (s *ReviewQuoteTestSuite) TestInvalidFeedback() {
+	_, err := s.uc.Review(s.user, 2, quote.ReviewFeedback{Feedback: "wow"})
+	s.Assert().Equal(exceptions.InvalidFeedback, err)
+	s.repo.AssertNotCalled(s.T(), "SaveReview", mock.Anything)
+}
+
+func (s *ReviewQuoteTestSuite) TestReviewHiddenQuote() {
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(false, models.QuoteModel{}, nil)
+	_, err := s.uc.Review(s.user, 2, quote.ReviewFeedback{Feedback: quote.LOVED})
+	s.Assert().Equal(exceptions.QuoteNotExists, err)
+	s.repo.AssertNotCalled(s.T(), "SaveReview", mock.Anything)
+}
+
+func (s *ReviewQuoteTestSuite) TestReviewThrowServerError() {
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1}, nil)
+	s.repo.On("FindReview", int64(1), int64(2)).Return(false, models.ReviewModel{}, nil)
+	s.repo.On("SaveReview", mock.Anything).Return(models.ReviewModel{}, exceptions.ServerError)
+	_, err := s.uc.Review(s.user, 2, quote.ReviewFeedback{Feedback: quote.LOVED})
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *ReviewQuoteTestSuite) TestDue() {
+	s.repo.On("Due", int64(1), s.now, 2).Return([]models.QuoteModel{{ID: 3, UserID: 1}, {ID: 1, UserID: 1}}, nil)
+	actual, err := s.uc.Due(s.user, 2)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]int64{3, 1}, ids(actual))
+}
+
+func (s *ReviewQuoteTestSuite) TestDueInvalidCount() {
+	_, err := s.uc.Due(s.user, 0)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Due(s.user, MAX_DUE_QUOTES+1)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+}
@@ -0,0 +1,124 @@
+package quote
+
+import (
+	"html"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const MAX_SEARCH_TERMS = 10
+const MAX_SEARCH_RESULTS = 50
+
+const MARK_START = "<mark>"
+const MARK_END = "</mark>"
+
+// Search weights, a match in the text counts more than one in the book title or the tags.
+const (
+	TEXT_WEIGHT = 3.0
+	BOOK_WEIGHT = 2.0
+	TAGS_WEIGHT = 1.0
+)
+
+func (uc *Usecase) Search(user models.User, query string, limit int) ([]models.SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 || len(terms) > MAX_SEARCH_TERMS || limit < 1 || limit > MAX_SEARCH_RESULTS {
+		uc.l.Debugf("invalid search from user %d: %d terms, limit %d", user.ID, len(terms), limit)
+		return nil, exceptions.InvalidInput
+	}
+	found, err := uc.s.Search(user.ID, terms, limit)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	quotes, err := uc.attribute(user, found)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.SearchResult, 0, len(quotes))
+	for _, q := range quotes {
+		tags := make([]string, 0, len(q.Tags))
+		for _, t := range q.Tags {
+			tags = append(tags, highlight(t, terms))
+		}
+		results = append(results, models.SearchResult{
+			Quote:     q,
+			Highlight: models.Highlight{Text: highlight(q.Text, terms), Book: highlight(q.Book, terms), Tags: tags},
+		})
+	}
+	return results, nil
+}
+
+// searchTerms splits the query into lower case words, anything but letters and numbers separates them.
+func searchTerms(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !isWordRune(r)
+	})
+	terms := make([]string, 0, len(words))
+	seen := map[string]bool{}
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// highlight wraps the fragments of s that match a term in <mark> tags. The text is escaped,
+// the result is safe to render as HTML.
+func highlight(s string, terms []string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range matchSpans(s, terms) {
+		b.WriteString(html.EscapeString(s[last:span[0]]))
+		b.WriteString(MARK_START)
+		b.WriteString(html.EscapeString(s[span[0]:span[1]]))
+		b.WriteString(MARK_END)
+		last = span[1]
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return b.String()
+}
+
+// matchSpans returns the byte ranges of s where a term matches the start of a word, ignoring case.
+func matchSpans(s string, terms []string) [][2]int {
+	var spans [][2]int
+	prev := ' '
+	for i, r := range s {
+		start := isWordRune(r) && !isWordRune(prev)
+		prev = r
+		if !start {
+			continue
+		}
+		longest := 0
+		for _, t := range terms {
+			if n := prefixLen(s[i:], t); n > longest {
+				longest = n
+			}
+		}
+		if longest > 0 {
+			spans = append(spans, [2]int{i, i + longest})
+		}
+	}
+	return spans
+}
+
+// prefixLen returns how many bytes at the start of s the lower case term matches, 0 when it does not.
+func prefixLen(s string, term string) int {
+	n := 0
+	for _, t := range term {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if size == 0 || unicode.ToLower(r) != t {
+			return 0
+		}
+		n += size
+	}
+	return n
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,77 @@
+package quote
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/service/logger"
+	"testing"
+)
+
+type SearchQuoteTestSuite struct {
+	suite.Suite
+	uc     *Usecase
+	repo   *MockedQuoteRepo
+	search *MockedSearchRepo
+	user   models.User
+}
+
+func TestSearchQuote(t *testing.T) {
+	suite.Run(t, new(SearchQuoteTestSuite))
+}
+
+func (s *SearchQuoteTestSuite) SetupTest() {
+	s.repo = new(MockedQuoteRepo)
+	s.search = new(MockedSearchRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, s.search, &sequence{numbers: []float64{0.5}}, 2)
+	s.user = models.User{ID: 1}
+}
+
+func (s *SearchQuoteTestSuite) TestInvalidInput() {
+	_, err := s.uc.Search(s.user, " ?! ", 10)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Search(s.user, "habit", 0)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Search(s.user, "habit", MAX_SEARCH_RESULTS+1)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Search(s.user, "a b c d e f g h i j k", 10)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	s.search.AssertNotCalled(s.T(), "Search", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *SearchQuoteTestSuite) TestHighlightsMatches() {
+	found := []models.QuoteModel{
+		{ID: 2, UserID: 1, Text: "Habits are the compound interest of self-improvement.", Book: "Atomic Habits", Tags: "habit,growth"},
+		{ID: 3, UserID: 2, Text: "We are what we repeatedly do, excellence is a habit."},
+	}
+	s.search.On("Search", int64(1), []string{"habit", "excellence"}, 10).Return(found, nil)
+	s.repo.On("Owners", []int64{2}).Return(map[int64]models.UserModel{2: {ID: 2, Name: "Leslie"}}, nil)
+
+	results, err := s.uc.Search(s.user, "Habit, excellence habit", 10)
+	s.Assert().Nil(err)
+	s.Require().Len(results, 2)
+	s.Assert().Equal(int64(2), results[0].Quote.ID)
+	s.Assert().Equal("<mark>Habit</mark>s are the compound interest of self-improvement.", results[0].Highlight.Text)
+	s.Assert().Equal("Atomic <mark>Habit</mark>s", results[0].Highlight.Book)
+	s.Assert().Equal([]string{"<mark>habit</mark>", "growth"}, results[0].Highlight.Tags)
+	s.Assert().Equal("We are what we repeatedly do, <mark>excellence</mark> is a <mark>habit</mark>.", results[1].Highlight.Text)
+	s.Assert().Equal(&models.Profile{ID: 2, Name: "Leslie"}, results[1].Quote.Owner)
+}
+
+func (s *SearchQuoteTestSuite) TestServerError() {
+	s.search.On("Search", int64(1), []string{"habit"}, 10).Return([]models.QuoteModel{}, exceptions.ServerError)
+	_, err := s.uc.Search(s.user, "habit", 10)
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *SearchQuoteTestSuite) TestHighlightOnlyMatchesWordStarts() {
+	s.Assert().Equal("rehabit <mark>habit</mark>", highlight("rehabit habit", []string{"habit"}))
+	s.Assert().Equal("<mark>Über</mark> alles", highlight("Über alles", []string{"über"}))
+	s.Assert().Equal("no match", highlight("no match", []string{"habit"}))
+}
+
+func (s *SearchQuoteTestSuite) TestHighlightEscapesHTML() {
+	s.Assert().Equal("&lt;script&gt;<mark>habit</mark>&lt;/script&gt; &amp; <mark>habit</mark>", highlight("<script>habit</script> & habit", []string{"habit"}))
+	s.Assert().Equal("&lt;b&gt;no match&lt;/b&gt;", highlight("<b>no match</b>", []string{"habit"}))
+}
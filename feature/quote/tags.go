@@ -0,0 +1,88 @@
+package quote
+
+import (
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"strings"
+	"unicode/utf8"
+)
+
+// AddTags adds the tags the quote does not have yet.
+func (uc *Usecase) AddTags(user models.User, id int64, u quote.TagsUpdate) (models.Quote, error) {
+	return uc.retag(user, id, u, func(current []string, tags []string) []string {
+		return append(current, tags...)
+	})
+}
+
+func (uc *Usecase) RemoveTags(user models.User, id int64, u quote.TagsUpdate) (models.Quote, error) {
+	return uc.retag(user, id, u, func(current []string, tags []string) []string {
+		removed := map[string]bool{}
+		for _, t := range tags {
+			removed[t] = true
+		}
+		var kept []string
+		for _, t := range current {
+			if !removed[t] {
+				kept = append(kept, t)
+			}
+		}
+		return kept
+	})
+}
+
+// retag replaces the tags of the quote with what change makes of them.
+func (uc *Usecase) retag(user models.User, id int64, u quote.TagsUpdate, change func(current []string, tags []string) []string) (models.Quote, error) {
+	err := validateTags(u.Tags)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	tags := models.SplitTags(models.JoinTags(u.Tags))
+	if len(tags) == 0 {
+		return models.Quote{}, exceptions.InvalidInput
+	}
+	m, err := uc.owned(user, id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	m.Tags = models.JoinTags(change(models.SplitTags(m.Tags), tags))
+	err = uc.r.Update(m)
+	if err != nil {
+		return models.Quote{}, exceptions.ServerError
+	}
+	_, updated, err := uc.r.Find(id)
+	if err != nil {
+		return models.Quote{}, exceptions.ServerError
+	}
+	return updated.ToQuote(), nil
+}
+
+func validateTags(tags []string) error {
+	for _, t := range tags {
+		if strings.Contains(t, ",") {
+			return exceptions.InvalidTag
+		}
+		if utf8.RuneCountInString(strings.TrimSpace(t)) > models.MAX_TAG_LENGTH {
+			return exceptions.InvalidTagName
+		}
+	}
+	return nil
+}
+
+// cleanFilter drops empty and repeated tags and matches all tags unless told otherwise.
+func cleanFilter(filter quote.TagFilter) (quote.TagFilter, error) {
+	switch filter.Match {
+	case "":
+		filter.Match = quote.MATCH_ALL
+	case quote.MATCH_ALL, quote.MATCH_ANY:
+	default:
+		return quote.TagFilter{}, exceptions.InvalidInput
+	}
+	tags := models.SplitTags(models.JoinTags(filter.Tags))
+	filter.Tags = nil
+	if len(tags) > 0 {
+		filter.Tags = tags
+	}
+	return filter, nil
+}
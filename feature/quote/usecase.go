@@ -0,0 +1,181 @@
+package quote
+
+import (
+	"myquote/domain"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"strings"
+	"time"
+)
+
+type Usecase struct {
+	l      domain.Logger
+	r      quote.Repository
+	s      quote.SearchRepository
+	random common.Random
+	window int
+	now    func() time.Time
+}
+
+// NewUsecase creates the quote usecase. Random never repeats a quote shown in the last window draws
+// as long as the user has enough other quotes, a window of 0 allows repeats.
+func NewUsecase(logger domain.Logger, repository quote.Repository, search quote.SearchRepository, random common.Random, window int) *Usecase {
+	return &Usecase{l: logger, r: repository, s: search, random: random, window: window, now: time.Now}
+}
+
+func (uc *Usecase) Create(user models.User, q quote.NewQuote) (models.Quote, error) {
+	err := validate(q)
+	if err != nil {
+		uc.l.Debugf("invalid quote from user %d: %s", user.ID, err.Error())
+		return models.Quote{}, err
+	}
+
+	created, err := uc.r.Create(models.QuoteModel{
+		UserID:     user.ID,
+		Text:       strings.TrimSpace(q.Text),
+		Book:       strings.TrimSpace(q.Book),
+		Chapter:    strings.TrimSpace(q.Chapter),
+		Page:       q.Page,
+		Tags:       models.JoinTags(q.Tags),
+		Visibility: string(quote.ResolveVisibility(q.Visibility, user.DefaultVisibility)),
+	})
+	if err != nil {
+		return models.Quote{}, exceptions.ServerError
+	}
+	return created.ToQuote(), nil
+}
+
+func (uc *Usecase) FindAll(user models.User, filter quote.TagFilter, req quote.PageRequest) (common.Page[models.Quote], error) {
+	filter, err := cleanFilter(filter)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	p, err := page(req)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	found, err := uc.r.List(user.ID, filter, p)
+	if err != nil {
+		return common.Page[models.Quote]{}, exceptions.ServerError
+	}
+	quotes := make([]models.Quote, 0, len(found))
+	for _, m := range found {
+		quotes = append(quotes, m.ToQuote())
+	}
+	return paginate(quotes, p), nil
+}
+
+// Find returns the quote when user may read it. A quote user may not read is reported as missing,
+// so its existence does not leak.
+func (uc *Usecase) Find(user models.User, id int64) (models.Quote, error) {
+	find, m, err := uc.r.FindVisible(user.ID, id)
+	if err != nil {
+		return models.Quote{}, exceptions.ServerError
+	}
+	if !find {
+		return models.Quote{}, exceptions.QuoteNotExists
+	}
+	quotes, err := uc.attribute(user, []models.QuoteModel{m})
+	if err != nil {
+		return models.Quote{}, err
+	}
+	return quotes[0], nil
+}
+
+// FindByUser lists the quotes of another user that user may read.
+func (uc *Usecase) FindByUser(user models.User, ownerID int64, filter quote.TagFilter, req quote.PageRequest) (common.Page[models.Quote], error) {
+	filter, err := cleanFilter(filter)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	p, err := page(req)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	found, err := uc.r.FindByUser(ownerID, user.ID, filter, p)
+	if err != nil {
+		return common.Page[models.Quote]{}, exceptions.ServerError
+	}
+	quotes, err := uc.attribute(user, found)
+	if err != nil {
+		return common.Page[models.Quote]{}, err
+	}
+	return paginate(quotes, p), nil
+}
+
+func (uc *Usecase) Update(user models.User, id int64, q quote.NewQuote) (models.Quote, error) {
+	err := validate(q)
+	if err != nil {
+		uc.l.Debugf("invalid quote from user %d: %s", user.ID, err.Error())
+		return models.Quote{}, err
+	}
+	m, err := uc.owned(user, id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	m.Text = strings.TrimSpace(q.Text)
+	m.Book = strings.TrimSpace(q.Book)
+	m.Chapter = strings.TrimSpace(q.Chapter)
+	m.Page = q.Page
+	m.Tags = models.JoinTags(q.Tags)
+	if q.Visibility != "" {
+		m.Visibility = q.Visibility
+	}
+	err = uc.r.Update(m)
+	if err != nil {
+		return models.Quote{}, exceptions.ServerError
+	}
+	_, updated, err := uc.r.Find(id)
+	if err != nil {
+		return models.Quote{}, exceptions.ServerError
+	}
+	return updated.ToQuote(), nil
+}
+
+func (uc *Usecase) Delete(user models.User, id int64) error {
+	_, err := uc.owned(user, id)
+	if err != nil {
+		return err
+	}
+	err = uc.r.Delete(id)
+	if err != nil {
+		return exceptions.ServerError
+	}
+	return nil
+}
+
+// owned finds the quote and makes sure it belongs to user.
+func (uc *Usecase) owned(user models.User, id int64) (models.QuoteModel, error) {
+	find, m, err := uc.r.Find(id)
+	if err != nil {
+		return models.QuoteModel{}, exceptions.ServerError
+	}
+	if !find {
+		return models.QuoteModel{}, exceptions.QuoteNotExists
+	}
+	if m.UserID != user.ID {
+		uc.l.Warnf("user %d tried to access quote %d of user %d", user.ID, id, m.UserID)
+		return models.QuoteModel{}, exceptions.Forbidden
+	}
+	return m, nil
+}
+
+func validate(q quote.NewQuote) error {
+	if strings.TrimSpace(q.Text) == "" {
+		return exceptions.InvalidQuote
+	}
+	if q.Page < 0 {
+		return exceptions.InvalidInput
+	}
+	err := validateTags(q.Tags)
+	if err != nil {
+		return err
+	}
+	if q.Visibility != "" && !quote.Visibility(q.Visibility).Valid() {
+		return exceptions.InvalidVisibility
+	}
+	return nil
+}
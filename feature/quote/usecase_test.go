@@ -0,0 +1,376 @@
+package quote
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type MockedQuoteRepo struct {
+	mock.Mock
+}
+
+func (m *MockedQuoteRepo) Create(q models.QuoteModel) (models.QuoteModel, error) {
+	args := m.Called(q)
+	return args.Get(0).(models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) FindAll(userID int64, filter quote.TagFilter) ([]models.QuoteModel, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) List(userID int64, filter quote.TagFilter, page quote.Page) ([]models.QuoteModel, error) {
+	args := m.Called(userID, filter, page)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) Find(id int64) (bool, models.QuoteModel, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Get(1).(models.QuoteModel), args.Error(2)
+}
+
+func (m *MockedQuoteRepo) FindVisible(viewerID int64, id int64) (bool, models.QuoteModel, error) {
+	args := m.Called(viewerID, id)
+	return args.Bool(0), args.Get(1).(models.QuoteModel), args.Error(2)
+}
+
+func (m *MockedQuoteRepo) FindByUser(ownerID int64, viewerID int64, filter quote.TagFilter, page quote.Page) ([]models.QuoteModel, error) {
+	args := m.Called(ownerID, viewerID, filter, page)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) Update(q models.QuoteModel) error {
+	args := m.Called(q)
+	return args.Error(0)
+}
+
+func (m *MockedQuoteRepo) Delete(id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockedQuoteRepo) RecentDraws(userID int64, limit int) ([]models.QuoteDrawModel, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]models.QuoteDrawModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) LastDrawn(userID int64) (map[int64]time.Time, error) {
+	args := m.Called(userID)
+	return args.Get(0).(map[int64]time.Time), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) CreateDraws(draws []models.QuoteDrawModel) error {
+	args := m.Called(draws)
+	return args.Error(0)
+}
+
+func (m *MockedQuoteRepo) FindFollowing(userID int64, filter quote.TagFilter) ([]models.QuoteModel, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) Feed(userID int64, page quote.Page) ([]models.QuoteModel, error) {
+	args := m.Called(userID, page)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) Owners(userIDs []int64) (map[int64]models.UserModel, error) {
+	args := m.Called(userIDs)
+	return args.Get(0).(map[int64]models.UserModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) FindReview(userID int64, quoteID int64) (bool, models.ReviewModel, error) {
+	args := m.Called(userID, quoteID)
+	return args.Bool(0), args.Get(1).(models.ReviewModel), args.Error(2)
+}
+
+func (m *MockedQuoteRepo) SaveReview(review models.ReviewModel) (models.ReviewModel, error) {
+	args := m.Called(review)
+	if saved, ok := args.Get(0).(func(models.ReviewModel) models.ReviewModel); ok {
+		return saved(review), args.Error(1)
+	}
+	return args.Get(0).(models.ReviewModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) Due(userID int64, now time.Time, limit int) ([]models.QuoteModel, error) {
+	args := m.Called(userID, now, limit)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+func (m *MockedQuoteRepo) Postpone(userID int64, quoteIDs []int64, now time.Time, dueAt time.Time) error {
+	args := m.Called(userID, quoteIDs, now, dueAt)
+	return args.Error(0)
+}
+
+type MockedSearchRepo struct {
+	mock.Mock
+}
+
+func (m *MockedSearchRepo) Search(viewerID int64, terms []string, limit int) ([]models.QuoteModel, error) {
+	args := m.Called(viewerID, terms, limit)
+	return args.Get(0).([]models.QuoteModel), args.Error(1)
+}
+
+// noFilter is the filter the usecase passes on when no tags are asked for.
+var noFilter = quote.TagFilter{Match: quote.MATCH_ALL}
+
+// firstPage is the page the usecase reads for a request of 10 quotes, one more tells whether a next page exists.
+var firstPage = quote.Page{Sort: quote.SORT_CREATED_AT, Order: quote.DESC, Limit: 11}
+
+// sequence returns its numbers in order and starts over at the end.
+type sequence struct {
+	numbers []float64
+	i       int
+}
+
+func (s *sequence) Float64() float64 {
+	n := s.numbers[s.i%len(s.numbers)]
+	s.i++
+	return n
+}
+
+type QuoteUsecaseTestSuite struct {
+	suite.Suite
+	uc     quote.Usecase
+	repo   *MockedQuoteRepo
+	search *MockedSearchRepo
+	user   models.User
+}
+
+func TestNewQuoteUsecase(t *testing.T) {
+	suite.Run(t, new(QuoteUsecaseTestSuite))
+}
+
+func (s *QuoteUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedQuoteRepo)
+	s.search = new(MockedSearchRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, s.search, &sequence{numbers: []float64{0.5}}, 2)
+	s.user = models.User{ID: 1, Name: "Lester"}
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateEmptyText() {
+	_, err := s.uc.Create(s.user, quote.NewQuote{Text: "   "})
+	s.Assert().Equal(exceptions.InvalidQuote, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateNegativePage() {
+	_, err := s.uc.Create(s.user, quote.NewQuote{Text: "Quote 1", Page: -1})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateTagWithComma() {
+	_, err := s.uc.Create(s.user, quote.NewQuote{Text: "Quote 1", Tags: []string{"a,b"}})
+	s.Assert().Equal(exceptions.InvalidTag, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateSuccess() {
+	q := quote.NewQuote{Text: " Quote 1 ", Book: "Book1", Chapter: "Chapter 1", Page: 12, Tags: []string{"life", " habit ", "life", ""}}
+	m := models.QuoteModel{UserID: 1, Text: "Quote 1", Book: "Book1", Chapter: "Chapter 1", Page: 12, Tags: "life,habit", Visibility: "followers"}
+	created := m
+	created.ID = 5
+	s.repo.On("Create", m).Return(created, nil)
+
+	actual, err := s.uc.Create(s.user, q)
+	s.Assert().Nil(err)
+	s.Assert().Equal(int64(5), actual.ID)
+	s.Assert().Equal([]string{"life", "habit"}, actual.Tags)
+	s.Assert().Equal("followers", actual.Visibility)
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateWithDefaultVisibilityOfUser() {
+	s.user.DefaultVisibility = "private"
+	s.repo.On("Create", mock.MatchedBy(func(m models.QuoteModel) bool { return m.Visibility == "private" })).Return(models.QuoteModel{ID: 5, Visibility: "private"}, nil)
+	_, err := s.uc.Create(s.user, quote.NewQuote{Text: "Quote 1"})
+	s.Assert().Nil(err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateInvalidVisibility() {
+	_, err := s.uc.Create(s.user, quote.NewQuote{Text: "Quote 1", Visibility: "friends"})
+	s.Assert().Equal(exceptions.InvalidVisibility, err)
+	s.repo.AssertNotCalled(s.T(), "Create", mock.Anything)
+}
+
+func (s *QuoteUsecaseTestSuite) TestCreateThrowServerError() {
+	s.repo.On("Create", mock.Anything).Return(models.QuoteModel{}, exceptions.ServerError)
+	_, err := s.uc.Create(s.user, quote.NewQuote{Text: "Quote 1"})
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindAllSuccess() {
+	s.repo.On("List", s.user.ID, noFilter, firstPage).Return([]models.QuoteModel{{ID: 1, UserID: 1, Text: "Quote 1"}}, nil)
+	page, err := s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 10})
+	s.Assert().Nil(err)
+	s.Assert().Len(page.Items, 1)
+	s.Assert().Equal([]string{}, page.Items[0].Tags)
+	s.Assert().Empty(page.NextCursor)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindHiddenQuoteReportsNotExists() {
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(false, models.QuoteModel{}, nil)
+	_, err := s.uc.Find(s.user, 2)
+	s.Assert().Equal(exceptions.QuoteNotExists, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindVisibleQuoteOfOthers() {
+	s.repo.On("FindVisible", int64(1), int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 9, Visibility: "public"}, nil)
+	s.repo.On("Owners", []int64{9}).Return(map[int64]models.UserModel{9: {ID: 9, Name: "Amy"}}, nil)
+	q, err := s.uc.Find(s.user, 2)
+	s.Assert().Nil(err)
+	s.Assert().Equal(&models.Profile{ID: 9, Name: "Amy"}, q.Owner)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindByUser() {
+	s.repo.On("FindByUser", int64(9), int64(1), noFilter, firstPage).Return([]models.QuoteModel{{ID: 2, UserID: 9, Visibility: "public"}}, nil)
+	s.repo.On("Owners", []int64{9}).Return(map[int64]models.UserModel{9: {ID: 9, Name: "Amy"}}, nil)
+	page, err := s.uc.FindByUser(s.user, 9, quote.TagFilter{}, quote.PageRequest{Limit: 10})
+	s.Assert().Nil(err)
+	s.Assert().Len(page.Items, 1)
+	s.Assert().Equal("public", page.Items[0].Visibility)
+}
+
+func (s *QuoteUsecaseTestSuite) TestUpdateNotExists() {
+	s.repo.On("Find", int64(2)).Return(false, models.QuoteModel{}, nil)
+	_, err := s.uc.Update(s.user, 2, quote.NewQuote{Text: "changed"})
+	s.Assert().Equal(exceptions.QuoteNotExists, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestUpdateOthersQuoteForbidden() {
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 9}, nil)
+	_, err := s.uc.Update(s.user, 2, quote.NewQuote{Text: "changed"})
+	s.Assert().Equal(exceptions.Forbidden, err)
+	s.repo.AssertNotCalled(s.T(), "Update", mock.Anything)
+}
+
+func (s *QuoteUsecaseTestSuite) TestUpdateSuccess() {
+	m := models.QuoteModel{ID: 2, UserID: 1, Text: "Quote 2"}
+	changed := models.QuoteModel{ID: 2, UserID: 1, Text: "changed", Book: "Book1"}
+	s.repo.On("Find", int64(2)).Return(true, m, nil).Once()
+	s.repo.On("Update", changed).Return(nil)
+	s.repo.On("Find", int64(2)).Return(true, changed, nil)
+
+	actual, err := s.uc.Update(s.user, 2, quote.NewQuote{Text: "changed", Book: "Book1"})
+	s.Assert().Nil(err)
+	s.Assert().Equal("changed", actual.Text)
+}
+
+func (s *QuoteUsecaseTestSuite) TestDeleteOthersQuoteForbidden() {
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 9}, nil)
+	err := s.uc.Delete(s.user, 2)
+	s.Assert().Equal(exceptions.Forbidden, err)
+	s.repo.AssertNotCalled(s.T(), "Delete", mock.Anything)
+}
+
+func (s *QuoteUsecaseTestSuite) TestDeleteSuccess() {
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1}, nil)
+	s.repo.On("Delete", int64(2)).Return(nil)
+	err := s.uc.Delete(s.user, 2)
+	s.Assert().Nil(err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFeed() {
+	s.repo.On("Feed", int64(1), firstPage).Return([]models.QuoteModel{{ID: 5, UserID: 2, Text: "Quote 5"}, {ID: 6, UserID: 2, Text: "Quote 6"}}, nil)
+	s.repo.On("Owners", []int64{2}).Return(map[int64]models.UserModel{2: {ID: 2, Name: "Leslie", Email: "leslie@gmail.com"}}, nil)
+	page, err := s.uc.Feed(s.user, quote.PageRequest{Limit: 10})
+	s.Assert().Nil(err)
+	s.Assert().Len(page.Items, 2)
+	s.Assert().Equal(&models.Profile{ID: 2, Name: "Leslie"}, page.Items[0].Owner)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFeedInvalidLimit() {
+	_, err := s.uc.Feed(s.user, quote.PageRequest{Limit: 0})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Feed(s.user, quote.PageRequest{Limit: MAX_PAGE_SIZE + 1})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindAllNextCursor() {
+	created := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	found := []models.QuoteModel{
+		{ID: 3, UserID: 1, Book: "C", CreatedAt: created.Add(2 * time.Hour)},
+		{ID: 2, UserID: 1, Book: "B", CreatedAt: created.Add(time.Hour)},
+		{ID: 1, UserID: 1, Book: "A", CreatedAt: created},
+	}
+	p := quote.Page{Sort: quote.SORT_CREATED_AT, Order: quote.DESC, Limit: 3}
+	s.repo.On("List", s.user.ID, noFilter, p).Return(found, nil).Once()
+
+	first, err := s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 2})
+	s.Require().Nil(err)
+	s.Assert().Len(first.Items, 2)
+	s.Require().NotEmpty(first.NextCursor)
+
+	p.After = &quote.Cursor{Sort: quote.SORT_CREATED_AT, Order: quote.DESC, Time: created.Add(time.Hour), ID: 2}
+	s.repo.On("List", s.user.ID, noFilter, p).Return(found[2:], nil).Once()
+	second, err := s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 2, Cursor: first.NextCursor})
+	s.Assert().Nil(err)
+	s.Assert().Len(second.Items, 1)
+	s.Assert().Empty(second.NextCursor)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindAllInvalidPage() {
+	_, err := s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 10, Sort: "text"})
+	s.Assert().Equal(exceptions.InvalidSort, err)
+	_, err = s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 10, Order: "up"})
+	s.Assert().Equal(exceptions.InvalidSort, err)
+	_, err = s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 10, Cursor: "not a cursor"})
+	s.Assert().Equal(exceptions.InvalidCursor, err)
+
+	cursor := encodeCursor(quote.Cursor{Sort: quote.SORT_BOOK, Order: quote.ASC, Book: "A", ID: 1})
+	_, err = s.uc.FindAll(s.user, quote.TagFilter{}, quote.PageRequest{Limit: 10, Cursor: cursor, Sort: quote.SORT_CREATED_AT})
+	s.Assert().Equal(exceptions.InvalidCursor, err)
+	s.repo.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindAllInvalidMatch() {
+	_, err := s.uc.FindAll(s.user, quote.TagFilter{Tags: []string{"life"}, Match: "some"}, quote.PageRequest{Limit: 10})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	s.repo.AssertNotCalled(s.T(), "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *QuoteUsecaseTestSuite) TestFindAllByTags() {
+	filter := quote.TagFilter{Tags: []string{"life", "love"}, Match: quote.MATCH_ANY}
+	s.repo.On("List", s.user.ID, filter, firstPage).Return([]models.QuoteModel{{ID: 1, UserID: 1, Tags: "life"}}, nil)
+	page, err := s.uc.FindAll(s.user, quote.TagFilter{Tags: []string{" life", "love", "life", ""}, Match: quote.MATCH_ANY}, quote.PageRequest{Limit: 10})
+	s.Assert().Nil(err)
+	s.Assert().Len(page.Items, 1)
+}
+
+func (s *QuoteUsecaseTestSuite) TestAddTags() {
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1, Tags: "life"}, nil).Once()
+	s.repo.On("Update", models.QuoteModel{ID: 2, UserID: 1, Tags: "life,love"}).Return(nil)
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1, Tags: "life,love"}, nil)
+
+	actual, err := s.uc.AddTags(s.user, 2, quote.TagsUpdate{Tags: []string{"love", "life"}})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]string{"life", "love"}, actual.Tags)
+}
+
+func (s *QuoteUsecaseTestSuite) TestRemoveTags() {
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1, Tags: "life,love"}, nil).Once()
+	s.repo.On("Update", models.QuoteModel{ID: 2, UserID: 1, Tags: "love"}).Return(nil)
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 1, Tags: "love"}, nil)
+
+	actual, err := s.uc.RemoveTags(s.user, 2, quote.TagsUpdate{Tags: []string{"life"}})
+	s.Assert().Nil(err)
+	s.Assert().Equal([]string{"love"}, actual.Tags)
+}
+
+func (s *QuoteUsecaseTestSuite) TestAddTagsInvalid() {
+	_, err := s.uc.AddTags(s.user, 2, quote.TagsUpdate{Tags: []string{"a,b"}})
+	s.Assert().Equal(exceptions.InvalidTag, err)
+	_, err = s.uc.AddTags(s.user, 2, quote.TagsUpdate{Tags: []string{" "}})
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	s.repo.AssertNotCalled(s.T(), "Update", mock.Anything)
+}
+
+func (s *QuoteUsecaseTestSuite) TestAddTagsOthersQuoteForbidden() {
+	s.repo.On("Find", int64(2)).Return(true, models.QuoteModel{ID: 2, UserID: 9}, nil)
+	_, err := s.uc.AddTags(s.user, 2, quote.TagsUpdate{Tags: []string{"life"}})
+	s.Assert().Equal(exceptions.Forbidden, err)
+	s.repo.AssertNotCalled(s.T(), "Update", mock.Anything)
+}
@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/stats"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	logger  domain.Logger
+	statsUc stats.Usecase
+}
+
+const STATS_ENDPOINT = "/api/stats"
+const DEFAULT_WEEKS = 12
+const DEFAULT_LIMIT = 5
+
+// NewStatsHTTPHandler registers the stats routes.
+func NewStatsHTTPHandler(c *gin.Engine, l domain.Logger, uc stats.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, statsUc: uc}
+	g := c.Group(STATS_ENDPOINT, middlewares...)
+	g.GET("", handler.stats)
+}
+
+// stats summarizes the quotes of the user, like GET /api/stats?weeks=12&limit=5
+func (h *handler) stats(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	weeks, err := strconv.Atoi(c.DefaultQuery("weeks", strconv.Itoa(DEFAULT_WEEKS)))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DEFAULT_LIMIT)))
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	s, err := h.statsUc.Stats(user, weeks, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
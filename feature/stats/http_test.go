@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedStatsUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedStatsUsecase) Stats(user models.User, weeks int, limit int) (models.Stats, error) {
+	args := m.Called(user, weeks, limit)
+	return args.Get(0).(models.Stats), args.Error(1)
+}
+
+type StatsTestSuite struct {
+	suite.Suite
+	uc   *MockedStatsUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestStatsHTTPHandler(t *testing.T) {
+	suite.Run(t, new(StatsTestSuite))
+}
+
+func (s *StatsTestSuite) SetupTest() {
+	s.uc = new(MockedStatsUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *StatsTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *StatsTestSuite) TestStatsWithDefaults() {
+	expected := models.Stats{Streak: models.Streak{Current: 2, Longest: 5}}
+	s.uc.On("Stats", s.user, DEFAULT_WEEKS, DEFAULT_LIMIT).Return(expected, nil)
+	NewStatsHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, STATS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Stats
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(expected, actual)
+}
+
+func (s *StatsTestSuite) TestStatsWithQuery() {
+	s.uc.On("Stats", s.user, 4, 10).Return(models.Stats{}, nil)
+	NewStatsHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, STATS_ENDPOINT+"?weeks=4&limit=10", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+}
+
+func (s *StatsTestSuite) TestStatsInvalidQuery() {
+	NewStatsHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, STATS_ENDPOINT+"?weeks=many", nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "Stats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *StatsTestSuite) TestStatsServerError() {
+	s.uc.On("Stats", s.user, DEFAULT_WEEKS, DEFAULT_LIMIT).Return(models.Stats{}, exceptions.ServerError)
+	NewStatsHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, STATS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+}
+
+func (s *StatsTestSuite) TestStatsUnauthorized() {
+	NewStatsHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, STATS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+}
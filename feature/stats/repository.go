@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/book"
+	"myquote/domain/models"
+	"myquote/domain/stats"
+	"time"
+)
+
+// Repository reads the tables of the quote and book features, it has no table of its own.
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+func (r *Repository) Added(userID int64, since time.Time) ([]time.Time, error) {
+	var added []time.Time
+	result := r.db.Model(&models.QuoteModel{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at").
+		Pluck("created_at", &added)
+	if result.Error != nil {
+		r.l.Debugf("find added quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return added, nil
+}
+
+func (r *Repository) MostDelivered(userID int64, limit int) ([]stats.QuoteCount, error) {
+	var counts []stats.QuoteCount
+	result := r.delivered(userID).
+		Having("COUNT(quote_draws.id) > 0").
+		Order("deliveries DESC, quotes.id").
+		Limit(limit).
+		Scan(&counts)
+	if result.Error != nil {
+		r.l.Debugf("find most delivered quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return counts, nil
+}
+
+func (r *Repository) LeastDelivered(userID int64, limit int) ([]stats.QuoteCount, error) {
+	var counts []stats.QuoteCount
+	result := r.delivered(userID).
+		Order("deliveries, quotes.id").
+		Limit(limit).
+		Scan(&counts)
+	if result.Error != nil {
+		r.l.Debugf("find least delivered quotes error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return counts, nil
+}
+
+// delivered selects the quotes of userID together with how many times they were delivered to userID.
+func (r *Repository) delivered(userID int64) *gorm.DB {
+	return r.db.Model(&models.QuoteModel{}).
+		Select("quotes.*, COUNT(quote_draws.id) AS deliveries").
+		Joins("LEFT JOIN quote_draws ON quote_draws.quote_id = quotes.id AND quote_draws.user_id = ?", userID).
+		Where("quotes.user_id = ?", userID).
+		Group("quotes.id")
+}
+
+func (r *Repository) TopBooks(userID int64, limit int) ([]book.BookCount, error) {
+	var books []book.BookCount
+	result := r.db.Model(&models.BookModel{}).
+		Select("books.*, COUNT(quotes.id) AS quote_count").
+		Joins("JOIN quotes ON quotes.book_id = books.id").
+		Where("books.user_id = ?", userID).
+		Group("books.id").
+		Order("quote_count DESC, books.title, books.id").
+		Limit(limit).
+		Scan(&books)
+	if result.Error != nil {
+		r.l.Debugf("find top books error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return books, nil
+}
+
+func (r *Repository) Deliveries(userID int64) ([]time.Time, error) {
+	var deliveries []time.Time
+	result := r.db.Model(&models.QuoteDrawModel{}).
+		Where("user_id = ?", userID).
+		Order("drawn_at").
+		Pluck("drawn_at", &deliveries)
+	if result.Error != nil {
+		r.l.Debugf("find deliveries error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return deliveries, nil
+}
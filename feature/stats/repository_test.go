@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/domain/stats"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"myquote/service/quotelink"
+	"testing"
+	"time"
+)
+
+type StatsRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+	now  time.Time
+}
+
+func TestStatsRepository(t *testing.T) {
+	suite.Run(t, new(StatsRepositoryTestSuite))
+}
+
+func (s *StatsRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.QuoteModel{}, &models.QuoteDrawModel{}, &models.BookModel{}, &models.ChapterModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.now = time.Date(2022, 5, 4, 8, 0, 0, 0, time.UTC)
+}
+
+func (s *StatsRepositoryTestSuite) quote(userID int64, text string, book string, daysAgo int) models.QuoteModel {
+	q := models.QuoteModel{UserID: userID, Text: text, Book: book, CreatedAt: s.now.AddDate(0, 0, -daysAgo)}
+	s.Require().NoError(quotelink.New(s.db).Book(&q))
+	s.Require().NoError(s.db.Create(&q).Error)
+	return q
+}
+
+func (s *StatsRepositoryTestSuite) deliver(userID int64, q models.QuoteModel, times int) {
+	for i := 0; i < times; i++ {
+		s.Require().NoError(s.db.Create(&models.QuoteDrawModel{UserID: userID, QuoteID: q.ID, Channel: "api", DrawnAt: s.now.AddDate(0, 0, -i)}).Error)
+	}
+}
+
+func texts(counts []stats.QuoteCount) []string {
+	var result []string
+	for _, c := range counts {
+		result = append(result, c.Text)
+	}
+	return result
+}
+
+func (s *StatsRepositoryTestSuite) TestAdded() {
+	s.quote(1, "old", "", 30)
+	s.quote(1, "new", "", 1)
+	s.quote(2, "other", "", 1)
+
+	added, err := s.repo.Added(1, s.now.AddDate(0, 0, -7))
+	s.Assert().Nil(err)
+	s.Require().Len(added, 1)
+	s.Assert().True(s.now.AddDate(0, 0, -1).Equal(added[0]))
+}
+
+func (s *StatsRepositoryTestSuite) TestMostAndLeastDelivered() {
+	often := s.quote(1, "often", "", 0)
+	once := s.quote(1, "once", "", 0)
+	s.quote(1, "never", "", 0)
+	s.deliver(1, often, 3)
+	s.deliver(1, once, 1)
+	// deliveries to other users do not count
+	s.deliver(2, once, 5)
+
+	most, err := s.repo.MostDelivered(1, 5)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]string{"often", "once"}, texts(most))
+	s.Assert().Equal(int64(3), most[0].Deliveries)
+
+	least, err := s.repo.LeastDelivered(1, 2)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]string{"never", "once"}, texts(least))
+	s.Assert().Equal(int64(0), least[0].Deliveries)
+}
+
+func (s *StatsRepositoryTestSuite) TestTopBooks() {
+	s.quote(1, "Quote 1", "Book1", 0)
+	s.quote(1, "Quote 2", "Book2", 0)
+	s.quote(1, "Quote 3", "Book2", 0)
+	s.quote(1, "Quote 4", "", 0)
+	s.quote(2, "Quote 5", "Book3", 0)
+
+	books, err := s.repo.TopBooks(1, 5)
+	s.Assert().Nil(err)
+	s.Require().Len(books, 2)
+	s.Assert().Equal("Book2", books[0].Title)
+	s.Assert().Equal(int64(2), books[0].QuoteCount)
+	s.Assert().Equal("Book1", books[1].Title)
+}
+
+func (s *StatsRepositoryTestSuite) TestDeliveries() {
+	q := s.quote(1, "Quote 1", "", 0)
+	s.deliver(1, q, 2)
+	s.deliver(2, q, 1)
+
+	deliveries, err := s.repo.Deliveries(1)
+	s.Assert().Nil(err)
+	s.Require().Len(deliveries, 2)
+	s.Assert().True(s.now.AddDate(0, 0, -1).Equal(deliveries[0]))
+	s.Assert().True(s.now.Equal(deliveries[1]))
+}
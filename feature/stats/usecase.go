@@ -0,0 +1,153 @@
+package stats
+
+import (
+	"myquote/domain"
+	"myquote/domain/book"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/stats"
+	"sort"
+	"time"
+	_ "time/tzdata"
+)
+
+const MAX_WEEKS = 52
+const MAX_LIMIT = 20
+
+// DATE_LAYOUT formats the first day of a week.
+const DATE_LAYOUT = "2006-01-02"
+
+type Usecase struct {
+	l   domain.Logger
+	r   stats.Repository
+	now func() time.Time
+}
+
+func NewUsecase(logger domain.Logger, repository stats.Repository) *Usecase {
+	return &Usecase{l: logger, r: repository, now: time.Now}
+}
+
+// Stats counts weeks and days in the time zone of the user, weeks start on Monday and the
+// current week is the last one.
+func (uc *Usecase) Stats(user models.User, weeks int, limit int) (models.Stats, error) {
+	if weeks < 1 || weeks > MAX_WEEKS || limit < 1 || limit > MAX_LIMIT {
+		return models.Stats{}, exceptions.InvalidInput
+	}
+	loc, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := uc.now()
+	first := startOfWeek(now.In(loc)).AddDate(0, 0, -7*(weeks-1))
+
+	// the times are compared as they are stored, in the zone of the server
+	added, err := uc.r.Added(user.ID, first.In(now.Location()))
+	if err != nil {
+		return models.Stats{}, exceptions.ServerError
+	}
+	most, err := uc.r.MostDelivered(user.ID, limit)
+	if err != nil {
+		return models.Stats{}, exceptions.ServerError
+	}
+	least, err := uc.r.LeastDelivered(user.ID, limit)
+	if err != nil {
+		return models.Stats{}, exceptions.ServerError
+	}
+	books, err := uc.r.TopBooks(user.ID, limit)
+	if err != nil {
+		return models.Stats{}, exceptions.ServerError
+	}
+	deliveries, err := uc.r.Deliveries(user.ID)
+	if err != nil {
+		return models.Stats{}, exceptions.ServerError
+	}
+
+	return models.Stats{
+		AddedPerWeek:  perWeek(added, first, weeks, loc),
+		MostReviewed:  toReviewCounts(most),
+		LeastReviewed: toReviewCounts(least),
+		TopBooks:      toBooks(books),
+		Streak:        streak(days(deliveries, loc), now.In(loc)),
+	}, nil
+}
+
+// perWeek counts the times into the weeks starting on first, a week without quotes counts zero.
+func perWeek(added []time.Time, first time.Time, weeks int, loc *time.Location) []models.WeekCount {
+	counts := make([]models.WeekCount, weeks)
+	for i := range counts {
+		counts[i].Week = first.AddDate(0, 0, 7*i).Format(DATE_LAYOUT)
+	}
+	for _, t := range added {
+		d := int(date(t.In(loc)).Sub(date(first)).Hours() / 24)
+		if d >= 0 && d/7 < weeks {
+			counts[d/7].Quotes++
+		}
+	}
+	return counts
+}
+
+// days returns the distinct local days of the times, as midnight UTC so one day is always 24 hours.
+func days(times []time.Time, loc *time.Location) []time.Time {
+	seen := map[time.Time]bool{}
+	var result []time.Time
+	for _, t := range times {
+		d := date(t.In(loc))
+		if !seen[d] {
+			seen[d] = true
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// streak finds the longest run of consecutive days and the run ending today. A run ending
+// yesterday is still current, today may bring a review.
+func streak(days []time.Time, now time.Time) models.Streak {
+	var s models.Streak
+	run := 0
+	for i, d := range days {
+		if i > 0 && d.Sub(days[i-1]) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > s.Longest {
+			s.Longest = run
+		}
+	}
+	if len(days) == 0 {
+		return s
+	}
+	today := date(now)
+	last := days[len(days)-1]
+	if last.Equal(today) || last.Equal(today.AddDate(0, 0, -1)) {
+		s.Current = run
+	}
+	return s
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func toReviewCounts(counts []stats.QuoteCount) []models.ReviewCount {
+	result := make([]models.ReviewCount, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, models.ReviewCount{Quote: c.QuoteModel.ToQuote(), Reviews: c.Deliveries})
+	}
+	return result
+}
+
+func toBooks(books []book.BookCount) []models.Book {
+	result := make([]models.Book, 0, len(books))
+	for _, b := range books {
+		result = append(result, b.ToBook())
+	}
+	return result
+}
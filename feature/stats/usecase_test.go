@@ -0,0 +1,140 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/book"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/stats"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type MockedStatsRepo struct {
+	mock.Mock
+}
+
+func (m *MockedStatsRepo) Added(userID int64, since time.Time) ([]time.Time, error) {
+	args := m.Called(userID, since)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockedStatsRepo) MostDelivered(userID int64, limit int) ([]stats.QuoteCount, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]stats.QuoteCount), args.Error(1)
+}
+
+func (m *MockedStatsRepo) LeastDelivered(userID int64, limit int) ([]stats.QuoteCount, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]stats.QuoteCount), args.Error(1)
+}
+
+func (m *MockedStatsRepo) TopBooks(userID int64, limit int) ([]book.BookCount, error) {
+	args := m.Called(userID, limit)
+	return args.Get(0).([]book.BookCount), args.Error(1)
+}
+
+func (m *MockedStatsRepo) Deliveries(userID int64) ([]time.Time, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+type StatsUsecaseTestSuite struct {
+	suite.Suite
+	uc   *Usecase
+	repo *MockedStatsRepo
+	user models.User
+	now  time.Time
+}
+
+func TestNewStatsUsecase(t *testing.T) {
+	suite.Run(t, new(StatsUsecaseTestSuite))
+}
+
+func (s *StatsUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedStatsRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo)
+	// Wednesday 16:00 in Taipei
+	s.now = time.Date(2022, 5, 4, 8, 0, 0, 0, time.UTC)
+	s.uc.now = func() time.Time { return s.now }
+	s.user = models.User{ID: 1, TimeZone: "Asia/Taipei"}
+}
+
+func (s *StatsUsecaseTestSuite) mockLists() {
+	s.repo.On("MostDelivered", int64(1), 5).Return([]stats.QuoteCount{{QuoteModel: models.QuoteModel{ID: 2, Text: "often", Tags: "life"}, Deliveries: 4}}, nil)
+	s.repo.On("LeastDelivered", int64(1), 5).Return([]stats.QuoteCount{{QuoteModel: models.QuoteModel{ID: 3, Text: "never"}}}, nil)
+	s.repo.On("TopBooks", int64(1), 5).Return([]book.BookCount{{BookModel: models.BookModel{ID: 1, Title: "Book1"}, QuoteCount: 7}}, nil)
+}
+
+func utc(month time.Month, day int, hour int) time.Time {
+	return time.Date(2022, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func (s *StatsUsecaseTestSuite) TestStats() {
+	// the first of three weeks starts on Monday 18 April in Taipei, 16:00 the day before in UTC
+	s.repo.On("Added", int64(1), utc(time.April, 17, 16)).Return([]time.Time{
+		utc(time.April, 17, 17), // Monday 01:00 in Taipei
+		utc(time.May, 1, 15),    // Sunday 23:00
+		utc(time.May, 1, 20),    // Monday 04:00
+	}, nil)
+	s.mockLists()
+	s.repo.On("Deliveries", int64(1)).Return([]time.Time{
+		utc(time.April, 20, 1), utc(time.April, 21, 1),
+		utc(time.April, 25, 1), utc(time.April, 26, 1), utc(time.April, 26, 2), utc(time.April, 27, 1),
+		utc(time.May, 2, 17), // 3 May 01:00 in Taipei
+		utc(time.May, 3, 17), // 4 May 01:00 in Taipei
+	}, nil)
+
+	actual, err := s.uc.Stats(s.user, 3, 5)
+	s.Require().Nil(err)
+	s.Assert().Equal([]models.WeekCount{{Week: "2022-04-18", Quotes: 1}, {Week: "2022-04-25", Quotes: 1}, {Week: "2022-05-02", Quotes: 1}}, actual.AddedPerWeek)
+	s.Assert().Equal([]models.ReviewCount{{Quote: models.Quote{ID: 2, Text: "often", Tags: []string{"life"}}, Reviews: 4}}, actual.MostReviewed)
+	s.Assert().Equal(int64(0), actual.LeastReviewed[0].Reviews)
+	s.Assert().Equal([]models.Book{{ID: 1, Title: "Book1", QuoteCount: 7}}, actual.TopBooks)
+	s.Assert().Equal(models.Streak{Current: 2, Longest: 3}, actual.Streak)
+}
+
+func (s *StatsUsecaseTestSuite) TestUnknownTimeZoneCountsInUTC() {
+	s.user.TimeZone = ""
+	s.repo.On("Added", int64(1), utc(time.May, 2, 0)).Return([]time.Time{utc(time.May, 1, 20)}, nil)
+	s.mockLists()
+	s.repo.On("Deliveries", int64(1)).Return([]time.Time{}, nil)
+
+	actual, err := s.uc.Stats(s.user, 1, 5)
+	s.Require().Nil(err)
+	s.Assert().Equal([]models.WeekCount{{Week: "2022-05-02", Quotes: 0}}, actual.AddedPerWeek)
+	s.Assert().Equal(models.Streak{}, actual.Streak)
+}
+
+func (s *StatsUsecaseTestSuite) TestInvalidInput() {
+	_, err := s.uc.Stats(s.user, 0, 5)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Stats(s.user, MAX_WEEKS+1, 5)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Stats(s.user, 12, 0)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	_, err = s.uc.Stats(s.user, 12, MAX_LIMIT+1)
+	s.Assert().Equal(exceptions.InvalidInput, err)
+	s.repo.AssertNotCalled(s.T(), "Added", mock.Anything, mock.Anything)
+}
+
+func (s *StatsUsecaseTestSuite) TestThrowServerError() {
+	s.repo.On("Added", int64(1), mock.Anything).Return([]time.Time{}, nil)
+	s.mockLists()
+	s.repo.On("Deliveries", int64(1)).Return([]time.Time{}, exceptions.ServerError)
+	_, err := s.uc.Stats(s.user, 12, 5)
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *StatsUsecaseTestSuite) TestStreak() {
+	today := time.Date(2022, 5, 4, 23, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2022, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	s.Assert().Equal(models.Streak{}, streak(nil, today))
+	// yesterday keeps the streak going, today may still bring a review
+	s.Assert().Equal(models.Streak{Current: 2, Longest: 2}, streak([]time.Time{day(2), day(3)}, today))
+	s.Assert().Equal(models.Streak{Current: 0, Longest: 2}, streak([]time.Time{day(1), day(2)}, today))
+	s.Assert().Equal(models.Streak{Current: 1, Longest: 1}, streak([]time.Time{day(1), day(4)}, today))
+}
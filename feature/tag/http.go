@@ -0,0 +1,64 @@
+package tag
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/tag"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	logger domain.Logger
+	tagUc  tag.Usecase
+}
+
+const TAGS_ENDPOINT = "/api/tags"
+
+// NewTagHTTPHandler registers the tag routes.
+func NewTagHTTPHandler(c *gin.Engine, l domain.Logger, uc tag.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, tagUc: uc}
+	g := c.Group(TAGS_ENDPOINT, middlewares...)
+	g.GET("", handler.findAll)
+	g.PATCH("/:id", handler.rename)
+}
+
+func (h *handler) findAll(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	tags, err := h.tagUc.FindAll(user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *handler) rename(c *gin.Context) {
+	user, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	var r tag.Rename
+	err = c.Bind(&r)
+	if err != nil {
+		h.logger.Debugf("Convert tag json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	renamed, err := h.tagUc.Rename(user, id, r)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, renamed)
+}
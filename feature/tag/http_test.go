@@ -0,0 +1,110 @@
+package tag
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/tag"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedTagUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedTagUsecase) FindAll(user models.User) ([]models.Tag, error) {
+	args := m.Called(user)
+	return args.Get(0).([]models.Tag), args.Error(1)
+}
+
+func (m *MockedTagUsecase) Rename(user models.User, id int64, r tag.Rename) (models.Tag, error) {
+	args := m.Called(user, id, r)
+	return args.Get(0).(models.Tag), args.Error(1)
+}
+
+type TagTestSuite struct {
+	suite.Suite
+	uc   *MockedTagUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestTagHTTPHandler(t *testing.T) {
+	suite.Run(t, new(TagTestSuite))
+}
+
+func (s *TagTestSuite) SetupTest() {
+	s.uc = new(MockedTagUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *TagTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *TagTestSuite) TestFindAll() {
+	s.uc.On("FindAll", s.user).Return([]models.Tag{{ID: 1, Name: "life", QuoteCount: 2}}, nil)
+	NewTagHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, TAGS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual []models.Tag
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal([]models.Tag{{ID: 1, Name: "life", QuoteCount: 2}}, actual)
+}
+
+func (s *TagTestSuite) TestRename() {
+	s.uc.On("Rename", s.user, int64(1), tag.Rename{Name: "living"}).Return(models.Tag{ID: 1, Name: "living", QuoteCount: 2}, nil)
+	NewTagHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, TAGS_ENDPOINT+"/1", []byte(`{"name":"living"}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.Tag
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal("living", actual.Name)
+}
+
+func (s *TagTestSuite) TestRenameNotExists() {
+	s.uc.On("Rename", s.user, int64(3), mock.Anything).Return(models.Tag{}, exceptions.TagNotExists)
+	NewTagHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, TAGS_ENDPOINT+"/3", []byte(`{"name":"living"}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusNotFound, s.r.Code)
+	s.Assert().Equal(exceptions.TagNotExists.Error(), m.Message)
+}
+
+func (s *TagTestSuite) TestRespondUnauthorizedWithoutUser() {
+	NewTagHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, TAGS_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "FindAll", mock.Anything)
+}
@@ -0,0 +1,133 @@
+package tag
+
+import (
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/models"
+	"myquote/domain/tag"
+	"myquote/service/quotelink"
+)
+
+const LINK_BATCH_SIZE = 100
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+// Migrate creates or updates the tags tables and links the quotes saved before tags existed.
+// It needs the quotes table.
+func (r *Repository) Migrate() error {
+	err := r.db.AutoMigrate(&models.TagModel{}, &models.QuoteTagModel{})
+	if err != nil {
+		r.l.Errorf("migrate tags table error: %s", err.Error())
+		return err
+	}
+
+	var quotes []models.QuoteModel
+	result := r.db.
+		Where("tags <> '' AND NOT EXISTS (SELECT 1 FROM quote_tags WHERE quote_tags.quote_id = quotes.id)").
+		FindInBatches(&quotes, LINK_BATCH_SIZE, func(tx *gorm.DB, batch int) error {
+			link := quotelink.New(tx)
+			for _, q := range quotes {
+				err := link.Tags(q, "")
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if result.Error != nil {
+		r.l.Errorf("link quotes to tags error: %s", result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
+
+func (r *Repository) FindAll(userID int64) ([]tag.TagCount, error) {
+	var tags []tag.TagCount
+	result := r.counted().Where("tags.user_id = ?", userID).Order("tags.name").Scan(&tags)
+	if result.Error != nil {
+		r.l.Debugf("find tags error, user id: %d\n The error message: %s", userID, result.Error.Error())
+		return nil, result.Error
+	}
+	return tags, nil
+}
+
+func (r *Repository) Find(id int64) (bool, tag.TagCount, error) {
+	var tags []tag.TagCount
+	result := r.counted().Where("tags.id = ?", id).Scan(&tags)
+	if result.Error != nil {
+		r.l.Debugf("find tag error, tag id: %d\n The error message: %s", id, result.Error.Error())
+		return false, tag.TagCount{}, result.Error
+	}
+	if len(tags) == 0 {
+		return false, tag.TagCount{}, nil
+	}
+	return true, tags[0], nil
+}
+
+// counted selects the tags together with how many quotes they are on, tags left on no quote count 0.
+func (r *Repository) counted() *gorm.DB {
+	return r.db.Model(&models.TagModel{}).
+		Select("tags.*, COUNT(quote_tags.quote_id) AS quote_count").
+		Joins("LEFT JOIN quote_tags ON quote_tags.tag_id = tags.id").
+		Group("tags.id")
+}
+
+func (r *Repository) Rename(t models.TagModel, name string) (int64, error) {
+	id := t.ID
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var quotes []models.QuoteModel
+		err := tx.Joins("JOIN quote_tags ON quote_tags.quote_id = quotes.id").Where("quote_tags.tag_id = ?", t.ID).Find(&quotes).Error
+		if err != nil {
+			return err
+		}
+		for _, q := range quotes {
+			names := models.SplitTags(q.Tags)
+			for i := range names {
+				if names[i] == t.Name {
+					names[i] = name
+				}
+			}
+			// the links are moved below
+			err = tx.Model(&q).UpdateColumn("tags", models.JoinTags(names)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var existing []models.TagModel
+		err = tx.Where("user_id = ? AND name = ?", t.UserID, name).Limit(1).Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		// MySQL compares the names without case, so a rename that only changes the case finds the tag itself
+		if len(existing) == 0 || existing[0].ID == t.ID {
+			return tx.Model(&t).Update("name", name).Error
+		}
+
+		id = existing[0].ID
+		err = tx.Exec(
+			"INSERT INTO quote_tags (quote_id, tag_id) SELECT quote_id, ? FROM quote_tags WHERE tag_id = ? AND quote_id NOT IN (SELECT quote_id FROM quote_tags WHERE tag_id = ?)",
+			id, t.ID, id,
+		).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("tag_id = ?", t.ID).Delete(&models.QuoteTagModel{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.TagModel{}, t.ID).Error
+	})
+	if err != nil {
+		r.l.Debugf("rename tag error, tag id: %d\n The error message: %s", t.ID, err.Error())
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,122 @@
+package tag
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"myquote/service/quotelink"
+	"testing"
+)
+
+type TagRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+func TestTagRepository(t *testing.T) {
+	suite.Run(t, new(TagRepositoryTestSuite))
+}
+
+func (s *TagRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.QuoteModel{}, &models.BookModel{}, &models.ChapterModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+	s.Require().NoError(s.repo.Migrate())
+}
+
+func (s *TagRepositoryTestSuite) quote(userID int64, tags string) models.QuoteModel {
+	q := models.QuoteModel{UserID: userID, Text: "Quote", Tags: tags}
+	s.Require().NoError(s.db.Create(&q).Error)
+	s.Require().NoError(quotelink.New(s.db).Tags(q, ""))
+	return q
+}
+
+func (s *TagRepositoryTestSuite) tag(userID int64, name string) models.TagModel {
+	var t models.TagModel
+	s.Require().NoError(s.db.Where("user_id = ? AND name = ?", userID, name).First(&t).Error)
+	return t
+}
+
+func (s *TagRepositoryTestSuite) TestFindAllCountsQuotes() {
+	s.quote(1, "life,love")
+	s.quote(1, "life")
+	s.quote(2, "work")
+	s.db.Create(&models.TagModel{UserID: 1, Name: "unused"})
+
+	tags, err := s.repo.FindAll(1)
+	s.Assert().Nil(err)
+	s.Require().Len(tags, 3)
+	s.Assert().Equal("life", tags[0].Name)
+	s.Assert().Equal(int64(2), tags[0].QuoteCount)
+	s.Assert().Equal("love", tags[1].Name)
+	s.Assert().Equal(int64(1), tags[1].QuoteCount)
+	s.Assert().Equal("unused", tags[2].Name)
+	s.Assert().Zero(tags[2].QuoteCount)
+}
+
+func (s *TagRepositoryTestSuite) TestMigrateLinksOldQuotes() {
+	q := s.quote(1, "life,love")
+	s.db.Where("quote_id = ?", q.ID).Delete(&models.QuoteTagModel{})
+
+	s.Require().NoError(s.repo.Migrate())
+	s.Require().NoError(s.repo.Migrate())
+	var links int64
+	s.db.Model(&models.QuoteTagModel{}).Where("quote_id = ?", q.ID).Count(&links)
+	s.Assert().Equal(int64(2), links)
+}
+
+func (s *TagRepositoryTestSuite) TestRename() {
+	q := s.quote(1, "life,love")
+	life := s.tag(1, "life")
+
+	id, err := s.repo.Rename(life, "living")
+	s.Assert().Nil(err)
+	s.Assert().Equal(life.ID, id)
+
+	_, renamed, _ := s.repo.Find(id)
+	s.Assert().Equal("living", renamed.Name)
+	s.db.First(&q, q.ID)
+	s.Assert().Equal("living,love", q.Tags)
+}
+
+func (s *TagRepositoryTestSuite) TestRenameOnlyCase() {
+	q := s.quote(1, "life,love")
+	life := s.tag(1, "life")
+
+	id, err := s.repo.Rename(life, "Life")
+	s.Assert().Nil(err)
+	s.Assert().Equal(life.ID, id)
+
+	find, renamed, _ := s.repo.Find(id)
+	s.Require().True(find)
+	s.Assert().Equal("Life", renamed.Name)
+	s.Assert().Equal(int64(1), renamed.QuoteCount)
+	s.db.First(&q, q.ID)
+	s.Assert().Equal("Life,love", q.Tags)
+}
+
+func (s *TagRepositoryTestSuite) TestRenameToExistingTagMerges() {
+	both := s.quote(1, "life,love")
+	one := s.quote(1, "life")
+	s.quote(1, "love")
+	life := s.tag(1, "life")
+	love := s.tag(1, "love")
+
+	id, err := s.repo.Rename(life, "love")
+	s.Assert().Nil(err)
+	s.Assert().Equal(love.ID, id)
+
+	find, _, _ := s.repo.Find(life.ID)
+	s.Assert().False(find)
+	_, merged, _ := s.repo.Find(love.ID)
+	s.Assert().Equal(int64(3), merged.QuoteCount)
+	s.db.First(&both, both.ID)
+	s.Assert().Equal("love", both.Tags)
+	s.db.First(&one, one.ID)
+	s.Assert().Equal("love", one.Tags)
+}
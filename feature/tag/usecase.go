@@ -0,0 +1,67 @@
+package tag
+
+import (
+	"myquote/domain"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/tag"
+	"strings"
+	"unicode/utf8"
+)
+
+type Usecase struct {
+	l domain.Logger
+	r tag.Repository
+}
+
+func NewUsecase(logger domain.Logger, repository tag.Repository) *Usecase {
+	return &Usecase{l: logger, r: repository}
+}
+
+func (uc *Usecase) FindAll(user models.User) ([]models.Tag, error) {
+	found, err := uc.r.FindAll(user.ID)
+	if err != nil {
+		return nil, exceptions.ServerError
+	}
+	tags := make([]models.Tag, 0, len(found))
+	for _, t := range found {
+		tags = append(tags, toTag(t))
+	}
+	return tags, nil
+}
+
+// Rename renames the tag on all quotes of the user, renaming to a tag the user has already
+// merges the two.
+func (uc *Usecase) Rename(user models.User, id int64, r tag.Rename) (models.Tag, error) {
+	name := strings.TrimSpace(r.Name)
+	if strings.Contains(name, ",") {
+		return models.Tag{}, exceptions.InvalidTag
+	}
+	if name == "" || utf8.RuneCountInString(name) > models.MAX_TAG_LENGTH {
+		return models.Tag{}, exceptions.InvalidTagName
+	}
+	find, t, err := uc.r.Find(id)
+	if err != nil {
+		return models.Tag{}, exceptions.ServerError
+	}
+	if !find || t.UserID != user.ID {
+		return models.Tag{}, exceptions.TagNotExists
+	}
+	if t.Name == name {
+		return toTag(t), nil
+	}
+
+	renamed, err := uc.r.Rename(t.TagModel, name)
+	if err != nil {
+		return models.Tag{}, exceptions.ServerError
+	}
+	_, t, err = uc.r.Find(renamed)
+	if err != nil {
+		return models.Tag{}, exceptions.ServerError
+	}
+	return toTag(t), nil
+}
+
+func toTag(t tag.TagCount) models.Tag {
+	return models.Tag{ID: t.ID, Name: t.Name, QuoteCount: t.QuoteCount}
+}
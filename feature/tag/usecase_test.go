@@ -0,0 +1,95 @@
+package tag
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/tag"
+	"myquote/service/logger"
+	"strings"
+	"testing"
+)
+
+type MockedTagRepo struct {
+	mock.Mock
+}
+
+func (m *MockedTagRepo) FindAll(userID int64) ([]tag.TagCount, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]tag.TagCount), args.Error(1)
+}
+
+func (m *MockedTagRepo) Find(id int64) (bool, tag.TagCount, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Get(1).(tag.TagCount), args.Error(2)
+}
+
+func (m *MockedTagRepo) Rename(t models.TagModel, name string) (int64, error) {
+	args := m.Called(t, name)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type TagUsecaseTestSuite struct {
+	suite.Suite
+	uc   *Usecase
+	repo *MockedTagRepo
+	user models.User
+}
+
+func TestTagUsecase(t *testing.T) {
+	suite.Run(t, new(TagUsecaseTestSuite))
+}
+
+func (s *TagUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedTagRepo)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo)
+	s.user = models.User{ID: 1}
+}
+
+func count(id int64, userID int64, name string, quotes int64) tag.TagCount {
+	return tag.TagCount{TagModel: models.TagModel{ID: id, UserID: userID, Name: name}, QuoteCount: quotes}
+}
+
+func (s *TagUsecaseTestSuite) TestFindAll() {
+	s.repo.On("FindAll", int64(1)).Return([]tag.TagCount{count(1, 1, "life", 2)}, nil)
+	tags, err := s.uc.FindAll(s.user)
+	s.Assert().Nil(err)
+	s.Assert().Equal([]models.Tag{{ID: 1, Name: "life", QuoteCount: 2}}, tags)
+}
+
+func (s *TagUsecaseTestSuite) TestRenameInvalidName() {
+	_, err := s.uc.Rename(s.user, 1, tag.Rename{Name: "a,b"})
+	s.Assert().Equal(exceptions.InvalidTag, err)
+	_, err = s.uc.Rename(s.user, 1, tag.Rename{Name: "  "})
+	s.Assert().Equal(exceptions.InvalidTagName, err)
+	_, err = s.uc.Rename(s.user, 1, tag.Rename{Name: strings.Repeat("a", models.MAX_TAG_LENGTH+1)})
+	s.Assert().Equal(exceptions.InvalidTagName, err)
+	s.repo.AssertNotCalled(s.T(), "Rename", mock.Anything, mock.Anything)
+}
+
+func (s *TagUsecaseTestSuite) TestRenameOthersTag() {
+	s.repo.On("Find", int64(1)).Return(true, count(1, 9, "life", 1), nil)
+	_, err := s.uc.Rename(s.user, 1, tag.Rename{Name: "living"})
+	s.Assert().Equal(exceptions.TagNotExists, err)
+	s.repo.AssertNotCalled(s.T(), "Rename", mock.Anything, mock.Anything)
+}
+
+func (s *TagUsecaseTestSuite) TestRenameToSameName() {
+	s.repo.On("Find", int64(1)).Return(true, count(1, 1, "life", 1), nil)
+	actual, err := s.uc.Rename(s.user, 1, tag.Rename{Name: " life "})
+	s.Assert().Nil(err)
+	s.Assert().Equal("life", actual.Name)
+	s.repo.AssertNotCalled(s.T(), "Rename", mock.Anything, mock.Anything)
+}
+
+func (s *TagUsecaseTestSuite) TestRenameMergesIntoExistingTag() {
+	life := count(1, 1, "life", 1)
+	s.repo.On("Find", int64(1)).Return(true, life, nil)
+	s.repo.On("Rename", life.TagModel, "love").Return(int64(2), nil)
+	s.repo.On("Find", int64(2)).Return(true, count(2, 1, "love", 3), nil)
+
+	actual, err := s.uc.Rename(s.user, 1, tag.Rename{Name: "love"})
+	s.Assert().Nil(err)
+	s.Assert().Equal(models.Tag{ID: 2, Name: "love", QuoteCount: 3}, actual)
+}
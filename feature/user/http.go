@@ -0,0 +1,103 @@
+package user
+
+import (
+	"github.com/gin-gonic/gin"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/exceptions"
+	"myquote/domain/user"
+	"net/http"
+)
+
+type handler struct {
+	logger domain.Logger
+	userUc user.Usecase
+}
+
+const ME_ENDPOINT = "/api/users/me"
+const NAME_ENDPOINT = ME_ENDPOINT + "/name"
+const EMAIL_ENDPOINT = ME_ENDPOINT + "/email"
+const PREFERENCES_ENDPOINT = ME_ENDPOINT + "/preferences"
+
+// NewUserHTTPHandler registers the routes of the authenticated user.
+func NewUserHTTPHandler(c *gin.Engine, l domain.Logger, uc user.Usecase, middlewares ...gin.HandlerFunc) {
+	handler := &handler{logger: l, userUc: uc}
+	g := c.Group(ME_ENDPOINT, middlewares...)
+	g.PATCH("/name", handler.updateName)
+	g.PATCH("/email", handler.updateEmail)
+	g.GET("/preferences", handler.preferences)
+	g.PATCH("/preferences", handler.updatePreferences)
+}
+
+func (h *handler) updateName(c *gin.Context) {
+	u, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	var update user.NameUpdate
+	err := c.Bind(&update)
+	if err != nil {
+		h.logger.Debugf("Convert name json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	updated, err := h.userUc.UpdateName(u, update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *handler) updateEmail(c *gin.Context) {
+	u, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	var update user.EmailUpdate
+	err := c.Bind(&update)
+	if err != nil {
+		h.logger.Debugf("Convert email json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	updated, err := h.userUc.UpdateEmail(u, update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *handler) preferences(c *gin.Context) {
+	u, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	p, err := h.userUc.Preferences(u)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *handler) updatePreferences(c *gin.Context) {
+	u, ok := auth.RequireUser(c)
+	if !ok {
+		return
+	}
+	var update user.PreferencesUpdate
+	err := c.Bind(&update)
+	if err != nil {
+		h.logger.Debugf("Convert preferences json error: %s", err.Error())
+		c.Error(exceptions.InvalidInput)
+		return
+	}
+	p, err := h.userUc.UpdatePreferences(u, update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
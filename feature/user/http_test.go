@@ -0,0 +1,181 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain"
+	"myquote/domain/auth"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/user"
+	"myquote/feature/middleware"
+	"myquote/service/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MockedUserUsecase struct {
+	mock.Mock
+}
+
+func (m *MockedUserUsecase) Preferences(u models.User) (user.Preferences, error) {
+	args := m.Called(u)
+	return args.Get(0).(user.Preferences), args.Error(1)
+}
+
+func (m *MockedUserUsecase) UpdatePreferences(u models.User, p user.PreferencesUpdate) (user.Preferences, error) {
+	args := m.Called(u, p)
+	return args.Get(0).(user.Preferences), args.Error(1)
+}
+
+func (m *MockedUserUsecase) UpdateName(u models.User, update user.NameUpdate) (models.User, error) {
+	args := m.Called(u, update)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockedUserUsecase) UpdateEmail(u models.User, update user.EmailUpdate) (models.User, error) {
+	args := m.Called(u, update)
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+type UserTestSuite struct {
+	suite.Suite
+	uc   *MockedUserUsecase
+	l    domain.Logger
+	g    *gin.Engine
+	r    *httptest.ResponseRecorder
+	user models.User
+}
+
+func TestUserHTTPHandler(t *testing.T) {
+	suite.Run(t, new(UserTestSuite))
+}
+
+func (s *UserTestSuite) SetupTest() {
+	s.uc = new(MockedUserUsecase)
+	s.l = logger.NewLogger("")
+	s.g = gin.Default()
+	s.g.Use(middleware.NewErrorMiddleware(s.l))
+	s.r = httptest.NewRecorder()
+	s.user = models.User{ID: 1}
+}
+
+func (s *UserTestSuite) authenticated(c *gin.Context) {
+	c.Set(auth.USER_KEY, s.user)
+}
+
+func newTestRequest(method string, endpoint string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req, err
+}
+
+func (s *UserTestSuite) TestPreferences() {
+	p := user.Preferences{MailsPerWeek: 1, QuotesPerMail: 5, TimeZone: "UTC"}
+	s.uc.On("Preferences", s.user).Return(p, nil)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodGet, PREFERENCES_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual user.Preferences
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(p, actual)
+}
+
+func (s *UserTestSuite) TestUpdatePreferences() {
+	mails := 2
+	update := user.PreferencesUpdate{MailsPerWeek: &mails}
+	p := user.Preferences{MailsPerWeek: 2, QuotesPerMail: 5, TimeZone: "UTC"}
+	s.uc.On("UpdatePreferences", s.user, update).Return(p, nil)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, PREFERENCES_ENDPOINT, []byte(`{"mails_per_week": 2}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var actual user.Preferences
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal(p, actual)
+}
+
+func (s *UserTestSuite) TestUpdatePreferencesShowInvalidMessage() {
+	s.uc.On("UpdatePreferences", s.user, mock.Anything).Return(user.Preferences{}, exceptions.InvalidQuotesPerMail)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, PREFERENCES_ENDPOINT, []byte(`{"quotes_per_mail": 10}`))
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.InvalidQuotesPerMail.Error(), m.Message)
+}
+
+func (s *UserTestSuite) TestUpdatePreferencesInvalidInput() {
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, PREFERENCES_ENDPOINT, []byte(`{"mails_per_week": "two"}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.uc.AssertNotCalled(s.T(), "UpdatePreferences", mock.Anything, mock.Anything)
+}
+
+func (s *UserTestSuite) TestRespondUnauthorizedWithoutUser() {
+	NewUserHTTPHandler(s.g, s.l, s.uc)
+	req, _ := newTestRequest(http.MethodGet, PREFERENCES_ENDPOINT, nil)
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusUnauthorized, s.r.Code)
+}
+
+func (s *UserTestSuite) TestUpdateName() {
+	update := user.NameUpdate{Name: "Lester Chen"}
+	s.uc.On("UpdateName", s.user, update).Return(models.User{ID: 1, Name: "Lester Chen"}, nil)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	body, _ := json.Marshal(update)
+	req, _ := newTestRequest(http.MethodPatch, NAME_ENDPOINT, body)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.User
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal("Lester Chen", actual.Name)
+}
+
+func (s *UserTestSuite) TestUpdateEmail() {
+	update := user.EmailUpdate{Email: "456@gmail.com", Password: "123456"}
+	s.uc.On("UpdateEmail", s.user, update).Return(models.User{ID: 1, Email: "456@gmail.com"}, nil)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	body, _ := json.Marshal(update)
+	req, _ := newTestRequest(http.MethodPatch, EMAIL_ENDPOINT, body)
+	s.g.ServeHTTP(s.r, req)
+
+	var actual models.User
+	json.Unmarshal(s.r.Body.Bytes(), &actual)
+	s.Assert().Equal(http.StatusOK, s.r.Code)
+	s.Assert().Equal("456@gmail.com", actual.Email)
+}
+
+func (s *UserTestSuite) TestUpdateEmailShowUserExists() {
+	update := user.EmailUpdate{Email: "456@gmail.com", Password: "123456"}
+	s.uc.On("UpdateEmail", s.user, update).Return(models.User{}, exceptions.UserExists)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	body, _ := json.Marshal(update)
+	req, _ := newTestRequest(http.MethodPatch, EMAIL_ENDPOINT, body)
+	s.g.ServeHTTP(s.r, req)
+
+	var m common.Message
+	json.Unmarshal(s.r.Body.Bytes(), &m)
+	s.Assert().Equal(http.StatusBadRequest, s.r.Code)
+	s.Assert().Equal(exceptions.UserExists.Error(), m.Message)
+}
+
+func (s *UserTestSuite) TestRespondServerErrorWhenUpdateNameFailure() {
+	s.uc.On("UpdateName", s.user, mock.Anything).Return(models.User{}, exceptions.ServerError)
+	NewUserHTTPHandler(s.g, s.l, s.uc, s.authenticated)
+	req, _ := newTestRequest(http.MethodPatch, NAME_ENDPOINT, []byte(`{"name": "Lester"}`))
+	s.g.ServeHTTP(s.r, req)
+	s.Assert().Equal(http.StatusInternalServerError, s.r.Code)
+}
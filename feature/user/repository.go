@@ -0,0 +1,85 @@
+package user
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"myquote/domain"
+	"myquote/domain/models"
+	"myquote/domain/user"
+	"myquote/service/database"
+	"time"
+)
+
+type Repository struct {
+	l  domain.Logger
+	db *gorm.DB
+}
+
+func NewRepository(logger domain.Logger, db *gorm.DB) *Repository {
+	return &Repository{l: logger, db: db}
+}
+
+func (r *Repository) Find(id int64) (bool, models.UserModel, error) {
+	var u models.UserModel
+	result := r.db.First(&u, "id = ?", id)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.UserModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find user error, user id: %d\n The error message: %s", id, result.Error.Error())
+		return false, models.UserModel{}, result.Error
+	}
+	return true, u, nil
+}
+
+func (r *Repository) FindByEmail(email string) (bool, models.UserModel, error) {
+	var u models.UserModel
+	result := r.db.First(&u, "email = ?", email)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, models.UserModel{}, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("find user error, user email: %s\n The error message: %s", email, result.Error.Error())
+		return false, models.UserModel{}, result.Error
+	}
+	return true, u, nil
+}
+
+func (r *Repository) UpdateName(id int64, name string) error {
+	result := r.db.Model(&models.UserModel{}).Where("id = ?", id).Update("name", name)
+	if result.Error != nil {
+		r.l.Debugf("update name error, user id: %d\n The error message: %s", id, result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
+
+func (r *Repository) UpdateEmail(id int64, email string) (bool, error) {
+	result := r.db.Model(&models.UserModel{}).Where("id = ?", id).Update("email", email)
+	if database.IsDuplicate(result.Error) {
+		return false, nil
+	}
+	if result.Error != nil {
+		r.l.Debugf("update email error, user id: %d\n The error message: %s", id, result.Error.Error())
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// UpdatePreferences saves the preferences together with the send time they lead to, in UTC like
+// the digest scheduler compares it.
+func (r *Repository) UpdatePreferences(id int64, p user.Preferences, nextDigestAt time.Time) error {
+	result := r.db.Model(&models.UserModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"mails_per_week":     p.MailsPerWeek,
+		"quotes_per_mail":    p.QuotesPerMail,
+		"time_zone":          p.TimeZone,
+		"default_visibility": p.DefaultVisibility,
+		"review_mode":        p.ReviewMode,
+		"next_digest_at":     nextDigestAt.UTC(),
+	})
+	if result.Error != nil {
+		r.l.Debugf("update preferences error, user id: %d\n The error message: %s", id, result.Error.Error())
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/domain/user"
+	"myquote/service/database"
+	"myquote/service/logger"
+	"testing"
+	"time"
+)
+
+type UserRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo *Repository
+}
+
+func TestUserRepository(t *testing.T) {
+	suite.Run(t, new(UserRepositoryTestSuite))
+}
+
+func (s *UserRepositoryTestSuite) SetupTest() {
+	db, err := database.Memory()
+	s.Require().NoError(err)
+	s.Require().NoError(db.AutoMigrate(&models.UserModel{}))
+	s.db = db
+	s.repo = NewRepository(logger.NewLogger(""), db)
+}
+
+func (s *UserRepositoryTestSuite) TestFindWithDefaultPreferences() {
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+
+	find, actual, err := s.repo.Find(u.ID)
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal(1, actual.MailsPerWeek)
+	s.Assert().Equal(5, actual.QuotesPerMail)
+	s.Assert().Equal("UTC", actual.TimeZone)
+	s.Assert().Equal("followers", actual.DefaultVisibility)
+	s.Assert().Nil(actual.NextDigestAt)
+}
+
+func (s *UserRepositoryTestSuite) TestFindNotExists() {
+	find, _, err := s.repo.Find(99)
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *UserRepositoryTestSuite) TestUpdatePreferences() {
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+	next := time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC)
+
+	err := s.repo.UpdatePreferences(u.ID, user.Preferences{MailsPerWeek: 3, QuotesPerMail: 7, TimeZone: "Asia/Taipei", DefaultVisibility: "private"}, next)
+	s.Assert().Nil(err)
+
+	_, actual, _ := s.repo.Find(u.ID)
+	s.Assert().Equal(3, actual.MailsPerWeek)
+	s.Assert().Equal(7, actual.QuotesPerMail)
+	s.Assert().Equal("Asia/Taipei", actual.TimeZone)
+	s.Assert().Equal("private", actual.DefaultVisibility)
+	s.Assert().True(next.Equal(*actual.NextDigestAt))
+}
+
+func (s *UserRepositoryTestSuite) TestUpdatePreferencesSavesUTC() {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	s.Require().NoError(err)
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+	next := time.Date(2022, 5, 2, 8, 0, 0, 0, taipei)
+
+	err = s.repo.UpdatePreferences(u.ID, user.Preferences{MailsPerWeek: 3, QuotesPerMail: 7, TimeZone: "Asia/Taipei", DefaultVisibility: "private"}, next)
+	s.Assert().Nil(err)
+
+	var count int64
+	s.db.Model(&models.UserModel{}).Where("next_digest_at <= ?", time.Date(2022, 5, 2, 1, 0, 0, 0, time.UTC)).Count(&count)
+	s.Assert().Equal(int64(1), count)
+}
+
+func (s *UserRepositoryTestSuite) TestFindByEmail() {
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+
+	find, actual, err := s.repo.FindByEmail("123@gmail.com")
+	s.Assert().Nil(err)
+	s.Assert().True(find)
+	s.Assert().Equal(u.ID, actual.ID)
+
+	find, _, err = s.repo.FindByEmail("456@gmail.com")
+	s.Assert().Nil(err)
+	s.Assert().False(find)
+}
+
+func (s *UserRepositoryTestSuite) TestUpdateNameAndEmailTouchUpdatedAt() {
+	old := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com", CreatedAt: old, UpdatedAt: old}
+	s.db.Create(&u)
+
+	s.Assert().Nil(s.repo.UpdateName(u.ID, "Lester Chen"))
+	updated, err := s.repo.UpdateEmail(u.ID, "456@gmail.com")
+	s.Assert().Nil(err)
+	s.Assert().True(updated)
+
+	_, actual, _ := s.repo.Find(u.ID)
+	s.Assert().Equal("Lester Chen", actual.Name)
+	s.Assert().Equal("456@gmail.com", actual.Email)
+	s.Assert().True(actual.UpdatedAt.After(old))
+}
+
+func (s *UserRepositoryTestSuite) TestUpdateEmailToUsedEmail() {
+	s.db.Create(&models.UserModel{Name: "Other", Email: "456@gmail.com"})
+	u := models.UserModel{Name: "Lester", Email: "123@gmail.com"}
+	s.db.Create(&u)
+
+	updated, err := s.repo.UpdateEmail(u.ID, "456@gmail.com")
+	s.Assert().Nil(err)
+	s.Assert().False(updated)
+	_, actual, _ := s.repo.Find(u.ID)
+	s.Assert().Equal("123@gmail.com", actual.Email)
+}
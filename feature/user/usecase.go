@@ -0,0 +1,182 @@
+package user
+
+import (
+	"myquote/domain"
+	"myquote/domain/common"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/quote"
+	"myquote/domain/user"
+	"myquote/service/schedule"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const MAX_NAME_LENGTH = 50
+
+type Usecase struct {
+	l     domain.Logger
+	r     user.Repository
+	ev    common.Validator
+	hashv common.HashValidator
+	mailv common.IntValidator
+	quotv common.IntValidator
+	tzv   common.Validator
+	now   func() time.Time
+}
+
+func NewUsecase(logger domain.Logger, repository user.Repository, emailValidator common.Validator, hashValidator common.HashValidator, mailsPerWeekValidator common.IntValidator, quotesPerMailValidator common.IntValidator, timeZoneValidator common.Validator) *Usecase {
+	return &Usecase{
+		l:     logger,
+		r:     repository,
+		ev:    emailValidator,
+		hashv: hashValidator,
+		mailv: mailsPerWeekValidator,
+		quotv: quotesPerMailValidator,
+		tzv:   timeZoneValidator,
+		now:   time.Now,
+	}
+}
+
+func (uc *Usecase) UpdateName(u models.User, update user.NameUpdate) (models.User, error) {
+	name := strings.TrimSpace(update.Name)
+	if name == "" || utf8.RuneCountInString(name) > MAX_NAME_LENGTH {
+		uc.l.Debugf("invalid name length: %d", utf8.RuneCountInString(name))
+		return models.User{}, exceptions.InvalidName
+	}
+	_, err := uc.find(u)
+	if err != nil {
+		return models.User{}, err
+	}
+	err = uc.r.UpdateName(u.ID, name)
+	if err != nil {
+		return models.User{}, exceptions.ServerError
+	}
+	return uc.reload(u)
+}
+
+// UpdateEmail changes the email after the current password is confirmed.
+func (uc *Usecase) UpdateEmail(u models.User, update user.EmailUpdate) (models.User, error) {
+	if !uc.ev.Validate(update.Email) {
+		uc.l.Debugf("invalid email addr: %s", update.Email)
+		return models.User{}, exceptions.InvalidEmailAddr
+	}
+	m, err := uc.find(u)
+	if err != nil {
+		return models.User{}, err
+	}
+	if !uc.hashv.Compare(update.Password, m.Hashed) {
+		uc.l.Warnf("user %d changing email with a wrong password", u.ID)
+		return models.User{}, exceptions.AuthError
+	}
+	if update.Email == m.Email {
+		return m.ToUser(), nil
+	}
+
+	find, _, err := uc.r.FindByEmail(update.Email)
+	if err != nil {
+		return models.User{}, exceptions.ServerError
+	}
+	if find {
+		return models.User{}, exceptions.UserExists
+	}
+	// another user may take the email between the check and the update
+	updated, err := uc.r.UpdateEmail(u.ID, update.Email)
+	if err != nil {
+		return models.User{}, exceptions.ServerError
+	}
+	if !updated {
+		return models.User{}, exceptions.UserExists
+	}
+	return uc.reload(u)
+}
+
+func (uc *Usecase) Preferences(u models.User) (user.Preferences, error) {
+	m, err := uc.find(u)
+	if err != nil {
+		return user.Preferences{}, err
+	}
+	return toPreferences(m), nil
+}
+
+// UpdatePreferences changes the given preferences and reschedules the next review mail.
+func (uc *Usecase) UpdatePreferences(u models.User, update user.PreferencesUpdate) (user.Preferences, error) {
+	m, err := uc.find(u)
+	if err != nil {
+		return user.Preferences{}, err
+	}
+	p := toPreferences(m)
+	if update.MailsPerWeek != nil {
+		p.MailsPerWeek = *update.MailsPerWeek
+	}
+	if update.QuotesPerMail != nil {
+		p.QuotesPerMail = *update.QuotesPerMail
+	}
+	if update.TimeZone != nil {
+		p.TimeZone = *update.TimeZone
+	}
+	if update.DefaultVisibility != nil {
+		p.DefaultVisibility = *update.DefaultVisibility
+	}
+	if update.ReviewMode != nil {
+		p.ReviewMode = *update.ReviewMode
+	}
+
+	if !uc.mailv.Validate(p.MailsPerWeek) {
+		uc.l.Debugf("invalid mails per week: %d", p.MailsPerWeek)
+		return user.Preferences{}, exceptions.InvalidMailsPerWeek
+	}
+	if !uc.quotv.Validate(p.QuotesPerMail) {
+		uc.l.Debugf("invalid quotes per mail: %d", p.QuotesPerMail)
+		return user.Preferences{}, exceptions.InvalidQuotesPerMail
+	}
+	if !uc.tzv.Validate(p.TimeZone) {
+		uc.l.Debugf("invalid time zone: %s", p.TimeZone)
+		return user.Preferences{}, exceptions.InvalidTimeZone
+	}
+	if !quote.Visibility(p.DefaultVisibility).Valid() {
+		uc.l.Debugf("invalid default visibility: %s", p.DefaultVisibility)
+		return user.Preferences{}, exceptions.InvalidVisibility
+	}
+	if !quote.ReviewMode(p.ReviewMode).Valid() {
+		uc.l.Debugf("invalid review mode: %s", p.ReviewMode)
+		return user.Preferences{}, exceptions.InvalidReviewMode
+	}
+
+	next := schedule.Next(p.MailsPerWeek, p.TimeZone, uc.now())
+	err = uc.r.UpdatePreferences(u.ID, p, next)
+	if err != nil {
+		return user.Preferences{}, exceptions.ServerError
+	}
+	return p, nil
+}
+
+func (uc *Usecase) find(u models.User) (models.UserModel, error) {
+	find, m, err := uc.r.Find(u.ID)
+	if err != nil {
+		return models.UserModel{}, exceptions.ServerError
+	}
+	if !find {
+		return models.UserModel{}, exceptions.UserNotExists
+	}
+	return m, nil
+}
+
+func (uc *Usecase) reload(u models.User) (models.User, error) {
+	m, err := uc.find(u)
+	if err != nil {
+		return models.User{}, err
+	}
+	return m.ToUser(), nil
+}
+
+func toPreferences(m models.UserModel) user.Preferences {
+	return user.Preferences{
+		MailsPerWeek:      m.MailsPerWeek,
+		QuotesPerMail:     m.QuotesPerMail,
+		TimeZone:          m.TimeZone,
+		DefaultVisibility: m.DefaultVisibility,
+		ReviewMode:        string(quote.ResolveReviewMode(m.ReviewMode)),
+	}
+}
@@ -0,0 +1,242 @@
+package user
+
+import (
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"myquote/domain/exceptions"
+	"myquote/domain/models"
+	"myquote/domain/user"
+	"myquote/service/logger"
+	"myquote/service/validator"
+	"testing"
+	"time"
+)
+
+type MockedUserRepo struct {
+	mock.Mock
+}
+
+func (m *MockedUserRepo) Find(id int64) (bool, models.UserModel, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Get(1).(models.UserModel), args.Error(2)
+}
+
+func (m *MockedUserRepo) UpdatePreferences(id int64, p user.Preferences, nextDigestAt time.Time) error {
+	args := m.Called(id, p, nextDigestAt)
+	return args.Error(0)
+}
+
+func (m *MockedUserRepo) FindByEmail(email string) (bool, models.UserModel, error) {
+	args := m.Called(email)
+	return args.Bool(0), args.Get(1).(models.UserModel), args.Error(2)
+}
+
+func (m *MockedUserRepo) UpdateName(id int64, name string) error {
+	args := m.Called(id, name)
+	return args.Error(0)
+}
+
+func (m *MockedUserRepo) UpdateEmail(id int64, email string) (bool, error) {
+	args := m.Called(id, email)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockedHashValidator struct {
+	mock.Mock
+}
+
+func (m *MockedHashValidator) Hash(s string) (string, error) {
+	args := m.Called(s)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockedHashValidator) Compare(s string, h string) bool {
+	args := m.Called(s, h)
+	return args.Bool(0)
+}
+
+type UserUsecaseTestSuite struct {
+	suite.Suite
+	uc    *Usecase
+	repo  *MockedUserRepo
+	hashv *MockedHashValidator
+	user  models.User
+	model models.UserModel
+	now   time.Time
+}
+
+func TestNewUserUsecase(t *testing.T) {
+	suite.Run(t, new(UserUsecaseTestSuite))
+}
+
+func (s *UserUsecaseTestSuite) SetupTest() {
+	s.repo = new(MockedUserRepo)
+	s.hashv = new(MockedHashValidator)
+	s.uc = NewUsecase(logger.NewLogger(""), s.repo, validator.NewEmailValidator(), s.hashv, validator.NewRangeValidator(1, 3), validator.NewRangeValidator(3, 7), validator.NewTimeZoneValidator())
+	// Tuesday
+	s.now = time.Date(2022, 5, 3, 12, 0, 0, 0, time.UTC)
+	s.uc.now = func() time.Time { return s.now }
+	s.user = models.User{ID: 1}
+	s.model = models.UserModel{ID: 1, Name: "Lester", Email: "123@gmail.com", Hashed: "this is a hash", MailsPerWeek: 1, QuotesPerMail: 5, TimeZone: "UTC", DefaultVisibility: "followers"}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func (s *UserUsecaseTestSuite) TestPreferences() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	p, err := s.uc.Preferences(s.user)
+	s.Assert().Nil(err)
+	s.Assert().Equal(user.Preferences{MailsPerWeek: 1, QuotesPerMail: 5, TimeZone: "UTC", DefaultVisibility: "followers", ReviewMode: "random"}, p)
+}
+
+func (s *UserUsecaseTestSuite) TestPreferencesUserNotExists() {
+	s.repo.On("Find", int64(1)).Return(false, models.UserModel{}, nil)
+	_, err := s.uc.Preferences(s.user)
+	s.Assert().Equal(exceptions.UserNotExists, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateInvalidMailsPerWeek() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	_, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{MailsPerWeek: intPtr(4)})
+	s.Assert().Equal(exceptions.InvalidMailsPerWeek, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateInvalidQuotesPerMail() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	_, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{QuotesPerMail: intPtr(2)})
+	s.Assert().Equal(exceptions.InvalidQuotesPerMail, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateInvalidTimeZone() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	_, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{TimeZone: strPtr("Mars/Base")})
+	s.Assert().Equal(exceptions.InvalidTimeZone, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateInvalidDefaultVisibility() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	_, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{DefaultVisibility: strPtr("friends")})
+	s.Assert().Equal(exceptions.InvalidVisibility, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateDefaultVisibility() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.repo.On("UpdatePreferences", int64(1), mock.MatchedBy(func(p user.Preferences) bool { return p.DefaultVisibility == "public" }), mock.Anything).Return(nil)
+	actual, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{DefaultVisibility: strPtr("public")})
+	s.Assert().Nil(err)
+	s.Assert().Equal("public", actual.DefaultVisibility)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateReviewMode() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.repo.On("UpdatePreferences", int64(1), mock.MatchedBy(func(p user.Preferences) bool { return p.ReviewMode == "spaced" }), mock.Anything).Return(nil)
+	actual, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{ReviewMode: strPtr("spaced")})
+	s.Assert().Nil(err)
+	s.Assert().Equal("spaced", actual.ReviewMode)
+
+	_, err = s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{ReviewMode: strPtr("often")})
+	s.Assert().Equal(exceptions.InvalidReviewMode, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateReschedulesNextMail() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	p := user.Preferences{MailsPerWeek: 3, QuotesPerMail: 5, TimeZone: "Asia/Taipei", DefaultVisibility: "followers", ReviewMode: "random"}
+	// Wednesday 08:00 in Taipei
+	next := time.Date(2022, 5, 4, 0, 0, 0, 0, time.UTC)
+	s.repo.On("UpdatePreferences", int64(1), p, mock.MatchedBy(func(t time.Time) bool { return t.Equal(next) })).Return(nil)
+
+	actual, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{MailsPerWeek: intPtr(3), TimeZone: strPtr("Asia/Taipei")})
+	s.Assert().Nil(err)
+	s.Assert().Equal(p, actual)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateThrowServerError() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.repo.On("UpdatePreferences", int64(1), mock.Anything, mock.Anything).Return(exceptions.ServerError)
+	_, err := s.uc.UpdatePreferences(s.user, user.PreferencesUpdate{QuotesPerMail: intPtr(7)})
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateEmptyName() {
+	_, err := s.uc.UpdateName(s.user, user.NameUpdate{Name: "  "})
+	s.Assert().Equal(exceptions.InvalidName, err)
+	s.repo.AssertNotCalled(s.T(), "UpdateName", mock.Anything, mock.Anything)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateNameSuccess() {
+	renamed := s.model
+	renamed.Name = "Lester Chen"
+	renamed.UpdatedAt = s.now
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil).Once()
+	s.repo.On("UpdateName", int64(1), "Lester Chen").Return(nil)
+	s.repo.On("Find", int64(1)).Return(true, renamed, nil)
+
+	actual, err := s.uc.UpdateName(s.user, user.NameUpdate{Name: " Lester Chen "})
+	s.Assert().Nil(err)
+	s.Assert().Equal("Lester Chen", actual.Name)
+	s.Assert().Equal(s.now, actual.UpdatedAt)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateInvalidEmail() {
+	_, err := s.uc.UpdateEmail(s.user, user.EmailUpdate{Email: "123@", Password: "123456"})
+	s.Assert().Equal(exceptions.InvalidEmailAddr, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateEmailWrongPassword() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.hashv.On("Compare", "wrong", s.model.Hashed).Return(false)
+
+	_, err := s.uc.UpdateEmail(s.user, user.EmailUpdate{Email: "456@gmail.com", Password: "wrong"})
+	s.Assert().Equal(exceptions.AuthError, err)
+	s.repo.AssertNotCalled(s.T(), "UpdateEmail", mock.Anything, mock.Anything)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateEmailUsedByOtherUser() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.hashv.On("Compare", "123456", s.model.Hashed).Return(true)
+	s.repo.On("FindByEmail", "456@gmail.com").Return(true, models.UserModel{ID: 2}, nil)
+
+	_, err := s.uc.UpdateEmail(s.user, user.EmailUpdate{Email: "456@gmail.com", Password: "123456"})
+	s.Assert().Equal(exceptions.UserExists, err)
+	s.repo.AssertNotCalled(s.T(), "UpdateEmail", mock.Anything, mock.Anything)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateEmailSuccess() {
+	changed := s.model
+	changed.Email = "456@gmail.com"
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil).Once()
+	s.hashv.On("Compare", "123456", s.model.Hashed).Return(true)
+	s.repo.On("FindByEmail", "456@gmail.com").Return(false, models.UserModel{}, nil)
+	s.repo.On("UpdateEmail", int64(1), "456@gmail.com").Return(true, nil)
+	s.repo.On("Find", int64(1)).Return(true, changed, nil)
+
+	actual, err := s.uc.UpdateEmail(s.user, user.EmailUpdate{Email: "456@gmail.com", Password: "123456"})
+	s.Assert().Nil(err)
+	s.Assert().Equal("456@gmail.com", actual.Email)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateEmailThrowServerError() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.hashv.On("Compare", "123456", s.model.Hashed).Return(true)
+	s.repo.On("FindByEmail", "456@gmail.com").Return(false, models.UserModel{}, nil)
+	s.repo.On("UpdateEmail", int64(1), "456@gmail.com").Return(false, exceptions.ServerError)
+
+	_, err := s.uc.UpdateEmail(s.user, user.EmailUpdate{Email: "456@gmail.com", Password: "123456"})
+	s.Assert().Equal(exceptions.ServerError, err)
+}
+
+func (s *UserUsecaseTestSuite) TestUpdateEmailTakenMeanwhile() {
+	s.repo.On("Find", int64(1)).Return(true, s.model, nil)
+	s.hashv.On("Compare", "123456", s.model.Hashed).Return(true)
+	s.repo.On("FindByEmail", "456@gmail.com").Return(false, models.UserModel{}, nil)
+	s.repo.On("UpdateEmail", int64(1), "456@gmail.com").Return(false, nil)
+
+	_, err := s.uc.UpdateEmail(s.user, user.EmailUpdate{Email: "456@gmail.com", Password: "123456"})
+	s.Assert().Equal(exceptions.UserExists, err)
+}
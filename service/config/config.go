@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"strconv"
+)
+
+type Config struct {
+	Addr     string   `yaml:"addr"`
+	LogPath  string   `yaml:"log_path"`
+	Database Database `yaml:"database"`
+	Token    Token    `yaml:"token"`
+	Review   Review   `yaml:"review"`
+	SMTP     SMTP     `yaml:"smtp"`
+	Import   Import   `yaml:"import"`
+}
+
+type Database struct {
+	Driver string `yaml:"driver"`
+	DSN    string `yaml:"dsn"`
+}
+
+type Token struct {
+	Size int `yaml:"size"`
+}
+
+// SMTP is disabled, no review mail is sent, when Host is empty.
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+type Import struct {
+	// Workers is the number of import jobs run at the same time.
+	Workers int `yaml:"workers"`
+}
+
+type Review struct {
+	// Window is the number of latest draws a random quote is not repeated from, 0 allows repeats.
+	Window int `yaml:"window"`
+}
+
+const (
+	ENV_ADDR           = "MYQUOTE_ADDR"
+	ENV_LOG_PATH       = "MYQUOTE_LOG_PATH"
+	ENV_DB_DRIVER      = "MYQUOTE_DB_DRIVER"
+	ENV_DB_DSN         = "MYQUOTE_DB_DSN"
+	ENV_TOKEN_SIZE     = "MYQUOTE_TOKEN_SIZE"
+	ENV_REVIEW_WINDOW  = "MYQUOTE_REVIEW_WINDOW"
+	ENV_SMTP_HOST      = "MYQUOTE_SMTP_HOST"
+	ENV_SMTP_PORT      = "MYQUOTE_SMTP_PORT"
+	ENV_SMTP_USERNAME  = "MYQUOTE_SMTP_USERNAME"
+	ENV_SMTP_PASSWORD  = "MYQUOTE_SMTP_PASSWORD"
+	ENV_SMTP_FROM      = "MYQUOTE_SMTP_FROM"
+	ENV_IMPORT_WORKERS = "MYQUOTE_IMPORT_WORKERS"
+)
+
+func Default() Config {
+	return Config{
+		Addr:     ":8080",
+		LogPath:  "",
+		Database: Database{Driver: "sqlite", DSN: "myquote.db"},
+		Token:    Token{Size: 32},
+		Review:   Review{Window: 10},
+		SMTP:     SMTP{Port: 587},
+		Import:   Import{Workers: 2},
+	}
+}
+
+// Load reads the yaml file at path on top of the default config and then applies the environment overrides.
+// An empty path skips the file.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		err = yaml.Unmarshal(b, &cfg)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+	err := cfg.applyEnv()
+	if err != nil {
+		return Config{}, err
+	}
+	if cfg.Import.Workers < 1 {
+		return Config{}, fmt.Errorf("import workers must be at least 1, got %d", cfg.Import.Workers)
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) applyEnv() error {
+	if v, ok := os.LookupEnv(ENV_ADDR); ok {
+		cfg.Addr = v
+	}
+	if v, ok := os.LookupEnv(ENV_LOG_PATH); ok {
+		cfg.LogPath = v
+	}
+	if v, ok := os.LookupEnv(ENV_DB_DRIVER); ok {
+		cfg.Database.Driver = v
+	}
+	if v, ok := os.LookupEnv(ENV_DB_DSN); ok {
+		cfg.Database.DSN = v
+	}
+	if v, ok := os.LookupEnv(ENV_TOKEN_SIZE); ok {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		cfg.Token.Size = size
+	}
+	if v, ok := os.LookupEnv(ENV_REVIEW_WINDOW); ok {
+		window, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		cfg.Review.Window = window
+	}
+	if v, ok := os.LookupEnv(ENV_SMTP_HOST); ok {
+		cfg.SMTP.Host = v
+	}
+	if v, ok := os.LookupEnv(ENV_SMTP_PORT); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		cfg.SMTP.Port = port
+	}
+	if v, ok := os.LookupEnv(ENV_SMTP_USERNAME); ok {
+		cfg.SMTP.Username = v
+	}
+	if v, ok := os.LookupEnv(ENV_SMTP_PASSWORD); ok {
+		cfg.SMTP.Password = v
+	}
+	if v, ok := os.LookupEnv(ENV_SMTP_FROM); ok {
+		cfg.SMTP.From = v
+	}
+	if v, ok := os.LookupEnv(ENV_IMPORT_WORKERS); ok {
+		workers, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		cfg.Import.Workers = workers
+	}
+	return nil
+}
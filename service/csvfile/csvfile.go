@@ -0,0 +1,201 @@
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DATE_LAYOUTS are the date formats a date column may use, a date without zone is in UTC.
+var DATE_LAYOUTS = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// READWISE maps the columns of a Readwise export.
+var READWISE = importer.Mapping{
+	Text:     "Highlight",
+	Book:     "Book Title",
+	Author:   "Book Author",
+	Page:     "Location",
+	PageType: "Location Type",
+	Tags:     "Tags",
+	Date:     "Highlighted at",
+	Note:     "Note",
+}
+
+// columns holds the index of every mapped column, -1 when the field is not mapped.
+type columns struct {
+	text, book, author, chapter, page, pageType, tags, date, note int
+}
+
+// Parser reads a CSV file with a header row, the mapping names the column of each field.
+// A row that fails validation is reported as a warning and skipped, the other rows are still
+// read. A note becomes a quote of its own tagged importer.NOTE_TAG.
+type Parser struct {
+	mapping importer.Mapping
+}
+
+func NewParser(m importer.Mapping) Parser {
+	return Parser{mapping: m}
+}
+
+func NewReadwiseParser() Parser {
+	return NewParser(READWISE)
+}
+
+func (p Parser) Parse(r io.Reader) ([]importer.ParsedQuote, []importer.Warning, error) {
+	if strings.TrimSpace(p.mapping.Text) == "" {
+		return nil, nil, exceptions.InvalidCSVMapping
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	cols, err := p.columns(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var quotes []importer.ParsedQuote
+	var warnings []importer.Warning
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			warnings = append(warnings, *warn(parseErr.StartLine, fmt.Sprintf("invalid row: %s", parseErr.Err.Error())))
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			warnings = append(warnings, *warn(line, fmt.Sprintf("row has %d columns, the header has %d", len(record), len(header))))
+			continue
+		}
+		read, w := readRow(cols, record, line)
+		if w != nil {
+			warnings = append(warnings, *w)
+			continue
+		}
+		quotes = append(quotes, read...)
+	}
+	return quotes, warnings, nil
+}
+
+// columns finds the mapped columns in the header, names are compared ignoring case.
+func (p Parser) columns(header []string) (columns, error) {
+	var err error
+	find := func(name string) int {
+		name = strings.TrimSpace(name)
+		if name == "" || err != nil {
+			return -1
+		}
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), name) {
+				return i
+			}
+		}
+		err = fmt.Errorf("%w: %s", exceptions.UnknownCSVColumn, name)
+		return -1
+	}
+	cols := columns{
+		text:     find(p.mapping.Text),
+		book:     find(p.mapping.Book),
+		author:   find(p.mapping.Author),
+		chapter:  find(p.mapping.Chapter),
+		page:     find(p.mapping.Page),
+		pageType: find(p.mapping.PageType),
+		tags:     find(p.mapping.Tags),
+		date:     find(p.mapping.Date),
+		note:     find(p.mapping.Note),
+	}
+	return cols, err
+}
+
+// readRow reads the quote of a row, and the note after it when the row has one. The note has
+// the book of the quote, so checking the quote covers both.
+func readRow(cols columns, record []string, line int) ([]importer.ParsedQuote, *importer.Warning) {
+	get := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	q := importer.ParsedQuote{Line: line, Text: get(cols.text), Book: get(cols.book), Author: get(cols.author), Chapter: get(cols.chapter)}
+	if q.Text == "" {
+		return nil, warn(line, "row has no text")
+	}
+	if v := get(cols.page); v != "" && (cols.pageType < 0 || strings.EqualFold(get(cols.pageType), "page")) {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return nil, warn(line, fmt.Sprintf("invalid page %q", v))
+		}
+		q.Page = page
+	}
+	if v := get(cols.date); v != "" {
+		date, ok := parseDate(v)
+		if !ok {
+			return nil, warn(line, fmt.Sprintf("invalid date %q", v))
+		}
+		q.Date = &date
+	}
+	q.Tags = splitTags(get(cols.tags))
+	if w := q.Check(); w != nil {
+		return nil, w
+	}
+
+	quotes := []importer.ParsedQuote{q}
+	if note := get(cols.note); note != "" {
+		n := q
+		n.Text, n.Tags = note, []string{importer.NOTE_TAG}
+		quotes = append(quotes, n)
+	}
+	return quotes, nil
+}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range DATE_LAYOUTS {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// splitTags splits a comma separated list, nil when there is no tag.
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+func warn(line int, message string) *importer.Warning {
+	return &importer.Warning{Line: line, Message: message}
+}
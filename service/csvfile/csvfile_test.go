@@ -0,0 +1,104 @@
+package csvfile
+
+import (
+	"github.com/stretchr/testify/assert"
+	"myquote/domain/exceptions"
+	"myquote/domain/importer"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReadwiseExport(t *testing.T) {
+	file := "\ufeffHighlight,Book Title,Book Author,Amazon Book ID,Note,Color,Tags,Location Type,Location,Highlighted at,Document tags\n" +
+		"\"Quote 1, with comma\",Book1,Author1,B01,,yellow,\"life, habit\",page,12,2021-05-13 04:33:00+00:00,\n" +
+		"Quote 2,Book1,Author1,B01,My note,yellow,,location,1234,2021-05-14 08:00:00+00:00,\n"
+	quotes, warnings, err := NewReadwiseParser().Parse(strings.NewReader(file))
+
+	first := time.Date(2021, 5, 13, 4, 33, 0, 0, time.UTC)
+	second := time.Date(2021, 5, 14, 8, 0, 0, 0, time.UTC)
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Len(t, quotes, 3)
+	assert.Equal(t, importer.ParsedQuote{Line: 2, Book: "Book1", Author: "Author1", Page: 12, Text: "Quote 1, with comma", Tags: []string{"life", "habit"}}, withoutDate(quotes[0]))
+	assert.True(t, first.Equal(*quotes[0].Date))
+	assert.Equal(t, importer.ParsedQuote{Line: 3, Book: "Book1", Author: "Author1", Text: "Quote 2"}, withoutDate(quotes[1]))
+	assert.Equal(t, importer.ParsedQuote{Line: 3, Book: "Book1", Author: "Author1", Text: "My note", Tags: []string{importer.NOTE_TAG}}, withoutDate(quotes[2]))
+	assert.True(t, second.Equal(*quotes[2].Date))
+}
+
+func TestParseMapping(t *testing.T) {
+	file := "quote;title;part;p\nQuote 1;Book1;Chapter 1;7\n"
+	parser := NewParser(importer.Mapping{Text: "Quote", Book: " Title ", Chapter: "PART", Page: "p"})
+	quotes, warnings, err := parser.Parse(strings.NewReader(strings.ReplaceAll(file, ";", ",")))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []importer.ParsedQuote{{Line: 2, Book: "Book1", Chapter: "Chapter 1", Page: 7, Text: "Quote 1"}}, quotes)
+}
+
+func TestParseReportsRowErrors(t *testing.T) {
+	file := `text,page,date
+Quote 1,1,2022-05-01
+,2,
+Quote 3,three,
+Quote 4,4,May 1st
+Quote 5
+"Quote "6"",6,
+"Quote 7
+spans lines",7,2022-05-01T08:00:00Z
+`
+	quotes, warnings, err := NewParser(importer.Mapping{Text: "text", Page: "page", Date: "date"}).Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Len(t, quotes, 2)
+	assert.Equal(t, "Quote 1", quotes[0].Text)
+	assert.Equal(t, "Quote 7\nspans lines", quotes[1].Text)
+	assert.Equal(t, 8, quotes[1].Line)
+	assert.Equal(t, []importer.Warning{
+		{Line: 3, Message: "row has no text"},
+		{Line: 4, Message: `invalid page "three"`},
+		{Line: 5, Message: `invalid date "May 1st"`},
+		{Line: 6, Message: "row has 1 columns, the header has 3"},
+		{Line: 7, Message: `invalid row: extraneous or missing " in quoted-field`},
+	}, warnings)
+}
+
+func TestParseReportsTooLongTagsAndTitles(t *testing.T) {
+	file := "text,book,tags\n" +
+		"Quote 1,Book1,life\n" +
+		"Quote 2,Book1," + strings.Repeat("a", 101) + "\n" +
+		"Quote 3," + strings.Repeat("b", 256) + ",\n" +
+		"Quote 4,Book1,\n"
+	quotes, warnings, err := NewParser(importer.Mapping{Text: "text", Book: "book", Tags: "tags"}).Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Len(t, quotes, 2)
+	assert.Equal(t, "Quote 1", quotes[0].Text)
+	assert.Equal(t, "Quote 4", quotes[1].Text)
+	assert.Equal(t, []importer.Warning{
+		{Line: 3, Message: "tag is longer than 100 characters"},
+		{Line: 4, Message: "book title is longer than 255 characters"},
+	}, warnings)
+}
+
+func TestParseMappingErrors(t *testing.T) {
+	_, _, err := NewParser(importer.Mapping{Book: "title"}).Parse(strings.NewReader("text,title\n"))
+	assert.Equal(t, exceptions.InvalidCSVMapping, err)
+
+	_, _, err = NewParser(importer.Mapping{Text: "text", Book: "book"}).Parse(strings.NewReader("text,title\n"))
+	assert.ErrorIs(t, err, exceptions.UnknownCSVColumn)
+	assert.Contains(t, err.Error(), "book")
+}
+
+func TestParseEmptyFile(t *testing.T) {
+	quotes, warnings, err := NewParser(importer.Mapping{Text: "text"}).Parse(strings.NewReader(""))
+	assert.Nil(t, err)
+	assert.Empty(t, quotes)
+	assert.Empty(t, warnings)
+}
+
+func withoutDate(q importer.ParsedQuote) importer.ParsedQuote {
+	q.Date = nil
+	return q
+}
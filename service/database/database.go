@@ -0,0 +1,57 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/glebarez/sqlite"
+	mysqlerr "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	MYSQL_DUPLICATE_ENTRY        = 1062
+	SQLITE_CONSTRAINT_PRIMARYKEY = 1555
+	SQLITE_CONSTRAINT_UNIQUE     = 2067
+)
+
+func Open(driver string, dsn string) (*gorm.DB, error) {
+	switch driver {
+	case "sqlite":
+		return gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	case "mysql":
+		return gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+// Memory opens a private in-memory sqlite database.
+// It is limited to one connection, otherwise every new connection would see an empty database.
+func Memory() (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// IsDuplicate tells whether err is a write rejected by a unique index or a primary key.
+func IsDuplicate(err error) bool {
+	var mysqlErr *mysqlerr.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == MYSQL_DUPLICATE_ENTRY
+	}
+	// the sqlite driver is not imported for its error type, its errors tell their code
+	var sqliteErr interface{ Code() int }
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package hash
+
+import "golang.org/x/crypto/bcrypt"
+
+type BcryptValidator struct {
+	cost int
+}
+
+func NewBcryptValidator() BcryptValidator {
+	return BcryptValidator{cost: bcrypt.DefaultCost}
+}
+
+func (v BcryptValidator) Hash(s string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(s), v.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (v BcryptValidator) Compare(s string, h string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(h), []byte(s)) == nil
+}
@@ -0,0 +1,276 @@
+package kindle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"myquote/domain/importer"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const MAX_LINE_SIZE = 1024 * 1024
+
+// SEPARATOR ends every clipping of the file.
+const SEPARATOR = "=========="
+
+// MIN_OVERLAP is how many characters an edited highlight shares with the one it replaces
+// when neither contains the other.
+const MIN_OVERLAP = 10
+
+type kind int
+
+const (
+	highlight kind = iota
+	note
+	bookmark
+)
+
+var (
+	titleAuthor = regexp.MustCompile(`^(.*\S)\s*\(([^()]*)\)$`)
+	location    = regexp.MustCompile(`(?i)\b(?:location|loc\.)\s*#?(\d+)(?:\s*-\s*(\d+))?`)
+	page        = regexp.MustCompile(`(?i)\bpage\s+(\d+)`)
+)
+
+type clipping struct {
+	line   int
+	kind   kind
+	book   string
+	author string
+	page   int
+	// start and end are the location range, 0 when the clipping has none
+	start, end int
+	text       string
+}
+
+// Parser reads the "My Clippings.txt" file of a Kindle, where every clipping is shaped as
+//
+//	Title (Author)
+//	- Your Highlight on page 12 | Location 123-125 | Added on Monday, May 2, 2022 8:00:00 AM
+//
+//	The highlighted text
+//	==========
+//
+// Highlights become quotes, notes become quotes tagged importer.NOTE_TAG and bookmarks are skipped.
+// Kindle appends a new clipping when a highlight is edited and keeps the old one, so of the
+// highlights of a book that overlap only the last one is kept. The quotes are grouped by book
+// in the order the books first appear and sorted by location inside a book.
+type Parser struct{}
+
+func NewParser() Parser {
+	return Parser{}
+}
+
+func (p Parser) Parse(r io.Reader) ([]importer.ParsedQuote, []importer.Warning, error) {
+	var clippings []clipping
+	var warnings []importer.Warning
+	var entry []string
+	start := 0
+	read := func() {
+		c, ok, w := readClipping(start, entry)
+		if w != nil {
+			warnings = append(warnings, *w)
+		}
+		if ok {
+			clippings = append(clippings, c)
+		}
+		entry = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MAX_LINE_SIZE)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if raw == SEPARATOR {
+			read()
+			continue
+		}
+		if entry == nil {
+			start = line
+		}
+		entry = append(entry, raw)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if entry != nil {
+		read()
+	}
+
+	kept, dropped := deduplicate(clippings)
+	warnings = append(warnings, dropped...)
+	quotes, invalid := importer.Valid(group(kept))
+	warnings = append(warnings, invalid...)
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Line < warnings[j].Line })
+	return quotes, warnings, nil
+}
+
+// readClipping reads the lines between two separators, ok is false when there is nothing to import.
+func readClipping(start int, lines []string) (clipping, bool, *importer.Warning) {
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+		start++
+	}
+	if len(lines) == 0 {
+		return clipping{}, false, nil
+	}
+	if len(lines) < 2 || !strings.HasPrefix(lines[1], "-") {
+		return clipping{}, false, warn(start, "clipping has no metadata line")
+	}
+
+	c := clipping{line: start}
+	c.book, c.author = splitTitle(lines[0])
+	meta := strings.TrimSpace(strings.TrimPrefix(lines[1], "-"))
+	kind, ok := readKind(meta)
+	if !ok {
+		return clipping{}, false, warn(start+1, "unknown clipping type")
+	}
+	c.kind = kind
+	if kind == bookmark {
+		return clipping{}, false, nil
+	}
+	c.start, c.end = readLocation(meta)
+	if m := page.FindStringSubmatch(meta); m != nil {
+		c.page, _ = strconv.Atoi(m[1])
+	}
+	c.text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+	if c.text == "" {
+		if kind == note {
+			return clipping{}, false, warn(start, "empty note")
+		}
+		return clipping{}, false, warn(start, "empty highlight")
+	}
+	return c, true, nil
+}
+
+// splitTitle splits "Title (Author)" into the title and the author, the last parentheses hold the author.
+func splitTitle(s string) (string, string) {
+	m := titleAuthor.FindStringSubmatch(s)
+	if m == nil {
+		return s, ""
+	}
+	return m[1], strings.TrimSpace(m[2])
+}
+
+// readKind reads the type from the first part of the metadata line, like "Your Highlight on page 12".
+func readKind(meta string) (kind, bool) {
+	first := strings.ToLower(strings.SplitN(meta, "|", 2)[0])
+	switch {
+	case strings.Contains(first, "bookmark"):
+		return bookmark, true
+	case strings.Contains(first, "note"):
+		return note, true
+	case strings.Contains(first, "highlight"):
+		return highlight, true
+	default:
+		return 0, false
+	}
+}
+
+// readLocation reads a range like "Location 123-125". Older Kindles shorten the end,
+// "Loc. 1234-36" is 1234-1236.
+func readLocation(meta string) (int, int) {
+	m := location.FindStringSubmatch(meta)
+	if m == nil {
+		return 0, 0
+	}
+	start, _ := strconv.Atoi(m[1])
+	if m[2] == "" {
+		return start, start
+	}
+	end, _ := strconv.Atoi(m[2])
+	if end < start {
+		mod := 1
+		for range m[2] {
+			mod *= 10
+		}
+		end += start - start%mod
+		if end < start {
+			end += mod
+		}
+	}
+	return start, end
+}
+
+// deduplicate drops the highlights a later highlight of the same book overlaps.
+func deduplicate(clippings []clipping) ([]clipping, []importer.Warning) {
+	var kept []clipping
+	var warnings []importer.Warning
+	for _, c := range clippings {
+		if c.kind != highlight {
+			kept = append(kept, c)
+			continue
+		}
+		remaining := kept[:0]
+		for _, k := range kept {
+			if k.kind == highlight && overlaps(k, c) {
+				warnings = append(warnings, *warn(k.line, fmt.Sprintf("highlight is replaced by the overlapping one on line %d", c.line)))
+				continue
+			}
+			remaining = append(remaining, k)
+		}
+		kept = append(remaining, c)
+	}
+	return kept, warnings
+}
+
+// overlaps tells whether b is an edit of a, their locations overlap and one text contains
+// or continues the other. The locations alone are too coarse, two highlights next to each
+// other can share one, and the text alone drops a short highlight found again elsewhere.
+func overlaps(a clipping, b clipping) bool {
+	if a.book != b.book {
+		return false
+	}
+	if a.start == 0 || b.start == 0 || a.start > b.end || b.start > a.end {
+		return false
+	}
+	if strings.Contains(a.text, b.text) || strings.Contains(b.text, a.text) {
+		return true
+	}
+	return shared(a.text, b.text) || shared(b.text, a.text)
+}
+
+// shared tells whether the end of a is the start of b for at least MIN_OVERLAP characters.
+func shared(a string, b string) bool {
+	for n := len(b) - 1; n >= MIN_OVERLAP; n-- {
+		if strings.HasSuffix(a, b[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// group orders the clippings by the first appearance of their book, then by location.
+func group(clippings []clipping) []importer.ParsedQuote {
+	books := map[string]int{}
+	for _, c := range clippings {
+		if _, ok := books[c.book]; !ok {
+			books[c.book] = len(books)
+		}
+	}
+	sort.SliceStable(clippings, func(i, j int) bool {
+		a, b := clippings[i], clippings[j]
+		if books[a.book] != books[b.book] {
+			return books[a.book] < books[b.book]
+		}
+		return a.start < b.start
+	})
+
+	quotes := make([]importer.ParsedQuote, 0, len(clippings))
+	for _, c := range clippings {
+		q := importer.ParsedQuote{Line: c.line, Book: c.book, Author: c.author, Page: c.page, Text: c.text}
+		if c.kind == note {
+			q.Tags = []string{importer.NOTE_TAG}
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes
+}
+
+func warn(line int, message string) *importer.Warning {
+	return &importer.Warning{Line: line, Message: message}
+}
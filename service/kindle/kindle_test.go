@@ -0,0 +1,213 @@
+package kindle
+
+import (
+	"github.com/stretchr/testify/assert"
+	"myquote/domain/importer"
+	"strings"
+	"testing"
+)
+
+func TestParseHighlightsNotesAndBookmarks(t *testing.T) {
+	file := "\ufeffBook1 (Author1)\r\n" +
+		"- Your Highlight on page 12 | Location 180-182 | Added on Monday, May 2, 2022 8:00:00 AM\r\n" +
+		"\r\n" +
+		"Quote 1\r\n" +
+		"==========\r\n" +
+		"\ufeffBook1 (Author1)\r\n" +
+		"- Your Bookmark on page 13 | Location 190 | Added on Monday, May 2, 2022 8:05:00 AM\r\n" +
+		"\r\n" +
+		"\r\n" +
+		"==========\r\n" +
+		"\ufeffBook1 (Author1)\r\n" +
+		"- Your Note on page 12 | Location 182 | Added on Monday, May 2, 2022 8:06:00 AM\r\n" +
+		"\r\n" +
+		"My note\r\n" +
+		"==========\r\n"
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []importer.ParsedQuote{
+		{Line: 1, Book: "Book1", Author: "Author1", Page: 12, Text: "Quote 1"},
+		{Line: 11, Book: "Book1", Author: "Author1", Page: 12, Text: "My note", Tags: []string{importer.NOTE_TAG}},
+	}, quotes)
+}
+
+func TestParseTitles(t *testing.T) {
+	file := `The Book (Series 1) (Last, First)
+- Your Highlight on Location 10-12 | Added on Monday, May 2, 2022 8:00:00 AM
+
+Quote 1
+==========
+Untitled document
+- Your Highlight on page 3 | Added on Monday, May 2, 2022 8:00:00 AM
+
+Quote 2
+==========
+`
+	quotes, _, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "The Book (Series 1)", quotes[0].Book)
+	assert.Equal(t, "Last, First", quotes[0].Author)
+	assert.Equal(t, "Untitled document", quotes[1].Book)
+	assert.Equal(t, "", quotes[1].Author)
+	assert.Equal(t, 3, quotes[1].Page)
+}
+
+func TestParseGroupsByBookAndLocation(t *testing.T) {
+	file := `Book1 (Author1)
+- Your Highlight on Location 300-301 | Added on Monday, May 2, 2022 8:00:00 AM
+
+Book1 later
+==========
+Book2 (Author2)
+- Your Highlight on Location 50-51 | Added on Monday, May 2, 2022 8:01:00 AM
+
+Book2 quote
+==========
+Book1 (Author1)
+- Highlight Loc. 1234-36 | Added on Monday, May 2, 2022 8:02:00 AM
+
+Book1 further
+==========
+Book1 (Author1)
+- Your Highlight on Location 100-101 | Added on Monday, May 2, 2022 8:03:00 AM
+
+Book1 earlier
+`
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	var texts []string
+	for _, q := range quotes {
+		texts = append(texts, q.Text)
+	}
+	assert.Equal(t, []string{"Book1 earlier", "Book1 later", "Book1 further", "Book2 quote"}, texts)
+}
+
+func TestParseKeepsTheLastOfOverlappingHighlights(t *testing.T) {
+	file := `Book1 (Author1)
+- Your Highlight on Location 10-11 | Added on Monday, May 2, 2022 8:00:00 AM
+
+The quick brown fox
+==========
+Book1 (Author1)
+- Your Highlight on Location 10-12 | Added on Monday, May 2, 2022 8:01:00 AM
+
+The quick brown fox jumps over the lazy dog
+==========
+Book1 (Author1)
+- Your Highlight on Location 12-14 | Added on Monday, May 2, 2022 8:02:00 AM
+
+over the lazy dog. It barked.
+==========
+Book1 (Author1)
+- Your Highlight on Location 14-15 | Added on Monday, May 2, 2022 8:03:00 AM
+
+Next to it, but another highlight.
+==========
+Book2 (Author2)
+- Your Highlight on Location 10-11 | Added on Monday, May 2, 2022 8:04:00 AM
+
+The quick brown fox
+==========
+`
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []importer.ParsedQuote{
+		{Line: 11, Book: "Book1", Author: "Author1", Text: "over the lazy dog. It barked."},
+		{Line: 16, Book: "Book1", Author: "Author1", Text: "Next to it, but another highlight."},
+		{Line: 21, Book: "Book2", Author: "Author2", Text: "The quick brown fox"},
+	}, quotes)
+	assert.Equal(t, []importer.Warning{
+		{Line: 1, Message: "highlight is replaced by the overlapping one on line 6"},
+		{Line: 6, Message: "highlight is replaced by the overlapping one on line 11"},
+	}, warnings)
+}
+
+func TestParseKeepsContainedHighlightsFarApart(t *testing.T) {
+	file := `Book1 (Author1)
+- Your Highlight on Location 10-11 | Added on Monday, May 2, 2022 8:00:00 AM
+
+Why?
+==========
+Book1 (Author1)
+- Your Highlight on Location 500-502 | Added on Monday, May 2, 2022 8:01:00 AM
+
+Why? Because the river does not ask.
+==========
+`
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []importer.ParsedQuote{
+		{Line: 1, Book: "Book1", Author: "Author1", Text: "Why?"},
+		{Line: 6, Book: "Book1", Author: "Author1", Text: "Why? Because the river does not ask."},
+	}, quotes)
+}
+
+func TestParseWarnsMalformedClippings(t *testing.T) {
+	file := `Book1 (Author1)
+Not a metadata line
+==========
+Book1 (Author1)
+- Your Clipping on Location 1 | Added on Monday, May 2, 2022 8:00:00 AM
+
+Something
+==========
+Book1 (Author1)
+- Your Highlight on Location 2 | Added on Monday, May 2, 2022 8:00:00 AM
+
+==========
+Book1 (Author1)
+- Your Highlight on Location 3 | Added on Monday, May 2, 2022 8:00:00 AM
+
+Quote 1
+second line
+==========
+`
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []importer.ParsedQuote{{Line: 13, Book: "Book1", Author: "Author1", Text: "Quote 1\nsecond line"}}, quotes)
+	assert.Equal(t, []importer.Warning{
+		{Line: 1, Message: "clipping has no metadata line"},
+		{Line: 5, Message: "unknown clipping type"},
+		{Line: 9, Message: "empty highlight"},
+	}, warnings)
+}
+
+func TestParseReportsTooLongTitles(t *testing.T) {
+	file := strings.Repeat("a", 256) + " (Author1)\n" +
+		"- Your Highlight on Location 3 | Added on Monday, May 2, 2022 8:00:00 AM\n" +
+		"\n" +
+		"Quote 1\n" +
+		"==========\n" +
+		"Book2 (Author2)\n" +
+		"- Your Highlight on Location 5 | Added on Monday, May 2, 2022 8:00:00 AM\n" +
+		"\n" +
+		"Quote 2\n" +
+		"==========\n"
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []importer.ParsedQuote{{Line: 6, Book: "Book2", Author: "Author2", Text: "Quote 2"}}, quotes)
+	assert.Equal(t, []importer.Warning{{Line: 1, Message: "book title is longer than 255 characters"}}, warnings)
+}
+
+func TestReadLocation(t *testing.T) {
+	for meta, expected := range map[string][2]int{
+		"Your Highlight on Location 123-125": {123, 125},
+		"Highlight Loc. 1234-36":             {1234, 1236},
+		"Highlight Loc. 1298-302":            {1298, 1302},
+		"Your Note on location 42":           {42, 42},
+		"Your Highlight on page 3":           {0, 0},
+	} {
+		start, end := readLocation(meta)
+		assert.Equal(t, expected, [2]int{start, end}, meta)
+	}
+}
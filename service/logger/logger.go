@@ -17,6 +17,7 @@ func NewLogger(path string) Logger {
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
 	if err != nil {
 		l.SetOutput(os.Stdout)
+		return Logger{l, nil}
 	}
 	l.SetOutput(f)
 	return Logger{l, f}
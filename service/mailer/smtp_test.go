@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"github.com/stretchr/testify/suite"
+	"io"
+	"mime"
+	"mime/multipart"
+	"myquote/domain/mail"
+	"myquote/service/mailer/smtptest"
+	netmail "net/mail"
+	"strings"
+	"testing"
+)
+
+type SMTPMailerTestSuite struct {
+	suite.Suite
+	server *smtptest.Server
+	mailer SMTPMailer
+}
+
+func TestSMTPMailer(t *testing.T) {
+	suite.Run(t, new(SMTPMailerTestSuite))
+}
+
+func (s *SMTPMailerTestSuite) SetupTest() {
+	server, err := smtptest.NewServer()
+	s.Require().NoError(err)
+	s.server = server
+	s.mailer = NewSMTPMailer(server.Host(), server.Port(), "", "", "myquote@example.com")
+}
+
+func (s *SMTPMailerTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *SMTPMailerTestSuite) TestSendMultipartMessage() {
+	err := s.mailer.Send(mail.Message{
+		To:      "123@gmail.com",
+		Subject: "每週回顧",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	})
+	s.Require().Nil(err)
+
+	messages := s.server.Messages()
+	s.Require().Len(messages, 1)
+	s.Assert().Equal("myquote@example.com", messages[0].From)
+	s.Assert().Equal([]string{"123@gmail.com"}, messages[0].To)
+
+	msg, err := netmail.ReadMessage(strings.NewReader(messages[0].Data))
+	s.Require().Nil(err)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	s.Assert().Equal("每週回顧", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	s.Require().Nil(err)
+	s.Assert().Equal("multipart/alternative", mediaType)
+
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		s.Require().Nil(err)
+		b, _ := io.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(b))
+	}
+	s.Assert().Equal([]string{
+		"text/plain; charset=utf-8: plain body",
+		"text/html; charset=utf-8: <p>html body</p>",
+	}, bodies)
+}
+
+func (s *SMTPMailerTestSuite) TestSendToUnreachableServer() {
+	s.server.Close()
+	err := s.mailer.Send(mail.Message{To: "123@gmail.com", Subject: "subject"})
+	s.Assert().NotNil(err)
+}
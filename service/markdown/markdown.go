@@ -0,0 +1,139 @@
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"myquote/domain/importer"
+	"sort"
+	"strings"
+)
+
+const MAX_LINE_SIZE = 1024 * 1024
+
+// ESCAPED are the characters a backslash escapes at the start of a continuation line.
+const ESCAPED = "-*\\"
+
+// Parser reads notes shaped as
+//
+//	## Book
+//	### Chapter
+//	- Quote
+//
+// Lines indented under a bullet continue its quote, also after blank lines, which stay in the
+// quote. A backslash escapes a continuation line starting with '-', '*' or '\'. Anything else
+// is reported as a warning and skipped, so one malformed line does not reject the whole file,
+// like a quote whose book or chapter title is too long.
+type Parser struct{}
+
+func NewParser() Parser {
+	return Parser{}
+}
+
+func (p Parser) Parse(r io.Reader) ([]importer.ParsedQuote, []importer.Warning, error) {
+	var quotes []importer.ParsedQuote
+	var warnings []importer.Warning
+	book, chapter := "", ""
+	// current is the index of the quote continuation lines are appended to, -1 when there is none
+	current := -1
+	// blanks counts the blank lines since the last line of the current quote
+	blanks := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MAX_LINE_SIZE)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			blanks++
+			continue
+		}
+		gap := blanks
+		blanks = 0
+
+		switch {
+		case isContinuation(raw) && !isBullet(trimmed):
+			if current < 0 {
+				warnings = append(warnings, warn(line, "indented line does not belong to a quote"))
+				continue
+			}
+			quotes[current].Text += strings.Repeat("\n", gap+1) + unescape(trimmed)
+		case strings.HasPrefix(trimmed, "#"):
+			current = -1
+			level, title := heading(trimmed)
+			switch {
+			case level == 0:
+				warnings = append(warnings, warn(line, "line is not a heading or a quote"))
+			case level == 1:
+				// a document title, nothing to store
+			case level == 2 && title != "":
+				book, chapter = title, ""
+			case level == 3 && title != "":
+				chapter = title
+			case title == "":
+				warnings = append(warnings, warn(line, "heading without title"))
+			default:
+				warnings = append(warnings, warn(line, fmt.Sprintf("unsupported heading level %d", level)))
+			}
+		case isBullet(trimmed):
+			text := strings.TrimSpace(trimmed[1:])
+			if text == "" {
+				current = -1
+				warnings = append(warnings, warn(line, "empty quote"))
+				continue
+			}
+			quotes = append(quotes, importer.ParsedQuote{Line: line, Book: book, Chapter: chapter, Text: text})
+			current = len(quotes) - 1
+		default:
+			current = -1
+			warnings = append(warnings, warn(line, "line is not a heading or a quote"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	// a quote is checked once its continuation lines are read
+	quotes, invalid := importer.Valid(quotes)
+	warnings = append(warnings, invalid...)
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Line < warnings[j].Line })
+	return quotes, warnings, nil
+}
+
+func isBullet(s string) bool {
+	return strings.HasPrefix(s, "- ") || strings.HasPrefix(s, "* ") || s == "-" || s == "*"
+}
+
+// unescape drops the backslash of an escaped continuation line.
+func unescape(s string) string {
+	if len(s) > 1 && s[0] == '\\' && strings.ContainsRune(ESCAPED, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+func isContinuation(s string) bool {
+	return strings.HasPrefix(s, "  ") || strings.HasPrefix(s, "\t")
+}
+
+// heading returns the level and the title of a line starting with '#'.
+// A line like "#tag" is not a heading and has level 0.
+func heading(s string) (int, string) {
+	level := 0
+	for level < len(s) && s[level] == '#' {
+		level++
+	}
+	rest := s[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(rest)
+}
+
+func warn(line int, message string) importer.Warning {
+	return importer.Warning{Line: line, Message: message}
+}
@@ -0,0 +1,115 @@
+package markdown
+
+import (
+	"github.com/stretchr/testify/assert"
+	"myquote/domain/importer"
+	"strings"
+	"testing"
+)
+
+func TestParseBooksAndChapters(t *testing.T) {
+	file := `## Book1
+### Chapter 1
+- Quote 1
+- Quote 2
+- Quote 3
+### Chapter 2
+- Quote 4
+- Quote 5 
+`
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []importer.ParsedQuote{
+		{Line: 3, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 1"},
+		{Line: 4, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 2"},
+		{Line: 5, Book: "Book1", Chapter: "Chapter 1", Text: "Quote 3"},
+		{Line: 7, Book: "Book1", Chapter: "Chapter 2", Text: "Quote 4"},
+		{Line: 8, Book: "Book1", Chapter: "Chapter 2", Text: "Quote 5"},
+	}, quotes)
+}
+
+func TestParseNewBookResetsChapter(t *testing.T) {
+	file := "## Book1\n### Chapter 1\n- Quote 1\n## Book2\n- Quote 2\n"
+	quotes, _, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Book2", quotes[1].Book)
+	assert.Equal(t, "", quotes[1].Chapter)
+}
+
+func TestParseContinuationLines(t *testing.T) {
+	file := "## Book1\n- first line\n  second line\n- Quote 2\n"
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "first line\nsecond line", quotes[0].Text)
+	assert.Equal(t, "Quote 2", quotes[1].Text)
+}
+
+func TestParseReportsMalformedLines(t *testing.T) {
+	file := `# Notes
+## Book1
+just some text
+-
+#### Too deep
+##
+  dangling
+* Quote 1
+`
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []importer.ParsedQuote{{Line: 8, Book: "Book1", Text: "Quote 1"}}, quotes)
+	assert.Equal(t, []importer.Warning{
+		{Line: 3, Message: "line is not a heading or a quote"},
+		{Line: 4, Message: "empty quote"},
+		{Line: 5, Message: "unsupported heading level 4"},
+		{Line: 6, Message: "heading without title"},
+		{Line: 7, Message: "indented line does not belong to a quote"},
+	}, warnings)
+}
+
+func TestParseReportsTooLongTitles(t *testing.T) {
+	file := "## " + strings.Repeat("a", 256) + "\n- Quote 1\n  still quote 1\n" +
+		"## Book2\n### " + strings.Repeat("b", 256) + "\n- Quote 2\n" +
+		"### Chapter1\n- Quote 3\n- \n"
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []importer.ParsedQuote{{Line: 8, Book: "Book2", Chapter: "Chapter1", Text: "Quote 3"}}, quotes)
+	assert.Equal(t, []importer.Warning{
+		{Line: 2, Message: "book title is longer than 255 characters"},
+		{Line: 6, Message: "chapter title is longer than 255 characters"},
+		{Line: 9, Message: "empty quote"},
+	}, warnings)
+}
+
+func TestParseEmptyFile(t *testing.T) {
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(""))
+
+	assert.Nil(t, err)
+	assert.Empty(t, quotes)
+	assert.Empty(t, warnings)
+}
+
+func TestParseBlankLinesInsideQuote(t *testing.T) {
+	file := "## Book1\n- first paragraph\n\n  second paragraph\n\n- Quote 2\n\n## Book2\n"
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "first paragraph\n\nsecond paragraph", quotes[0].Text)
+	assert.Equal(t, "Quote 2", quotes[1].Text)
+}
+
+func TestParseEscapedContinuationLines(t *testing.T) {
+	file := "- Quote 1\n  \\- still quote 1\n  \\x stays\n"
+	quotes, warnings, err := NewParser().Parse(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, []importer.ParsedQuote{{Line: 1, Text: "Quote 1\n- still quote 1\n\\x stays"}}, quotes)
+}
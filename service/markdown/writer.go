@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"myquote/domain/importer"
+	"strings"
+)
+
+// ErrUnordered is returned for a quote without book after quotes with one, or without chapter
+// after quotes with one, Parser would put it into that book or chapter.
+var ErrUnordered = errors.New("quotes without book or chapter should come first")
+
+// Writer writes quotes in the layout Parser reads, so parsing the output gives the same books,
+// chapters and texts. The quotes should come grouped by book and chapter, quotes without book
+// first. Spaces around the lines of a quote are dropped, as Parser drops them.
+type Writer struct {
+	w       *bufio.Writer
+	book    string
+	chapter string
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (mw *Writer) Write(q importer.ParsedQuote) error {
+	book, chapter := oneLine(q.Book), oneLine(q.Chapter)
+	if book == "" && (mw.book != "" || (chapter == "" && mw.chapter != "")) {
+		return ErrUnordered
+	}
+
+	var b strings.Builder
+	// a book heading resets the chapter, it also takes a book back to no chapter
+	if book != mw.book || (chapter == "" && mw.chapter != "") {
+		b.WriteString("## " + book + "\n")
+		mw.book, mw.chapter = book, ""
+	}
+	if chapter != mw.chapter {
+		b.WriteString("### " + chapter + "\n")
+		mw.chapter = chapter
+	}
+	for i, line := range strings.Split(strings.TrimSpace(q.Text), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case i == 0:
+			b.WriteString("- " + line + "\n")
+		case line == "":
+			b.WriteString("\n")
+		case strings.ContainsRune(ESCAPED, rune(line[0])):
+			b.WriteString("  \\" + line + "\n")
+		default:
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	_, err := mw.w.WriteString(b.String())
+	return err
+}
+
+// Flush writes the buffered quotes to the underlying writer.
+func (mw *Writer) Flush() error {
+	return mw.w.Flush()
+}
+
+// oneLine keeps a heading on one line.
+func oneLine(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(s))
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"myquote/domain/importer"
+	"testing"
+)
+
+func write(t *testing.T, quotes []importer.ParsedQuote) string {
+	var b bytes.Buffer
+	w := NewWriter(&b)
+	for _, q := range quotes {
+		assert.Nil(t, w.Write(q))
+	}
+	assert.Nil(t, w.Flush())
+	return b.String()
+}
+
+func TestWriteReadmeLayout(t *testing.T) {
+	actual := write(t, []importer.ParsedQuote{
+		{Book: "Book1", Chapter: "Chapter 1", Text: "Quote 1"},
+		{Book: "Book1", Chapter: "Chapter 1", Text: "Quote 2"},
+		{Book: "Book1", Chapter: "Chapter 2", Text: "Quote 3"},
+		{Book: "Book2", Text: "Quote 4"},
+	})
+
+	assert.Equal(t, "## Book1\n### Chapter 1\n- Quote 1\n- Quote 2\n### Chapter 2\n- Quote 3\n## Book2\n- Quote 4\n", actual)
+}
+
+func TestWriteRoundTrips(t *testing.T) {
+	quotes := []importer.ParsedQuote{
+		{Text: "no book"},
+		{Chapter: "Loose chapter", Text: "chapter without book"},
+		{Book: "Book1", Text: "first line\nsecond line"},
+		{Book: "Book1", Text: "paragraph\n\n\nafter two blank lines"},
+		{Book: "Book1", Chapter: "Chapter 1", Text: "list\n- not a new quote\n* nor this\n\\ a backslash\n## not a heading"},
+		{Book: "Book1", Text: "back to no chapter"},
+		{Book: "# Book2", Chapter: "### Chapter", Text: "- dash first"},
+	}
+	parsed, warnings, err := NewParser().Parse(bytes.NewBufferString(write(t, quotes)))
+
+	assert.Nil(t, err)
+	assert.Empty(t, warnings)
+	for i := range parsed {
+		parsed[i].Line = 0
+	}
+	assert.Equal(t, quotes, parsed)
+}
+
+func TestWriteDropsSpacesAroundLines(t *testing.T) {
+	actual := write(t, []importer.ParsedQuote{{Book: " Book1\n", Text: "  first  \n   \n\tsecond"}})
+	assert.Equal(t, "## Book1\n- first\n\n  second\n", actual)
+}
+
+func TestWriteRejectsQuoteWithoutBookAfterBooks(t *testing.T) {
+	w := NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Write(importer.ParsedQuote{Book: "Book1", Text: "Quote 1"}))
+	assert.Equal(t, ErrUnordered, w.Write(importer.ParsedQuote{Text: "Quote 2"}))
+
+	w = NewWriter(&bytes.Buffer{})
+	assert.Nil(t, w.Write(importer.ParsedQuote{Chapter: "Chapter 1", Text: "Quote 1"}))
+	assert.Equal(t, ErrUnordered, w.Write(importer.ParsedQuote{Text: "Quote 2"}))
+}
@@ -0,0 +1,123 @@
+package quotelink
+
+import (
+	"gorm.io/gorm"
+	"myquote/domain/models"
+)
+
+type bookKey struct {
+	userID int64
+	title  string
+}
+
+type chapterKey struct {
+	bookID int64
+	title  string
+}
+
+type tagKey struct {
+	userID int64
+	name   string
+}
+
+// Linker keeps the books, chapters and tags of quotes in step with the titles and tag names the
+// quotes hold. The repositories that write quotes use one inside their transaction, it remembers
+// what it found or created, so a batch of quotes of one book looks the book up once.
+type Linker struct {
+	tx       *gorm.DB
+	books    map[bookKey]int64
+	chapters map[chapterKey]int64
+	tags     map[tagKey]int64
+}
+
+func New(tx *gorm.DB) *Linker {
+	return &Linker{tx: tx, books: map[bookKey]int64{}, chapters: map[chapterKey]int64{}, tags: map[tagKey]int64{}}
+}
+
+// Book points the quote at the book and chapter named by Book and Chapter, creating them when
+// they are missing. The titles stay on the quote so reading a quote needs no join.
+func (l *Linker) Book(q *models.QuoteModel) error {
+	q.BookID, q.ChapterID = nil, nil
+	if q.Book == "" {
+		return nil
+	}
+	key := bookKey{userID: q.UserID, title: q.Book}
+	bookID, ok := l.books[key]
+	if !ok {
+		var book models.BookModel
+		err := l.tx.Where(models.BookModel{UserID: q.UserID, Title: q.Book}).FirstOrCreate(&book).Error
+		if err != nil {
+			return err
+		}
+		bookID = book.ID
+		l.books[key] = bookID
+	}
+	q.BookID = &bookID
+	if q.Chapter == "" {
+		return nil
+	}
+	ckey := chapterKey{bookID: bookID, title: q.Chapter}
+	chapterID, ok := l.chapters[ckey]
+	if !ok {
+		var chapter models.ChapterModel
+		err := l.tx.Where(models.ChapterModel{BookID: bookID, Title: q.Chapter}).FirstOrCreate(&chapter).Error
+		if err != nil {
+			return err
+		}
+		chapterID = chapter.ID
+		l.chapters[ckey] = chapterID
+	}
+	q.ChapterID = &chapterID
+	return nil
+}
+
+// Tags links the saved quote to the tags named by Tags, creating the missing ones, and unlinks the
+// tags named only by previous, the Tags value the quote had before. Nothing is written when the
+// value did not change, a new quote passes an empty previous.
+func (l *Linker) Tags(q models.QuoteModel, previous string) error {
+	if q.Tags == previous {
+		return nil
+	}
+	current := map[string]bool{}
+	for _, name := range models.SplitTags(q.Tags) {
+		current[name] = true
+	}
+	old := map[string]bool{}
+	var removed []string
+	for _, name := range models.SplitTags(previous) {
+		old[name] = true
+		if !current[name] {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(removed) > 0 {
+		err := l.tx.
+			Where("quote_id = ? AND tag_id IN (?)", q.ID, l.tx.Model(&models.TagModel{}).Select("id").Where("user_id = ? AND name IN ?", q.UserID, removed)).
+			Delete(&models.QuoteTagModel{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range models.SplitTags(q.Tags) {
+		if old[name] {
+			continue
+		}
+		key := tagKey{userID: q.UserID, name: name}
+		tagID, ok := l.tags[key]
+		if !ok {
+			var tag models.TagModel
+			err := l.tx.Where(models.TagModel{UserID: q.UserID, Name: name}).FirstOrCreate(&tag).Error
+			if err != nil {
+				return err
+			}
+			tagID = tag.ID
+			l.tags[key] = tagID
+		}
+		err := l.tx.Create(&models.QuoteTagModel{QuoteID: q.ID, TagID: tagID}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package quotelink
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"myquote/domain/models"
+	"myquote/service/database"
+	"testing"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := database.Memory()
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.BookModel{}, &models.ChapterModel{}, &models.TagModel{}, &models.QuoteTagModel{}))
+	return db
+}
+
+func TestLinkBookAndChapter(t *testing.T) {
+	db := newDB(t)
+	link := New(db)
+	first := models.QuoteModel{UserID: 1, Book: "Book1", Chapter: "Chapter 1"}
+	second := models.QuoteModel{UserID: 1, Book: "Book1", Chapter: "Chapter 1"}
+	other := models.QuoteModel{UserID: 2, Book: "Book1"}
+	noBook := models.QuoteModel{UserID: 1, Chapter: "Chapter 1"}
+	for _, q := range []*models.QuoteModel{&first, &second, &other, &noBook} {
+		require.NoError(t, link.Book(q))
+	}
+
+	assert.NotNil(t, first.BookID)
+	assert.Equal(t, *first.BookID, *second.BookID)
+	assert.Equal(t, *first.ChapterID, *second.ChapterID)
+	assert.NotEqual(t, *first.BookID, *other.BookID)
+	assert.Nil(t, other.ChapterID)
+	assert.Nil(t, noBook.BookID)
+	assert.Nil(t, noBook.ChapterID)
+
+	var count int64
+	db.Model(&models.BookModel{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestLinkFindsExistingBook(t *testing.T) {
+	db := newDB(t)
+	book := models.BookModel{UserID: 1, Title: "Book1"}
+	require.NoError(t, db.Create(&book).Error)
+
+	q := models.QuoteModel{UserID: 1, Book: "Book1"}
+	require.NoError(t, New(db).Book(&q))
+	assert.Equal(t, book.ID, *q.BookID)
+}
+
+func TestLinkTagsMovesOnlyChangedTags(t *testing.T) {
+	db := newDB(t)
+	link := New(db)
+	q := models.QuoteModel{ID: 1, UserID: 1, Tags: "life,love"}
+	require.NoError(t, link.Tags(q, ""))
+	var love models.TagModel
+	require.NoError(t, db.Where("name = ?", "love").First(&love).Error)
+
+	q.Tags = "love,work"
+	require.NoError(t, link.Tags(q, "life,love"))
+	var names []string
+	db.Model(&models.TagModel{}).
+		Joins("JOIN quote_tags ON quote_tags.tag_id = tags.id").
+		Where("quote_tags.quote_id = ?", q.ID).
+		Order("tags.name").
+		Pluck("tags.name", &names)
+	assert.Equal(t, []string{"love", "work"}, names)
+
+	var kept models.TagModel
+	require.NoError(t, db.Where("name = ?", "love").First(&kept).Error)
+	assert.Equal(t, love.ID, kept.ID)
+}
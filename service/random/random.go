@@ -0,0 +1,23 @@
+package random
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Random is a math/rand source that is safe for concurrent use.
+type Random struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func NewRandom() *Random {
+	return &Random{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (r *Random) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
@@ -0,0 +1,50 @@
+package schedule
+
+import (
+	"time"
+	_ "time/tzdata"
+)
+
+// SEND_HOUR is the local hour review mails go out.
+const SEND_HOUR = 8
+
+// weekdays are the days review mails go out, by mails per week.
+var weekdays = map[int][]time.Weekday{
+	1: {time.Monday},
+	2: {time.Monday, time.Thursday},
+	3: {time.Monday, time.Wednesday, time.Friday},
+}
+
+// Next returns the first send time strictly after after, in the time zone of the user.
+// An unknown mails per week count falls back to once a week and an unknown zone to UTC.
+func Next(mailsPerWeek int, timeZone string, after time.Time) time.Time {
+	days, ok := weekdays[mailsPerWeek]
+	if !ok {
+		days = weekdays[1]
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := after.In(loc)
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		at := time.Date(day.Year(), day.Month(), day.Day(), SEND_HOUR, 0, 0, 0, loc)
+		if !at.After(after) || !contains(days, at.Weekday()) {
+			continue
+		}
+		return at
+	}
+	// unreachable, every weekday appears within 8 days
+	return after.AddDate(0, 0, 7)
+}
+
+func contains(days []time.Weekday, d time.Weekday) bool {
+	for _, day := range days {
+		if day == d {
+			return true
+		}
+	}
+	return false
+}
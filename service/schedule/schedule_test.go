@@ -0,0 +1,43 @@
+package schedule
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNextOncePerWeek(t *testing.T) {
+	// Tuesday
+	after := time.Date(2022, 5, 3, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC), Next(1, "UTC", after))
+}
+
+func TestNextSameDayBeforeSendHour(t *testing.T) {
+	// Monday 07:59
+	after := time.Date(2022, 5, 2, 7, 59, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC), Next(1, "UTC", after))
+}
+
+func TestNextIsStrictlyAfter(t *testing.T) {
+	after := time.Date(2022, 5, 2, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC), Next(1, "UTC", after))
+}
+
+func TestNextTwiceAndThreeTimesPerWeek(t *testing.T) {
+	// Monday after the mail went out
+	after := time.Date(2022, 5, 2, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 5, 5, 8, 0, 0, 0, time.UTC), Next(2, "UTC", after))
+	assert.Equal(t, time.Date(2022, 5, 4, 8, 0, 0, 0, time.UTC), Next(3, "UTC", after))
+}
+
+func TestNextInUserTimeZone(t *testing.T) {
+	// Sunday 23:30 UTC is already Monday 07:30 in Taipei
+	after := time.Date(2022, 5, 1, 23, 30, 0, 0, time.UTC)
+	next := Next(1, "Asia/Taipei", after)
+	assert.True(t, next.Equal(time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNextFallsBack(t *testing.T) {
+	after := time.Date(2022, 5, 3, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, 5, 9, 8, 0, 0, 0, time.UTC), Next(9, "Nowhere/City", after))
+}
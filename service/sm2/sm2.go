@@ -0,0 +1,58 @@
+package sm2
+
+import "math"
+
+const INITIAL_EASE = 2.5
+const MIN_EASE = 1.3
+
+// PASSING_QUALITY is the lowest quality that keeps the repetitions going.
+const PASSING_QUALITY = 3
+
+// State is how far a user is in the repetition of one item.
+type State struct {
+	Ease float64
+	// Interval is the number of days until the next review.
+	Interval    int
+	Repetitions int
+}
+
+func New() State {
+	return State{Ease: INITIAL_EASE}
+}
+
+// Next returns the state after a review graded with quality, from 0 for a blackout to 5 for a
+// perfect response, following SuperMemo 2. Quality outside 0-5 is clamped and the zero State
+// counts as a new item.
+func Next(s State, quality int) State {
+	if quality < 0 {
+		quality = 0
+	}
+	if quality > 5 {
+		quality = 5
+	}
+	if s.Ease < MIN_EASE {
+		s.Ease = INITIAL_EASE
+	}
+
+	if quality < PASSING_QUALITY {
+		s.Repetitions = 0
+		s.Interval = 1
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.Ease))
+		}
+		s.Repetitions++
+	}
+
+	miss := float64(5 - quality)
+	s.Ease += 0.1 - miss*(0.08+miss*0.02)
+	if s.Ease < MIN_EASE {
+		s.Ease = MIN_EASE
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package sm2
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNextGrowsTheInterval(t *testing.T) {
+	s := New()
+	s = Next(s, 5)
+	assert.Equal(t, 1, s.Interval)
+	s = Next(s, 5)
+	assert.Equal(t, 6, s.Interval)
+	s = Next(s, 5)
+	assert.Equal(t, 3, s.Repetitions)
+	assert.InDelta(t, 2.8, s.Ease, 0.0001)
+	assert.Equal(t, 16, s.Interval)
+}
+
+func TestNextLowersTheEaseOnHardReviews(t *testing.T) {
+	s := Next(New(), 3)
+	assert.InDelta(t, 2.36, s.Ease, 0.0001)
+	assert.Equal(t, 1, s.Repetitions)
+}
+
+func TestNextStartsOverOnFailure(t *testing.T) {
+	s := State{Ease: 2.5, Interval: 15, Repetitions: 3}
+	s = Next(s, 1)
+	assert.Equal(t, 0, s.Repetitions)
+	assert.Equal(t, 1, s.Interval)
+	assert.InDelta(t, 1.96, s.Ease, 0.0001)
+}
+
+func TestNextKeepsTheMinimumEase(t *testing.T) {
+	s := State{Ease: MIN_EASE, Interval: 1}
+	s = Next(s, 0)
+	assert.Equal(t, MIN_EASE, s.Ease)
+}
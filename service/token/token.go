@@ -0,0 +1,24 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type Generator struct {
+	size int
+}
+
+func NewGenerator(size int) Generator {
+	return Generator{size: size}
+}
+
+// New returns a random hex token of size bytes.
+func (g Generator) New() string {
+	b := make([]byte, g.size)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package validator
+
+import (
+	"net/mail"
+	"net/url"
+	"time"
+	_ "time/tzdata"
+)
+
+type EmailValidator struct{}
+
+func NewEmailValidator() EmailValidator {
+	return EmailValidator{}
+}
+
+func (v EmailValidator) Validate(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return false
+	}
+	return addr.Address == s
+}
+
+type PasswordValidator struct {
+	min int
+	max int
+}
+
+func NewPasswordValidator() PasswordValidator {
+	return PasswordValidator{min: 6, max: 15}
+}
+
+func (v PasswordValidator) Validate(s string) bool {
+	return len(s) >= v.min && len(s) <= v.max
+}
+
+type RangeValidator struct {
+	min int
+	max int
+}
+
+func NewRangeValidator(min int, max int) RangeValidator {
+	return RangeValidator{min: min, max: max}
+}
+
+func (v RangeValidator) Validate(n int) bool {
+	return n >= v.min && n <= v.max
+}
+
+type TimeZoneValidator struct{}
+
+func NewTimeZoneValidator() TimeZoneValidator {
+	return TimeZoneValidator{}
+}
+
+// Validate accepts IANA time zone names like "Asia/Taipei".
+func (v TimeZoneValidator) Validate(s string) bool {
+	if s == "" || s == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(s)
+	return err == nil
+}
+
+type ISBNValidator struct{}
+
+func NewISBNValidator() ISBNValidator {
+	return ISBNValidator{}
+}
+
+// Validate accepts ISBN-10 and ISBN-13 without hyphens, the check digit must match.
+func (v ISBNValidator) Validate(s string) bool {
+	switch len(s) {
+	case 10:
+		sum := 0
+		for i, c := range s {
+			d := int(c - '0')
+			if i == 9 && (c == 'X' || c == 'x') {
+				d = 10
+			} else if c < '0' || c > '9' {
+				return false
+			}
+			sum += (10 - i) * d
+		}
+		return sum%11 == 0
+	case 13:
+		sum := 0
+		for i, c := range s {
+			if c < '0' || c > '9' {
+				return false
+			}
+			d := int(c - '0')
+			if i%2 == 1 {
+				d *= 3
+			}
+			sum += d
+		}
+		return sum%10 == 0
+	}
+	return false
+}
+
+type URLValidator struct{}
+
+func NewURLValidator() URLValidator {
+	return URLValidator{}
+}
+
+// Validate accepts absolute http and https URLs.
+func (v URLValidator) Validate(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}